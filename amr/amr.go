@@ -0,0 +1,424 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package amr implements RTP payload format (RFC 4867) and SDP negotiation for AMR and AMR-WB.
+//
+// Speech codecs are provided with Register. Build with "opencoreamr" tag to register codecs
+// based on opencore-amr and vo-amrwbenc libraries.
+package amr
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/livekit/media-sdk"
+	"github.com/livekit/media-sdk/rtp"
+)
+
+const (
+	SDPName   = "AMR/8000"
+	SDPNameWB = "AMR-WB/16000"
+)
+
+// FrameEncoder encodes 20ms frames of PCM audio.
+type FrameEncoder interface {
+	// Encode a frame of PCM samples using a given speech mode. If DTX is enabled, it may return SID or NO_DATA frames.
+	// Frame data is only valid until the next call.
+	Encode(src media.PCM16Sample, mode FrameType) Frame
+	Close()
+}
+
+// FrameDecoder decodes individual frames to 20ms of PCM audio.
+type FrameDecoder interface {
+	// Decode a single frame to dst. Damaged, lost and NO_DATA frames must be concealed by the decoder.
+	Decode(dst media.PCM16Sample, f Frame)
+	Close()
+}
+
+// Implementation of AMR or AMR-WB speech codec.
+type Implementation interface {
+	// NewEncoder creates a new encoder, optionally with discontinuous transmission.
+	NewEncoder(dtx bool) FrameEncoder
+	NewDecoder() FrameDecoder
+}
+
+// Config of the codec offered to the remote side.
+type Config struct {
+	Format
+	// ModeSet restricts speech modes that can be used. Empty means all modes.
+	ModeSet []FrameType
+	// ModeChangePeriod restricts mode changes to every N-th frame. Only values 1 (default) and 2 are supported.
+	ModeChangePeriod int
+	// DTX enables discontinuous transmission in the encoder.
+	DTX bool
+}
+
+var (
+	registerMu sync.Mutex
+	registered = make(map[bool]bool)
+)
+
+// Register AMR codec with a given implementation. Config selects between AMR-NB and AMR-WB.
+// It can only be called once for each variant.
+func Register(impl Implementation, conf Config) {
+	registerMu.Lock()
+	defer registerMu.Unlock()
+	if registered[conf.Wideband] {
+		panic("amr: codec is already registered")
+	}
+	c, err := NewCodec(impl, conf)
+	if err != nil {
+		panic(err)
+	}
+	registered[conf.Wideband] = true
+	media.RegisterCodec(c)
+}
+
+// NewCodec creates AMR or AMR-WB RTP codec.
+func NewCodec(impl Implementation, conf Config) (*Codec, error) {
+	if conf.ModeChangePeriod == 0 {
+		conf.ModeChangePeriod = 1
+	}
+	if conf.ModeChangePeriod != 1 && conf.ModeChangePeriod != 2 {
+		return nil, fmt.Errorf("unsupported mode-change-period: %d", conf.ModeChangePeriod)
+	}
+	conf.ModeSet = slices.Clone(conf.ModeSet)
+	slices.Sort(conf.ModeSet)
+	conf.ModeSet = slices.Compact(conf.ModeSet)
+	for _, m := range conf.ModeSet {
+		if int(m) >= conf.Modes() {
+			return nil, fmt.Errorf("unsupported mode: %d", m)
+		}
+	}
+	c := &Codec{impl: impl, conf: conf}
+	c.remoteCMR.Store(CMRNone)
+	c.localCMR.Store(CMRNone)
+	info := media.CodecInfo{
+		SDPName:    SDPName,
+		SampleRate: 8000,
+		Priority:   -15,
+		FileExt:    "amr",
+	}
+	if conf.Wideband {
+		info = media.CodecInfo{
+			SDPName:    SDPNameWB,
+			SampleRate: 16000,
+			Priority:   -6,
+			FileExt:    "awb",
+		}
+	}
+	c.codec = rtp.NewAudioCodec(info, c.Decode, c.Encode).(audioCodec)
+	return c, nil
+}
+
+type audioCodec interface {
+	rtp.AudioCodec
+	rtp.AudioCodecDumper
+}
+
+// Codec is AMR or AMR-WB RTP codec. Codecs returned by WithFMTP keep the state of a single call:
+// mode requests (CMR) received by the decoder are applied to the encoder.
+type Codec struct {
+	codec     audioCodec
+	impl      Implementation
+	conf      Config
+	remoteCMR atomic.Uint32
+	localCMR  atomic.Uint32
+}
+
+var (
+	_ rtp.FMTPCodec        = (*Codec)(nil)
+	_ rtp.AudioCodecDumper = (*Codec)(nil)
+)
+
+func (c *Codec) Info() media.CodecInfo {
+	return c.codec.Info()
+}
+
+// Config returns codec configuration.
+func (c *Codec) Config() Config {
+	conf := c.conf
+	conf.ModeSet = slices.Clone(conf.ModeSet)
+	return conf
+}
+
+// RequestMode sets the codec mode request (CMR) sent to the remote side. CMRNone cancels the request.
+func (c *Codec) RequestMode(mode FrameType) {
+	c.localCMR.Store(uint32(mode))
+}
+
+func (c *Codec) FMTP() string {
+	var params []string
+	if c.conf.OctetAlign {
+		params = append(params, "octet-align=1")
+	}
+	if len(c.conf.ModeSet) != 0 {
+		modes := make([]string, 0, len(c.conf.ModeSet))
+		for _, m := range c.conf.ModeSet {
+			modes = append(modes, strconv.Itoa(int(m)))
+		}
+		params = append(params, "mode-set="+strings.Join(modes, ","))
+	}
+	if c.conf.ModeChangePeriod == 2 {
+		params = append(params, "mode-change-period=2")
+	}
+	return strings.Join(params, "; ")
+}
+
+// WithFMTP returns a new codec configured for format parameters of the remote side.
+// The payload format (octet-align) follows the remote side, and the mode set is restricted to modes supported by both sides.
+// Robust sorting, interleaving and CRC are not supported.
+func (c *Codec) WithFMTP(fmtp string) (rtp.AudioCodec, error) {
+	params := rtp.ParseFMTP(fmtp)
+	conf := c.Config()
+	conf.OctetAlign = false
+	for _, name := range []string{"octet-align", "crc", "robust-sorting"} {
+		switch v := params[name]; v {
+		case "", "0":
+		case "1":
+			if name != "octet-align" {
+				return nil, fmt.Errorf("unsupported AMR parameter: %s", name)
+			}
+			conf.OctetAlign = true
+		default:
+			return nil, fmt.Errorf("invalid %s value: %q", name, v)
+		}
+	}
+	if _, ok := params["interleaving"]; ok {
+		return nil, fmt.Errorf("unsupported AMR parameter: interleaving")
+	}
+	if v, ok := params["channels"]; ok && v != "1" {
+		return nil, fmt.Errorf("unsupported number of channels: %q", v)
+	}
+	if v, ok := params["mode-set"]; ok {
+		var modes []FrameType
+		for _, s := range strings.Split(v, ",") {
+			m, err := strconv.Atoi(strings.TrimSpace(s))
+			if err != nil || m < 0 || m >= conf.Modes() {
+				return nil, fmt.Errorf("invalid mode-set: %q", v)
+			}
+			if len(conf.ModeSet) == 0 || slices.Contains(conf.ModeSet, FrameType(m)) {
+				modes = append(modes, FrameType(m))
+			}
+		}
+		if len(modes) == 0 {
+			return nil, fmt.Errorf("no common AMR modes in mode-set: %q", v)
+		}
+		conf.ModeSet = modes
+	}
+	if v, ok := params["mode-change-period"]; ok {
+		switch v {
+		case "1":
+		case "2":
+			conf.ModeChangePeriod = 2
+		default:
+			return nil, fmt.Errorf("unsupported mode-change-period: %q", v)
+		}
+	}
+	return NewCodec(c.impl, conf)
+}
+
+func (c *Codec) EncodeRTP(w *rtp.Stream) media.PCM16Writer {
+	return c.codec.EncodeRTP(w)
+}
+
+func (c *Codec) DecodeRTP(w media.Writer[media.PCM16Sample], typ byte) rtp.Handler {
+	return c.codec.DecodeRTP(w, typ)
+}
+
+func (c *Codec) EncodeRTPWithDump(w *rtp.Stream, d media.Dumper) (media.PCM16Writer, error) {
+	return c.codec.EncodeRTPWithDump(w, d)
+}
+
+func (c *Codec) DecodeRTPWithDump(w media.Writer[media.PCM16Sample], typ byte, d media.Dumper) (rtp.Handler, error) {
+	return c.codec.DecodeRTPWithDump(w, typ, d)
+}
+
+func (c *Codec) frameSamples() int {
+	return c.Info().SampleRate / rtp.DefFramesPerSec
+}
+
+// allowed reports if the mode can be used by the encoder.
+func (c *Codec) allowed(m FrameType) bool {
+	if int(m) >= c.conf.Modes() {
+		return false
+	}
+	return len(c.conf.ModeSet) == 0 || slices.Contains(c.conf.ModeSet, m)
+}
+
+// targetMode returns the mode the encoder should use, based on the mode set and the mode request from the remote side.
+func (c *Codec) targetMode() FrameType {
+	cmr := FrameType(c.remoteCMR.Load())
+	if cmr == CMRNone || int(cmr) >= c.conf.Modes() {
+		cmr = FrameType(c.conf.Modes() - 1)
+	}
+	// Use the highest allowed mode not exceeding the request, or the lowest mode if none.
+	for m := cmr; ; m-- {
+		if c.allowed(m) {
+			return m
+		}
+		if m == 0 {
+			break
+		}
+	}
+	if len(c.conf.ModeSet) != 0 {
+		return c.conf.ModeSet[0]
+	}
+	return 0
+}
+
+// Decode creates a decoder that writes PCM to w. Mode requests received from the remote side are applied to encoders of this codec.
+func (c *Codec) Decode(w media.PCM16Writer) Writer {
+	if rate := c.Info().SampleRate; w.SampleRate() != rate {
+		w = media.ResampleWriter(w, rate)
+	}
+	return &Decoder{c: c, w: w, d: c.impl.NewDecoder()}
+}
+
+// Encode creates an encoder that writes RTP payloads to w.
+func (c *Codec) Encode(w Writer) media.PCM16Writer {
+	if w.SampleRate() != c.Info().SampleRate {
+		panic("unsupported sample rate")
+	}
+	return &Encoder{c: c, w: w, e: c.impl.NewEncoder(c.conf.DTX), mode: c.targetMode()}
+}
+
+// Sample is an RTP payload of AMR or AMR-WB.
+type Sample []byte
+
+func (s Sample) Size() int {
+	return len(s)
+}
+
+// DTX marks AMR samples as frames with discontinuous transmission. See rtp.DTXFrame.
+func (Sample) DTX() {}
+
+func (s Sample) CopyTo(dst []byte) (int, error) {
+	if len(dst) < len(s) {
+		return 0, io.ErrShortBuffer
+	}
+	n := copy(dst, s)
+	return n, nil
+}
+
+type Writer = media.WriteCloser[Sample]
+
+type Decoder struct {
+	c   *Codec
+	w   media.PCM16Writer
+	d   FrameDecoder
+	buf media.PCM16Sample
+}
+
+func (d *Decoder) String() string {
+	return fmt.Sprintf("AMR(decode) -> %s", d.w)
+}
+
+func (d *Decoder) SampleRate() int {
+	return d.w.SampleRate()
+}
+
+func (d *Decoder) Close() error {
+	d.d.Close()
+	return d.w.Close()
+}
+
+func (d *Decoder) WriteSample(in Sample) error {
+	p, err := d.c.conf.Unmarshal(in)
+	if err != nil {
+		return err
+	}
+	d.c.remoteCMR.Store(uint32(p.CMR))
+	n := d.c.frameSamples()
+	sz := len(p.Frames) * n
+	if cap(d.buf) < sz {
+		d.buf = make(media.PCM16Sample, sz)
+	} else {
+		d.buf = d.buf[:sz]
+	}
+	for i, f := range p.Frames {
+		d.d.Decode(d.buf[i*n:(i+1)*n], f)
+	}
+	return d.w.WriteSample(d.buf)
+}
+
+type Encoder struct {
+	c      *Codec
+	w      Writer
+	e      FrameEncoder
+	mode   FrameType
+	frames int // number of encoded frames, used for mode-change-period
+	in     media.PCM16Sample
+	out    Sample
+	data   []byte
+	buf    []Frame
+}
+
+func (e *Encoder) String() string {
+	return fmt.Sprintf("AMR(encode) -> %s", e.w)
+}
+
+func (e *Encoder) SampleRate() int {
+	return e.w.SampleRate()
+}
+
+func (e *Encoder) Close() error {
+	e.e.Close()
+	return e.w.Close()
+}
+
+// WriteSample encodes all complete 20ms frames and sends them as a single payload. Remaining samples are buffered.
+// If all frames are NO_DATA (DTX), an empty payload is written. Nothing is written if there are not enough samples for a frame.
+func (e *Encoder) WriteSample(in media.PCM16Sample) error {
+	e.in = append(e.in, in...)
+	n := e.c.frameSamples()
+	e.buf = e.buf[:0]
+	e.data = e.data[:0]
+	hasData := false
+	off := 0
+	for ; len(e.in)-off >= n; off += n {
+		if e.frames%e.c.conf.ModeChangePeriod == 0 {
+			e.mode = e.c.targetMode()
+		}
+		e.frames++
+		f := e.e.Encode(e.in[off:off+n], e.mode)
+		if f.Type != FrameNoData {
+			hasData = true
+		}
+		// Frame data is only valid until the next call, so copy it.
+		i := len(e.data)
+		e.data = append(e.data, f.Data...)
+		f.Data = e.data[i:len(e.data):len(e.data)]
+		e.buf = append(e.buf, f)
+	}
+	e.in = e.in[:copy(e.in, e.in[off:])]
+	if off == 0 {
+		return nil // not enough samples for a frame
+	}
+	e.out = e.out[:0]
+	if hasData {
+		p := Payload{CMR: uint8(e.c.localCMR.Load()), Frames: e.buf}
+		var err error
+		e.out, err = e.c.conf.Marshal(e.out, p)
+		if err != nil {
+			return err
+		}
+	}
+	return e.w.WriteSample(e.out)
+}
@@ -0,0 +1,252 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package amr
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/media-sdk"
+	"github.com/livekit/media-sdk/rtp"
+	"github.com/livekit/media-sdk/sdp"
+)
+
+// testImpl "encodes" a frame by storing its first sample in the first byte of the frame data.
+// Silent frames produce NO_DATA if DTX is enabled.
+type testImpl struct {
+	f Format
+}
+
+func (i testImpl) NewEncoder(dtx bool) FrameEncoder { return &testEncoder{f: i.f, dtx: dtx} }
+func (i testImpl) NewDecoder() FrameDecoder         { return testDecoder{} }
+
+type testEncoder struct {
+	f   Format
+	dtx bool
+	buf [64]byte
+}
+
+func (e *testEncoder) Encode(src media.PCM16Sample, mode FrameType) Frame {
+	if e.dtx && src[0] == 0 {
+		return Frame{Type: FrameNoData}
+	}
+	data := e.buf[:e.f.FrameSize(mode)]
+	clear(data)
+	data[0] = byte(src[0])
+	return Frame{Type: mode, Quality: true, Data: data}
+}
+
+func (e *testEncoder) Close() {}
+
+type testDecoder struct{}
+
+func (testDecoder) Decode(dst media.PCM16Sample, f Frame) {
+	v := int16(-1)
+	if f.Type != FrameNoData {
+		v = int16(f.Data[0])<<8 | int16(f.Type)
+	}
+	for i := range dst {
+		dst[i] = v
+	}
+}
+
+func (testDecoder) Close() {}
+
+type sampleWriter struct {
+	rate    int
+	samples []Sample
+}
+
+func (w *sampleWriter) String() string  { return "test" }
+func (w *sampleWriter) SampleRate() int { return w.rate }
+func (w *sampleWriter) Close() error    { return nil }
+
+func (w *sampleWriter) WriteSample(s Sample) error {
+	w.samples = append(w.samples, append(Sample{}, s...))
+	return nil
+}
+
+func frame(n int, v int16) media.PCM16Sample {
+	out := make(media.PCM16Sample, n)
+	for i := range out {
+		out[i] = v
+	}
+	return out
+}
+
+func newCodec(t testing.TB, conf Config) *Codec {
+	c, err := NewCodec(testImpl{f: conf.Format}, conf)
+	require.NoError(t, err)
+	return c
+}
+
+func decodePayloads(t testing.TB, f Format, samples []Sample) []Payload {
+	var out []Payload
+	for _, s := range samples {
+		if len(s) == 0 {
+			out = append(out, Payload{})
+			continue
+		}
+		p, err := f.Unmarshal(s)
+		require.NoError(t, err)
+		out = append(out, p)
+	}
+	return out
+}
+
+func TestEncodeModes(t *testing.T) {
+	conf := Config{Format: Format{OctetAlign: true}, ModeSet: []FrameType{0, 2, 5}, ModeChangePeriod: 2}
+	c := newCodec(t, conf)
+	got := &sampleWriter{rate: 8000}
+	w := c.Encode(got)
+
+	require.NoError(t, w.WriteSample(frame(160, 1)))
+	// Remote requests mode 4, which is not in the mode set. The closest lower mode must be used,
+	// but only on even frames because of the mode change period.
+	c.remoteCMR.Store(4)
+	require.NoError(t, w.WriteSample(frame(160, 2)))
+	require.NoError(t, w.WriteSample(frame(320, 3)))
+	c.RequestMode(1)
+	c.remoteCMR.Store(CMRNone)
+	require.NoError(t, w.WriteSample(frame(80, 4)))
+	require.NoError(t, w.WriteSample(frame(80, 4)))
+	require.NoError(t, w.Close())
+
+	var modes [][]FrameType
+	var cmrs []uint8
+	for _, p := range decodePayloads(t, conf.Format, got.samples) {
+		var m []FrameType
+		for _, f := range p.Frames {
+			m = append(m, f.Type)
+		}
+		modes = append(modes, m)
+		cmrs = append(cmrs, p.CMR)
+	}
+	require.Equal(t, [][]FrameType{{5}, {5}, {2, 2}, {5}}, modes)
+	require.Equal(t, []uint8{CMRNone, CMRNone, CMRNone, 1}, cmrs)
+}
+
+func TestEncodeDTX(t *testing.T) {
+	conf := Config{Format: Format{Wideband: true}, DTX: true}
+	c := newCodec(t, conf)
+	got := &sampleWriter{rate: 16000}
+	w := c.Encode(got)
+	require.NoError(t, w.WriteSample(frame(320, 1)))
+	require.NoError(t, w.WriteSample(frame(320, 0)))
+	require.NoError(t, w.Close())
+	require.Len(t, got.samples, 2)
+	require.NotEmpty(t, got.samples[0])
+	require.Empty(t, got.samples[1])
+
+	// Empty payloads are not sent over RTP, but the timestamp advances.
+	var buf rtp.Buffer
+	pw := c.EncodeRTP(rtp.NewSeqWriter(&buf).NewStream(96, 16000))
+	require.NoError(t, pw.WriteSample(frame(320, 1)))
+	require.NoError(t, pw.WriteSample(frame(320, 0)))
+	require.NoError(t, pw.WriteSample(frame(320, 2)))
+	require.Len(t, buf, 2)
+	require.Equal(t, buf[0].Timestamp+2*320, buf[1].Timestamp)
+}
+
+func TestDecode(t *testing.T) {
+	f := Format{}
+	c := newCodec(t, Config{Format: f})
+	var got []media.PCM16Sample
+	w := c.Decode(media.NewPCM16FrameWriter(&got, 8000))
+
+	data, err := f.Marshal(nil, Payload{CMR: 3, Frames: []Frame{
+		{Type: 7, Quality: true, Data: append([]byte{5}, make([]byte, 30)...)},
+		{Type: FrameNoData},
+	}})
+	require.NoError(t, err)
+	require.NoError(t, w.WriteSample(data))
+	require.ErrorIs(t, w.WriteSample(data[:3]), ErrInvalidPayload)
+	require.NoError(t, w.Close())
+
+	require.Equal(t, []media.PCM16Sample{
+		append(frame(160, 5<<8|7), frame(160, -1)...),
+	}, got)
+	require.EqualValues(t, 3, c.remoteCMR.Load())
+	require.Equal(t, FrameType(3), c.targetMode())
+}
+
+func TestFMTP(t *testing.T) {
+	c := newCodec(t, Config{})
+	require.Equal(t, "", c.FMTP())
+
+	c2, err := c.WithFMTP("octet-align=1; mode-set=0,2,5,7; mode-change-period=2")
+	require.NoError(t, err)
+	conf := c2.(*Codec).Config()
+	require.True(t, conf.OctetAlign)
+	require.Equal(t, []FrameType{0, 2, 5, 7}, conf.ModeSet)
+	require.Equal(t, 2, conf.ModeChangePeriod)
+	require.Equal(t, "octet-align=1; mode-set=0,2,5,7; mode-change-period=2", c2.(*Codec).FMTP())
+	require.False(t, c2 == c, "must create a new codec for each call")
+
+	c = newCodec(t, Config{ModeSet: []FrameType{5, 2}})
+	require.Equal(t, "mode-set=2,5", c.FMTP())
+	c2, err = c.WithFMTP("mode-set=0,2,7")
+	require.NoError(t, err)
+	require.Equal(t, []FrameType{2}, c2.(*Codec).Config().ModeSet)
+	require.False(t, c2.(*Codec).Config().OctetAlign)
+
+	for _, fmtp := range []string{
+		"mode-set=0,7",
+		"mode-set=9",
+		"octet-align=2",
+		"crc=1",
+		"robust-sorting=1",
+		"interleaving=4",
+		"channels=2",
+		"mode-change-period=3",
+	} {
+		_, err = c.WithFMTP(fmtp)
+		require.Error(t, err, fmtp)
+	}
+	_, err = NewCodec(testImpl{}, Config{ModeSet: []FrameType{8}})
+	require.Error(t, err)
+}
+
+func TestSDP(t *testing.T) {
+	c := newCodec(t, Config{Format: Format{Wideband: true}, ModeSet: []FrameType{2}})
+	// Builds with cgo register codecs based on opencore-amr, use the test codec instead.
+	reg := media.DefaultRegistry().Clone()
+	reg.RegisterCodec(c)
+	opt := sdp.WithRegistry(reg)
+
+	const offerSDP = "v=0\r\n" +
+		"o=- 1 1 IN IP4 10.0.0.1\r\n" +
+		"s=-\r\n" +
+		"c=IN IP4 10.0.0.1\r\n" +
+		"t=0 0\r\n" +
+		"m=audio 10000 RTP/AVP 97 98 101\r\n" +
+		"a=rtpmap:97 AMR-WB/16000\r\n" +
+		"a=fmtp:97 octet-align=1; mode-set=0,1,2\r\n" +
+		"a=rtpmap:98 AMR/8000\r\n" +
+		"a=rtpmap:101 telephone-event/8000\r\n" +
+		"a=fmtp:101 0-16\r\n"
+	offer, err := sdp.ParseOffer([]byte(offerSDP), opt)
+	require.NoError(t, err)
+	answer, conf, err := offer.Answer(netip.MustParseAddr("10.0.0.2"), 20000, sdp.EncryptionNone, opt)
+	require.NoError(t, err)
+	require.Equal(t, SDPNameWB, conf.Audio.Codec.Info().SDPName)
+	require.EqualValues(t, 97, conf.Audio.Type)
+
+	data, err := answer.SDP.Marshal()
+	require.NoError(t, err)
+	require.Contains(t, string(data), "a=rtpmap:97 AMR-WB/16000\r\na=fmtp:97 octet-align=1; mode-set=2\r\n")
+}
@@ -0,0 +1,164 @@
+//go:build cgo && opencoreamr
+
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package amr
+
+/*
+#cgo pkg-config: opencore-amrnb opencore-amrwb vo-amrwbenc
+#include <opencore-amrnb/interf_enc.h>
+#include <opencore-amrnb/interf_dec.h>
+#include <opencore-amrwb/dec_if.h>
+#include <vo-amrwbenc/enc_if.h>
+*/
+import "C"
+
+import (
+	"unsafe"
+
+	"github.com/livekit/media-sdk"
+)
+
+func init() {
+	Register(opencoreNB{}, Config{})
+	Register(opencoreWB{}, Config{Format: Format{Wideband: true}})
+}
+
+// maxStorageSize is the maximal size of a frame in storage format (MIME file format), including the header byte.
+const maxStorageSize = 64
+
+// fromStorage converts a frame in storage format to Frame.
+func fromStorage(f Format, buf []byte) Frame {
+	if len(buf) == 0 {
+		return Frame{Type: FrameNoData}
+	}
+	fr := Frame{
+		Type:    FrameType(buf[0]>>3) & 0xf,
+		Quality: buf[0]&0x4 != 0,
+	}
+	if sz := f.FrameSize(fr.Type); sz > 0 && len(buf) > sz {
+		fr.Data = buf[1 : 1+sz]
+	} else if sz < 0 {
+		fr.Type = FrameNoData
+	}
+	return fr
+}
+
+// toStorage converts a frame to storage format.
+func toStorage(dst []byte, fr Frame) []byte {
+	h := byte(fr.Type&0xf) << 3
+	if fr.Quality {
+		h |= 0x4
+	}
+	dst = append(dst[:0], h)
+	return append(dst, fr.Data...)
+}
+
+type opencoreNB struct{}
+
+func (opencoreNB) NewEncoder(dtx bool) FrameEncoder {
+	var v C.int
+	if dtx {
+		v = 1
+	}
+	return &nbEncoder{st: C.Encoder_Interface_init(v)}
+}
+
+func (opencoreNB) NewDecoder() FrameDecoder {
+	return &nbDecoder{st: C.Decoder_Interface_init()}
+}
+
+type nbEncoder struct {
+	st  unsafe.Pointer
+	buf [maxStorageSize]byte
+}
+
+func (e *nbEncoder) Encode(src media.PCM16Sample, mode FrameType) Frame {
+	n := C.Encoder_Interface_Encode(e.st, C.enum_Mode(mode), (*C.short)(unsafe.Pointer(&src[0])), (*C.uchar)(unsafe.Pointer(&e.buf[0])), 0)
+	return fromStorage(Format{}, e.buf[:n])
+}
+
+func (e *nbEncoder) Close() {
+	if e.st != nil {
+		C.Encoder_Interface_exit(e.st)
+		e.st = nil
+	}
+}
+
+type nbDecoder struct {
+	st  unsafe.Pointer
+	buf []byte
+}
+
+func (d *nbDecoder) Decode(dst media.PCM16Sample, fr Frame) {
+	d.buf = toStorage(d.buf, fr)
+	C.Decoder_Interface_Decode(d.st, (*C.uchar)(unsafe.Pointer(&d.buf[0])), (*C.short)(unsafe.Pointer(&dst[0])), 0)
+}
+
+func (d *nbDecoder) Close() {
+	if d.st != nil {
+		C.Decoder_Interface_exit(d.st)
+		d.st = nil
+	}
+}
+
+type opencoreWB struct{}
+
+func (opencoreWB) NewEncoder(dtx bool) FrameEncoder {
+	return &wbEncoder{st: C.E_IF_init(), dtx: dtx}
+}
+
+func (opencoreWB) NewDecoder() FrameDecoder {
+	return &wbDecoder{st: C.D_IF_init()}
+}
+
+type wbEncoder struct {
+	st  unsafe.Pointer
+	dtx bool
+	buf [maxStorageSize]byte
+}
+
+func (e *wbEncoder) Encode(src media.PCM16Sample, mode FrameType) Frame {
+	var dtx C.int
+	if e.dtx {
+		dtx = 1
+	}
+	n := C.E_IF_encode(e.st, C.int(mode), (*C.short)(unsafe.Pointer(&src[0])), (*C.uchar)(unsafe.Pointer(&e.buf[0])), dtx)
+	return fromStorage(Format{Wideband: true}, e.buf[:n])
+}
+
+func (e *wbEncoder) Close() {
+	if e.st != nil {
+		C.E_IF_exit(e.st)
+		e.st = nil
+	}
+}
+
+type wbDecoder struct {
+	st  unsafe.Pointer
+	buf []byte
+}
+
+func (d *wbDecoder) Decode(dst media.PCM16Sample, fr Frame) {
+	d.buf = toStorage(d.buf, fr)
+	C.D_IF_decode(d.st, (*C.uchar)(unsafe.Pointer(&d.buf[0])), (*C.short)(unsafe.Pointer(&dst[0])), 0)
+}
+
+func (d *wbDecoder) Close() {
+	if d.st != nil {
+		C.D_IF_exit(d.st)
+		d.st = nil
+	}
+}
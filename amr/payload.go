@@ -0,0 +1,281 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package amr
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrInvalidPayload = errors.New("invalid AMR payload")
+
+// FrameType is a frame type index from RFC 4867. Values below SID of the variant are speech modes.
+type FrameType uint8
+
+const (
+	// FrameSpeechLost indicates a lost speech frame. Only used by AMR-WB.
+	FrameSpeechLost FrameType = 14
+	// FrameNoData indicates that no data is sent for the frame, for example during DTX.
+	FrameNoData FrameType = 15
+	// CMRNone is a codec mode request that doesn't request any particular mode.
+	CMRNone = 15
+)
+
+var (
+	// Frame sizes in bits, indexed by frame type. Zero means the frame type is not supported.
+	nbFrameBits = [16]int{95, 103, 118, 134, 148, 159, 204, 244, 39, 43, 38, 37}
+	wbFrameBits = [16]int{132, 177, 253, 285, 317, 365, 397, 461, 477, 40}
+)
+
+// Frame is a single AMR or AMR-WB frame.
+type Frame struct {
+	Type FrameType
+	// Quality is false if the frame is damaged (Q bit).
+	Quality bool
+	// Data contains speech bits of the frame in the order defined by the codec, packed MSB first and zero-padded to a byte.
+	Data []byte
+}
+
+// Payload is a decoded RTP payload of AMR or AMR-WB.
+type Payload struct {
+	// CMR is a codec mode request sent to the remote encoder, or CMRNone.
+	CMR    uint8
+	Frames []Frame
+}
+
+// Format of AMR RTP payload (RFC 4867).
+type Format struct {
+	// Wideband is set for AMR-WB, otherwise AMR-NB is used.
+	Wideband bool
+	// OctetAlign selects octet-aligned mode instead of bandwidth-efficient mode.
+	OctetAlign bool
+}
+
+// Modes returns the number of speech modes.
+func (f Format) Modes() int {
+	if f.Wideband {
+		return 9
+	}
+	return 8
+}
+
+// SID returns the frame type used for comfort noise frames.
+func (f Format) SID() FrameType {
+	return FrameType(f.Modes())
+}
+
+// FrameBits returns the number of bits in frame of a given type, or -1 if the type is not supported.
+func (f Format) FrameBits(ft FrameType) int {
+	switch ft {
+	case FrameNoData:
+		return 0
+	case FrameSpeechLost:
+		if f.Wideband {
+			return 0
+		}
+		return -1
+	}
+	bits := nbFrameBits
+	if f.Wideband {
+		bits = wbFrameBits
+	}
+	if int(ft) >= len(bits) || bits[ft] == 0 {
+		return -1
+	}
+	return bits[ft]
+}
+
+// FrameSize returns the size of frame data in bytes, or -1 if the type is not supported.
+func (f Format) FrameSize(ft FrameType) int {
+	bits := f.FrameBits(ft)
+	if bits < 0 {
+		return -1
+	}
+	return (bits + 7) / 8
+}
+
+// Marshal appends the payload to dst.
+func (f Format) Marshal(dst []byte, p Payload) ([]byte, error) {
+	if len(p.Frames) == 0 {
+		return nil, fmt.Errorf("%w: no frames", ErrInvalidPayload)
+	}
+	for _, fr := range p.Frames {
+		if sz := f.FrameSize(fr.Type); sz < 0 {
+			return nil, fmt.Errorf("%w: unsupported frame type %d", ErrInvalidPayload, fr.Type)
+		} else if len(fr.Data) < sz {
+			return nil, fmt.Errorf("%w: short frame data for type %d", ErrInvalidPayload, fr.Type)
+		}
+	}
+	if f.OctetAlign {
+		dst = append(dst, p.CMR<<4)
+		for i, fr := range p.Frames {
+			dst = append(dst, tocEntry(fr, i != len(p.Frames)-1)<<2)
+		}
+		for _, fr := range p.Frames {
+			dst = append(dst, fr.Data[:f.FrameSize(fr.Type)]...)
+		}
+		return dst, nil
+	}
+	w := bitWriter{buf: dst}
+	w.write(uint32(p.CMR), 4)
+	for i, fr := range p.Frames {
+		w.write(uint32(tocEntry(fr, i != len(p.Frames)-1)), 6)
+	}
+	for _, fr := range p.Frames {
+		w.writeBits(fr.Data, f.FrameBits(fr.Type))
+	}
+	return w.buf, nil
+}
+
+// tocEntry returns 6 bit table of contents entry: F, FT and Q bits.
+func tocEntry(fr Frame, follows bool) byte {
+	v := byte(fr.Type&0xf) << 1
+	if follows {
+		v |= 1 << 5
+	}
+	if fr.Quality {
+		v |= 1
+	}
+	return v
+}
+
+// Unmarshal decodes the payload. Frame data is copied from the payload.
+func (f Format) Unmarshal(data []byte) (Payload, error) {
+	var p Payload
+	if f.OctetAlign {
+		if len(data) < 2 {
+			return p, fmt.Errorf("%w: payload too short", ErrInvalidPayload)
+		}
+		p.CMR = data[0] >> 4
+		data = data[1:]
+		for {
+			if len(data) == 0 {
+				return p, fmt.Errorf("%w: truncated table of contents", ErrInvalidPayload)
+			}
+			toc := data[0] >> 2
+			data = data[1:]
+			p.Frames = append(p.Frames, Frame{Type: FrameType(toc>>1) & 0xf, Quality: toc&1 != 0})
+			if toc&(1<<5) == 0 {
+				break
+			}
+		}
+		for i := range p.Frames {
+			fr := &p.Frames[i]
+			sz := f.FrameSize(fr.Type)
+			if sz < 0 {
+				return p, fmt.Errorf("%w: unsupported frame type %d", ErrInvalidPayload, fr.Type)
+			} else if len(data) < sz {
+				return p, fmt.Errorf("%w: truncated frame", ErrInvalidPayload)
+			}
+			fr.Data = append([]byte(nil), data[:sz]...)
+			data = data[sz:]
+		}
+		return p, nil
+	}
+	r := bitReader{buf: data}
+	cmr, ok := r.read(4)
+	if !ok {
+		return p, fmt.Errorf("%w: payload too short", ErrInvalidPayload)
+	}
+	p.CMR = uint8(cmr)
+	for {
+		toc, ok := r.read(6)
+		if !ok {
+			return p, fmt.Errorf("%w: truncated table of contents", ErrInvalidPayload)
+		}
+		p.Frames = append(p.Frames, Frame{Type: FrameType(toc>>1) & 0xf, Quality: toc&1 != 0})
+		if toc&(1<<5) == 0 {
+			break
+		}
+	}
+	for i := range p.Frames {
+		fr := &p.Frames[i]
+		bits := f.FrameBits(fr.Type)
+		if bits < 0 {
+			return p, fmt.Errorf("%w: unsupported frame type %d", ErrInvalidPayload, fr.Type)
+		}
+		fr.Data, ok = r.readBits(bits)
+		if !ok {
+			return p, fmt.Errorf("%w: truncated frame", ErrInvalidPayload)
+		}
+	}
+	return p, nil
+}
+
+type bitWriter struct {
+	buf []byte
+	n   int // number of bits used in the last byte, 0 means byte-aligned
+}
+
+func (w *bitWriter) write(v uint32, bits int) {
+	for i := bits - 1; i >= 0; i-- {
+		w.writeBit(byte(v>>i) & 1)
+	}
+}
+
+func (w *bitWriter) writeBit(b byte) {
+	if w.n == 0 {
+		w.buf = append(w.buf, 0)
+	}
+	w.buf[len(w.buf)-1] |= b << (7 - w.n)
+	w.n = (w.n + 1) % 8
+}
+
+func (w *bitWriter) writeBits(data []byte, bits int) {
+	for i := 0; i < bits; i++ {
+		w.writeBit((data[i/8] >> (7 - i%8)) & 1)
+	}
+}
+
+type bitReader struct {
+	buf []byte
+	pos int // in bits
+}
+
+func (r *bitReader) readBit() (byte, bool) {
+	if r.pos >= 8*len(r.buf) {
+		return 0, false
+	}
+	b := (r.buf[r.pos/8] >> (7 - r.pos%8)) & 1
+	r.pos++
+	return b, true
+}
+
+func (r *bitReader) read(bits int) (uint32, bool) {
+	var v uint32
+	for i := 0; i < bits; i++ {
+		b, ok := r.readBit()
+		if !ok {
+			return 0, false
+		}
+		v = v<<1 | uint32(b)
+	}
+	return v, true
+}
+
+func (r *bitReader) readBits(bits int) ([]byte, bool) {
+	if bits == 0 {
+		return nil, true
+	}
+	out := make([]byte, (bits+7)/8)
+	for i := 0; i < bits; i++ {
+		b, ok := r.readBit()
+		if !ok {
+			return nil, false
+		}
+		out[i/8] |= b << (7 - i%8)
+	}
+	return out, true
+}
@@ -0,0 +1,95 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package amr
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func randFrame(f Format, ft FrameType) Frame {
+	bits := f.FrameBits(ft)
+	data := make([]byte, (bits+7)/8)
+	for i := range data {
+		data[i] = byte(rand.Uint32())
+	}
+	if r := bits % 8; r != 0 {
+		data[len(data)-1] &= 0xff << (8 - r)
+	}
+	if bits == 0 {
+		data = nil
+	}
+	return Frame{Type: ft, Quality: true, Data: data}
+}
+
+func TestPayloadHeader(t *testing.T) {
+	fr := randFrame(Format{}, 7)
+	p := Payload{CMR: CMRNone, Frames: []Frame{fr}}
+
+	data, err := Format{}.Marshal(nil, p)
+	require.NoError(t, err)
+	require.Len(t, data, 32) // 4 + 6 + 244 bits
+	require.Equal(t, byte(0xf3), data[0])
+	require.Equal(t, byte(0xc0), data[1]&0xc0)
+
+	data, err = Format{OctetAlign: true}.Marshal(nil, p)
+	require.NoError(t, err)
+	require.Len(t, data, 2+31)
+	require.Equal(t, []byte{0xf0, 0x3c}, data[:2])
+	require.Equal(t, fr.Data, data[2:])
+}
+
+func TestPayloadRoundTrip(t *testing.T) {
+	for _, f := range []Format{
+		{},
+		{OctetAlign: true},
+		{Wideband: true},
+		{Wideband: true, OctetAlign: true},
+	} {
+		var frames []Frame
+		for ft := FrameType(0); ft <= f.SID(); ft++ {
+			frames = append(frames, randFrame(f, ft))
+		}
+		frames = append(frames, Frame{Type: FrameNoData})
+		for _, p := range []Payload{
+			{CMR: 2, Frames: frames[:1]},
+			{CMR: CMRNone, Frames: frames},
+		} {
+			data, err := f.Marshal(nil, p)
+			require.NoError(t, err)
+			got, err := f.Unmarshal(data)
+			require.NoError(t, err)
+			require.Equal(t, p, got, "%+v", f)
+
+			_, err = f.Unmarshal(data[:len(data)-2])
+			require.ErrorIs(t, err, ErrInvalidPayload)
+		}
+	}
+}
+
+func TestPayloadInvalid(t *testing.T) {
+	_, err := Format{}.Marshal(nil, Payload{})
+	require.ErrorIs(t, err, ErrInvalidPayload)
+	_, err = Format{}.Marshal(nil, Payload{Frames: []Frame{{Type: 12}}})
+	require.ErrorIs(t, err, ErrInvalidPayload)
+	_, err = Format{}.Marshal(nil, Payload{Frames: []Frame{{Type: 7, Data: []byte{1}}}})
+	require.ErrorIs(t, err, ErrInvalidPayload)
+	_, err = Format{OctetAlign: true}.Unmarshal([]byte{0xf0, 12 << 3})
+	require.ErrorIs(t, err, ErrInvalidPayload)
+	_, err = Format{}.Unmarshal([]byte{0xf0})
+	require.ErrorIs(t, err, ErrInvalidPayload)
+}
@@ -15,8 +15,10 @@
 package media
 
 import (
+	"maps"
 	"slices"
 	"strings"
+	"sync"
 )
 
 type CodecInfo struct {
@@ -34,56 +36,189 @@ type Codec interface {
 	Info() CodecInfo
 }
 
-var (
-	disabled        = make(map[string]struct{})
-	codecs          []Codec
-	codecOnRegister []func(c Codec)
-)
+// Registry holds a set of codecs, together with their enabled state and priorities.
+//
+// Package-level codec functions use the default registry, which codec packages register to. Custom registries
+// allow having a different codec policy for each service in the same process, see DefaultRegistry and Clone.
+type Registry struct {
+	mu         sync.RWMutex
+	codecs     []Codec
+	byName     map[string]Codec
+	byType     map[byte]Codec
+	disabled   map[string]struct{}
+	priority   map[string]int
+	onRegister []func(c Codec)
+}
 
-func CodecSetEnabled(name string, enabled bool) {
+// NewRegistry creates an empty codec registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		byName:   make(map[string]Codec),
+		byType:   make(map[byte]Codec),
+		disabled: make(map[string]struct{}),
+		priority: make(map[string]int),
+	}
+}
+
+var defaultRegistry = NewRegistry()
+
+// DefaultRegistry returns the registry used by package-level functions.
+func DefaultRegistry() *Registry {
+	return defaultRegistry
+}
+
+// Clone returns a copy of the registry with the same codecs, enabled state and priorities.
+// Registration callbacks are not copied.
+func (r *Registry) Clone() *Registry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return &Registry{
+		codecs:   slices.Clone(r.codecs),
+		byName:   maps.Clone(r.byName),
+		byType:   maps.Clone(r.byType),
+		disabled: maps.Clone(r.disabled),
+		priority: maps.Clone(r.priority),
+	}
+}
+
+// RegisterCodec adds a codec to the registry.
+//
+// Codecs with the same SDP name or the same static RTP payload type are replaced by the new codec,
+// which takes the place of the first replaced codec in the codec list.
+func (r *Registry) RegisterCodec(c Codec) {
+	info := c.Info()
+	name := strings.ToLower(info.SDPName)
+	r.mu.Lock()
+	replaced := false
+	for i := 0; i < len(r.codecs); {
+		old := r.codecs[i].Info()
+		oldName := strings.ToLower(old.SDPName)
+		sameName := name != "" && oldName == name
+		sameType := info.RTPIsStatic && old.RTPIsStatic && old.RTPDefType == info.RTPDefType
+		if !sameName && !sameType {
+			i++
+			continue
+		}
+		delete(r.byName, oldName)
+		if old.RTPIsStatic {
+			delete(r.byType, old.RTPDefType)
+		}
+		if !replaced {
+			r.codecs[i] = c
+			replaced = true
+			i++
+		} else {
+			r.codecs = slices.Delete(r.codecs, i, i+1)
+		}
+	}
+	if !replaced {
+		r.codecs = append(r.codecs, c)
+	}
+	if name != "" {
+		r.byName[name] = c
+	}
+	if info.RTPIsStatic {
+		r.byType[info.RTPDefType] = c
+	}
+	if info.Disabled {
+		r.disabled[strings.ToLower(info.SDPName)] = struct{}{}
+	}
+	callbacks := slices.Clone(r.onRegister)
+	r.mu.Unlock()
+	for _, fnc := range callbacks {
+		fnc(c)
+	}
+}
+
+// OnRegister calls fnc for all registered codecs, and for each codec registered later.
+func (r *Registry) OnRegister(fnc func(c Codec)) {
+	r.mu.Lock()
+	codecs := slices.Clone(r.codecs)
+	r.onRegister = append(r.onRegister, fnc)
+	r.mu.Unlock()
+	for _, c := range codecs {
+		fnc(c)
+	}
+}
+
+// SetEnabled enables or disables a codec by its SDP name.
+func (r *Registry) SetEnabled(name string, enabled bool) {
 	name = strings.ToLower(name)
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	if enabled {
-		delete(disabled, name)
+		delete(r.disabled, name)
 	} else {
-		disabled[name] = struct{}{}
+		r.disabled[name] = struct{}{}
 	}
 }
 
-func CodecsSetEnabled(codecs map[string]bool) {
+// SetAllEnabled enables or disables codecs by their SDP names.
+func (r *Registry) SetAllEnabled(codecs map[string]bool) {
 	for name, enabled := range codecs {
-		CodecSetEnabled(name, enabled)
+		r.SetEnabled(name, enabled)
 	}
 }
 
-func CodecEnabled(c Codec) bool {
+// Enabled checks if the codec is enabled. It returns false for nil codec.
+func (r *Registry) Enabled(c Codec) bool {
 	if c == nil {
 		return false
 	}
-	return CodecEnabledByName(c.Info().SDPName)
+	return r.EnabledByName(c.Info().SDPName)
 }
 
-func CodecEnabledByName(name string) bool {
+// EnabledByName checks if the codec with a given SDP name is enabled.
+func (r *Registry) EnabledByName(name string) bool {
 	name = strings.ToLower(name)
-	_, dis := disabled[name]
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, dis := r.disabled[name]
 	return !dis
 }
 
-func OnRegister(fnc func(c Codec)) {
-	for _, c := range codecs {
-		fnc(c)
+// SetPriority overrides the priority of the codec with a given SDP name. Codecs with higher priority are preferred.
+func (r *Registry) SetPriority(name string, priority int) {
+	name = strings.ToLower(name)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.priority[name] = priority
+}
+
+// ResetPriority removes the priority override for the codec, set by SetPriority.
+func (r *Registry) ResetPriority(name string) {
+	name = strings.ToLower(name)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.priority, name)
+}
+
+// Priority returns the priority of the codec. It defaults to CodecInfo.Priority.
+func (r *Registry) Priority(c Codec) int {
+	info := c.Info()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if p, ok := r.priority[strings.ToLower(info.SDPName)]; ok {
+		return p
 	}
-	codecOnRegister = append(codecOnRegister, fnc)
+	return info.Priority
 }
 
-func Codecs() []Codec {
-	return slices.Clone(codecs)
+// Codecs returns all registered codecs.
+func (r *Registry) Codecs() []Codec {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return slices.Clone(r.codecs)
 }
 
-func EnabledCodecs() []Codec {
-	out := make([]Codec, 0, len(codecs))
-	for _, c := range codecs {
+// EnabledCodecs returns all enabled codecs.
+func (r *Registry) EnabledCodecs() []Codec {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]Codec, 0, len(r.codecs))
+	for _, c := range r.codecs {
 		name := strings.ToLower(c.Info().SDPName)
-		if _, ok := disabled[name]; ok {
+		if _, ok := r.disabled[name]; ok {
 			continue
 		}
 		out = append(out, c)
@@ -91,14 +226,60 @@ func EnabledCodecs() []Codec {
 	return out
 }
 
-func RegisterCodec(c Codec) {
-	codecs = append(codecs, c)
-	if info := c.Info(); info.Disabled {
-		CodecSetEnabled(info.SDPName, false)
+// CodecByName returns an enabled codec by its SDP name, for example "PCMU/8000". Channel count of 1 may be omitted.
+func (r *Registry) CodecByName(name string) Codec {
+	name = strings.ToLower(name)
+	r.mu.RLock()
+	c, ok := r.byName[name]
+	if !ok && strings.Count(name, "/") == 2 {
+		if base, ok := strings.CutSuffix(name, "/1"); ok {
+			c = r.byName[base]
+		}
 	}
-	for _, fnc := range codecOnRegister {
-		fnc(c)
+	r.mu.RUnlock()
+	if !r.Enabled(c) {
+		return nil
 	}
+	return c
+}
+
+// CodecByPayloadType returns a codec with a given static RTP payload type. Codecs are returned even if disabled.
+func (r *Registry) CodecByPayloadType(typ byte) Codec {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.byType[typ]
+}
+
+func CodecSetEnabled(name string, enabled bool) {
+	defaultRegistry.SetEnabled(name, enabled)
+}
+
+func CodecsSetEnabled(codecs map[string]bool) {
+	defaultRegistry.SetAllEnabled(codecs)
+}
+
+func CodecEnabled(c Codec) bool {
+	return defaultRegistry.Enabled(c)
+}
+
+func CodecEnabledByName(name string) bool {
+	return defaultRegistry.EnabledByName(name)
+}
+
+func OnRegister(fnc func(c Codec)) {
+	defaultRegistry.OnRegister(fnc)
+}
+
+func Codecs() []Codec {
+	return defaultRegistry.Codecs()
+}
+
+func EnabledCodecs() []Codec {
+	return defaultRegistry.EnabledCodecs()
+}
+
+func RegisterCodec(c Codec) {
+	defaultRegistry.RegisterCodec(c)
 }
 
 func NewCodec(info CodecInfo) Codec {
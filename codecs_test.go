@@ -0,0 +1,134 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package media
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRegistry() (*Registry, Codec, Codec) {
+	r := NewRegistry()
+	a := NewCodec(CodecInfo{SDPName: "A/8000", SampleRate: 8000, RTPDefType: 0, RTPIsStatic: true, Priority: 10})
+	b := NewCodec(CodecInfo{SDPName: "B/16000", SampleRate: 16000, RTPDefType: 9, RTPIsStatic: true, Priority: 20, Disabled: true})
+	r.RegisterCodec(a)
+	r.RegisterCodec(b)
+	return r, a, b
+}
+
+func TestRegistry(t *testing.T) {
+	r, a, b := newTestRegistry()
+	require.Equal(t, []Codec{a, b}, r.Codecs())
+	require.Equal(t, []Codec{a}, r.EnabledCodecs())
+
+	require.Equal(t, a, r.CodecByName("a/8000"))
+	require.Equal(t, a, r.CodecByName("A/8000/1"))
+	require.Nil(t, r.CodecByName("A/8000/2"))
+	require.Nil(t, r.CodecByName("B/16000"))
+	require.Equal(t, b, r.CodecByPayloadType(9))
+	require.Nil(t, r.CodecByPayloadType(8))
+
+	r.SetEnabled("b/16000", true)
+	require.True(t, r.Enabled(b))
+	require.Equal(t, b, r.CodecByName("B/16000"))
+	require.False(t, r.Enabled(nil))
+
+	require.Equal(t, 10, r.Priority(a))
+	r.SetPriority("A/8000", 30)
+	require.Equal(t, 30, r.Priority(a))
+	r.ResetPriority("A/8000")
+	require.Equal(t, 10, r.Priority(a))
+
+	var got []Codec
+	r.OnRegister(func(c Codec) {
+		got = append(got, c)
+	})
+	c := NewCodec(CodecInfo{SDPName: "C/48000", SampleRate: 48000})
+	r.RegisterCodec(c)
+	require.Equal(t, []Codec{a, b, c}, got)
+}
+
+func TestRegistryClone(t *testing.T) {
+	r, a, b := newTestRegistry()
+	r2 := r.Clone()
+	r2.SetEnabled("A/8000", false)
+	r2.SetEnabled("B/16000", true)
+	r2.SetPriority("A/8000", 5)
+	r2.RegisterCodec(NewCodec(CodecInfo{SDPName: "C/48000", SampleRate: 48000}))
+
+	require.Equal(t, []Codec{a}, r.EnabledCodecs())
+	require.Len(t, r.Codecs(), 2)
+	require.Equal(t, 10, r.Priority(a))
+	require.Equal(t, a, r.CodecByName("A/8000"))
+	require.Nil(t, r.CodecByName("C/48000"))
+
+	require.Equal(t, []Codec{b}, r2.EnabledCodecs()[:1])
+	require.Len(t, r2.Codecs(), 3)
+	require.Equal(t, 5, r2.Priority(a))
+	require.Nil(t, r2.CodecByName("A/8000"))
+}
+
+func TestRegistryReplace(t *testing.T) {
+	r, a, b := newTestRegistry()
+	c := NewCodec(CodecInfo{SDPName: "C/8000", SampleRate: 8000})
+	r.RegisterCodec(c)
+	require.Equal(t, []Codec{a, b, c}, r.Codecs())
+
+	// Same SDP name.
+	a2 := NewCodec(CodecInfo{SDPName: "a/8000", SampleRate: 8000, RTPDefType: 0, RTPIsStatic: true})
+	r.RegisterCodec(a2)
+	require.Equal(t, []Codec{a2, b, c}, r.Codecs())
+	require.Equal(t, a2, r.CodecByName("A/8000"))
+	require.Equal(t, a2, r.CodecByPayloadType(0))
+
+	// Same static payload type, but a different name.
+	b2 := NewCodec(CodecInfo{SDPName: "B2/16000", SampleRate: 16000, RTPDefType: 9, RTPIsStatic: true})
+	r.RegisterCodec(b2)
+	require.Equal(t, []Codec{a2, b2, c}, r.Codecs())
+	require.Nil(t, r.CodecByName("B/16000"))
+	require.Equal(t, b2, r.CodecByName("B2/16000"))
+	require.Equal(t, b2, r.CodecByPayloadType(9))
+
+	// Matches two registered codecs.
+	c2 := NewCodec(CodecInfo{SDPName: "C/8000", SampleRate: 8000, RTPDefType: 0, RTPIsStatic: true})
+	r.RegisterCodec(c2)
+	require.Equal(t, []Codec{c2, b2}, r.Codecs())
+	require.Nil(t, r.CodecByName("A/8000"))
+	require.Equal(t, c2, r.CodecByName("C/8000"))
+	require.Equal(t, c2, r.CodecByPayloadType(0))
+}
+
+func TestRegistryConcurrent(t *testing.T) {
+	r, a, _ := newTestRegistry()
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 100 {
+				r.SetEnabled("A/8000", j%2 == 0)
+				r.SetPriority(fmt.Sprintf("X%d", i), j)
+				_ = r.Enabled(a)
+				_ = r.EnabledCodecs()
+				_ = r.CodecByName("A/8000")
+				_ = r.Priority(a)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,150 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package media
+
+import (
+	"errors"
+	"io"
+)
+
+// ConvertReader returns a reader that converts interleaved PCM samples from src to a given sample rate and number of channels.
+// Source reader must return io.EOF, or no samples, when the stream ends.
+//
+// Channels are mixed by averaging them, or by duplicating a mono channel. Resampling is done on a mono signal,
+// thus a stereo source that requires resampling will be downmixed.
+// ReadSample returns io.ErrShortBuffer if the buffer cannot fit a single sample of all channels.
+func ConvertReader(src Reader[PCM16Sample], srcRate, srcChannels int, sampleRate, channels int) ReadCloser[PCM16Sample] {
+	if srcRate <= 0 || sampleRate <= 0 {
+		panic("invalid sample rate")
+	}
+	if srcChannels <= 0 || channels <= 0 {
+		panic("invalid channel count")
+	}
+	r := &convertReader{
+		src:         src,
+		srcChannels: srcChannels,
+		channels:    channels,
+		frame:       max(srcRate/DefFramesPerSec, 1) * srcChannels,
+	}
+	if srcRate != sampleRate {
+		r.resample = ResampleWriter(NewPCM16BufferWriter(&r.mono, sampleRate), srcRate)
+	}
+	return r
+}
+
+type convertReader struct {
+	src         Reader[PCM16Sample]
+	srcChannels int
+	channels    int
+	frame       int
+	resample    PCM16Writer // nil, if sample rates match
+
+	in   PCM16Sample // samples read from the source
+	mono PCM16Sample // resampled mono samples
+	out  PCM16Sample // converted samples
+	eof  bool
+}
+
+// mixChannels converts interleaved samples from src with a given number of channels and appends them to dst.
+func mixChannels(dst PCM16Sample, channels int, src PCM16Sample, srcChannels int) PCM16Sample {
+	if channels == srcChannels {
+		return append(dst, src...)
+	}
+	for i := 0; i+srcChannels <= len(src); i += srcChannels {
+		var v int16
+		if srcChannels == 1 {
+			v = src[i]
+		} else {
+			var sum int32
+			for _, s := range src[i : i+srcChannels] {
+				sum += int32(s)
+			}
+			v = int16(sum / int32(srcChannels))
+		}
+		for range channels {
+			dst = append(dst, v)
+		}
+	}
+	return dst
+}
+
+func (r *convertReader) fill() error {
+	if cap(r.in) < r.frame {
+		r.in = make(PCM16Sample, r.frame)
+	}
+	r.in = r.in[:r.frame]
+	n, err := r.src.ReadSample(r.in)
+	if errors.Is(err, io.EOF) || (err == nil && n == 0) {
+		r.eof = true
+	} else if err != nil {
+		return err
+	}
+	in := r.in[:n]
+	if r.resample == nil {
+		r.out = mixChannels(r.out, r.channels, in, r.srcChannels)
+	} else {
+		if r.srcChannels != 1 {
+			in = mixChannels(in[:0], 1, in, r.srcChannels)
+		}
+		if len(in) > 0 {
+			if err = r.resample.WriteSample(in); err != nil {
+				return err
+			}
+		}
+		if r.eof {
+			// Flush the resampler.
+			if err = r.resample.Close(); err != nil {
+				return err
+			}
+		}
+		r.out = mixChannels(r.out, r.channels, r.mono, 1)
+		r.mono = r.mono[:0]
+	}
+	return nil
+}
+
+func (r *convertReader) ReadSample(buf PCM16Sample) (int, error) {
+	if len(buf) < r.channels {
+		// Cannot fit a single multichannel sample.
+		return 0, io.ErrShortBuffer
+	}
+	for len(r.out) < len(buf) && !r.eof {
+		if err := r.fill(); err != nil {
+			return 0, err
+		}
+	}
+	if len(r.out) == 0 && r.eof {
+		return 0, io.EOF
+	}
+	// Never split multichannel samples.
+	sz := min(len(buf), len(r.out))
+	sz -= sz % r.channels
+	n := copy(buf, r.out[:sz])
+	r.out = r.out[:copy(r.out, r.out[n:])]
+	return n, nil
+}
+
+func (r *convertReader) Close() error {
+	var err error
+	if r.resample != nil && !r.eof {
+		err = r.resample.Close()
+	}
+	if c, ok := r.src.(io.Closer); ok {
+		if err2 := c.Close(); err2 != nil {
+			err = err2
+		}
+	}
+	return err
+}
@@ -19,6 +19,8 @@ import (
 	"encoding/binary"
 	"io"
 	"math"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/livekit/media-sdk"
@@ -29,13 +31,43 @@ import (
 const SDPName = "telephone-event/8000"
 const SampleRate = 8000
 
+// Rates lists RTP clock rates supported for telephone events.
+// Events should use the same clock rate as the audio codec.
+var Rates = []int{SampleRate, 16000, 48000}
+
 func init() {
-	media.RegisterCodec(media.NewCodec(media.CodecInfo{
-		SDPName:     SDPName,
-		SampleRate:  SampleRate,
-		RTPIsStatic: false,
-		Priority:    -100, // let it be last in SDP
-	}))
+	for _, rate := range Rates {
+		media.RegisterCodec(media.NewCodec(media.CodecInfo{
+			SDPName:     SDPNameWithRate(rate),
+			SampleRate:  rate,
+			RTPIsStatic: false,
+			Priority:    -100, // let it be last in SDP
+		}))
+	}
+}
+
+// SDPNameWithRate returns telephone event SDP name for a given RTP clock rate.
+func SDPNameWithRate(rate int) string {
+	if rate == SampleRate {
+		return SDPName
+	}
+	return "telephone-event/" + strconv.Itoa(rate)
+}
+
+// ParseSDPName checks if the SDP name describes telephone events and returns their RTP clock rate.
+// Names are case-insensitive and may include a channel count of 1.
+func ParseSDPName(name string) (int, bool) {
+	name = strings.ToLower(name)
+	rest, ok := strings.CutPrefix(name, "telephone-event/")
+	if !ok {
+		return 0, false
+	}
+	rest = strings.TrimSuffix(rest, "/1")
+	rate, err := strconv.Atoi(rest)
+	if err != nil || rate <= 0 {
+		return 0, false
+	}
+	return rate, true
 }
 
 const (
@@ -143,6 +175,14 @@ type Event struct {
 	End    bool
 }
 
+// Duration converts event duration to time, given the RTP clock rate of events.
+func (ev Event) Duration(clockRate int) time.Duration {
+	if clockRate <= 0 {
+		clockRate = SampleRate
+	}
+	return time.Duration(ev.Dur) * time.Second / time.Duration(clockRate)
+}
+
 func Decode(data []byte) (Event, error) {
 	if len(data) < 4 {
 		return Event{}, io.ErrUnexpectedEOF
@@ -191,12 +231,17 @@ func Encode(out []byte, ev Event) (int, error) {
 // Write in-band (analog) and off-band (digital) DTMF tones to audio and RTP streams respectively.
 //
 // Digits may contain a special character 'w' which adds a 0.5 sec delay.
+// Event durations use the clock rate of the events stream, which should match the audio codec clock rate.
 func Write(ctx context.Context, audio media.Writer[media.PCM16Sample], events *rtp.Stream, startTs uint32, digits string) error {
 	const framesPerSec = int(time.Second / rtp.DefFrameDur)
 	var (
 		buf    [4]byte
 		pcmBuf media.PCM16Sample
 	)
+	clockRate := SampleRate
+	if events != nil && events.ClockRate() > 0 {
+		clockRate = events.ClockRate()
+	}
 	if audio != nil {
 		pcmBuf = make(media.PCM16Sample, audio.SampleRate()/framesPerSec)
 	}
@@ -219,7 +264,7 @@ func Write(ctx context.Context, audio media.Writer[media.PCM16Sample], events *r
 		totalDur = dt
 		nextDelay = 0
 		if events != nil {
-			events.Delay(uint32(dt / (time.Second / SampleRate)))
+			events.Delay(uint32(dt * time.Duration(clockRate) / time.Second))
 		}
 	}
 
@@ -273,7 +318,7 @@ func Write(ctx context.Context, audio media.Writer[media.PCM16Sample], events *r
 			n, err := Encode(buf[:], Event{
 				Code:   code,
 				Volume: eventVolume,
-				Dur:    uint16(dur / (time.Second / SampleRate)),
+				Dur:    uint16(dur * time.Duration(clockRate) / time.Second),
 				End:    end,
 			})
 			if err != nil {
@@ -293,7 +338,7 @@ func Write(ctx context.Context, audio media.Writer[media.PCM16Sample], events *r
 					return err
 				}
 				// advance the timestamp now
-				events.Delay(uint32(totalDur / (time.Second / SampleRate)))
+				events.Delay(uint32(totalDur * time.Duration(clockRate) / time.Second))
 			}
 		}
 		remaining -= step
@@ -15,23 +15,120 @@
 package media
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 )
 
+// DumpStream describes a media stream being dumped.
+type DumpStream struct {
+	// Name of the stream, for example "rtp_in".
+	Name string
+	// Codec is SDP name of the codec for encoded streams. It is empty for PCM16 samples.
+	Codec string
+	// FileExt is a file extension used for raw encoded streams.
+	FileExt    string
+	SampleRate int
+	Channels   int
+}
+
+// Dumper creates destinations for media dumps, used for debugging. See dump package for an implementation
+// that supports per-call configuration and media containers.
+type Dumper interface {
+	Dump(s DumpStream) (WriteCloser[Frame], error)
+}
+
+// DumpTo returns a writer that passes samples to w and writes them to a dump created by d.
+// If d is nil, w is returned as-is. Errors writing the dump do not affect w, they are returned from Close.
+func DumpTo[T Frame](d Dumper, s DumpStream, w WriteCloser[T]) (WriteCloser[T], error) {
+	if d == nil {
+		return w, nil
+	}
+	if s.SampleRate == 0 {
+		s.SampleRate = w.SampleRate()
+	}
+	if s.Channels == 0 {
+		s.Channels = 1
+	}
+	dw, err := d.Dump(s)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create media dump %q: %w", s.Name, err)
+	}
+	return &dumpWriter[T]{name: s.Name, w: w, d: dw}, nil
+}
+
+type dumpWriter[T Frame] struct {
+	name string
+	w    WriteCloser[T]
+	d    WriteCloser[Frame]
+	err  error
+}
+
+func (w *dumpWriter[T]) String() string {
+	return fmt.Sprintf("Dump(%s) -> %s", w.name, w.w)
+}
+
+func (w *dumpWriter[T]) SampleRate() int {
+	return w.w.SampleRate()
+}
+
+func (w *dumpWriter[T]) WriteSample(sample T) error {
+	err := w.w.WriteSample(sample)
+	if w.err == nil {
+		w.err = w.d.WriteSample(sample)
+	}
+	return err
+}
+
+func (w *dumpWriter[T]) Close() error {
+	err := w.w.Close()
+	derr := w.d.Close()
+	if w.err != nil {
+		derr = w.err
+	}
+	if derr != nil {
+		derr = fmt.Errorf("media dump %q failed: %w", w.name, derr)
+	}
+	return errors.Join(err, derr)
+}
+
+// NewRawDumper creates a Dumper that writes raw samples to files in a given directory.
+// Files are named "<name>_ar<rate>.<ext>", where extension is "s16le" for PCM16 streams.
+func NewRawDumper(dir string) Dumper {
+	return rawDumper{dir: dir}
+}
+
+type rawDumper struct {
+	dir string
+}
+
+func (d rawDumper) Dump(s DumpStream) (WriteCloser[Frame], error) {
+	ext := s.FileExt
+	if ext == "" {
+		ext = "raw"
+		if s.Codec == "" {
+			ext = "s16le"
+		}
+	}
+	f, err := os.Create(filepath.Join(d.dir, fmt.Sprintf("%s_ar%d.%s", s.Name, s.SampleRate, ext)))
+	if err != nil {
+		return nil, err
+	}
+	return NewFileWriter[Frame](f, s.SampleRate), nil
+}
+
 func DumpWriterPCM16(name string, w PCM16Writer) PCM16Writer {
 	return DumpWriter[PCM16Sample]("s16le", name, w)
 }
 
+// DumpWriter dumps raw samples to a file in the current directory. If the file cannot be created, w is returned as-is.
+//
+// Deprecated: Use DumpTo.
 func DumpWriter[T Frame](ext string, name string, w WriteCloser[T]) WriteCloser[T] {
-	rate := w.SampleRate()
-	nameOut := fmt.Sprintf("%s_ar%d.%s", name, rate, ext)
-	f, err := os.Create(nameOut)
+	w2, err := DumpTo(NewRawDumper(""), DumpStream{Name: name, FileExt: ext}, w)
 	if err != nil {
-		panic(err)
-	}
-	return MultiWriter[T]{
-		w,
-		NewFileWriter[T](f, rate),
+		return w
 	}
+	return w2
 }
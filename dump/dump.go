@@ -0,0 +1,185 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dump implements media.Dumper that writes media streams of a single call to WAV and WebM files.
+package dump
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/livekit/media-sdk"
+	"github.com/livekit/media-sdk/ogg"
+	"github.com/livekit/media-sdk/wav"
+	"github.com/livekit/media-sdk/webm"
+)
+
+// CreateFunc creates a destination for a dump file with a given name.
+type CreateFunc func(name string) (io.WriteCloser, error)
+
+// Config for per-call media dumps.
+type Config struct {
+	// Dir is a directory where dump files are created. It is ignored if Create is set.
+	Dir string
+	// CallID is used as a prefix for all file names.
+	CallID string
+	// Create is an optional function that creates dump destinations, instead of files in Dir.
+	Create CreateFunc
+}
+
+// New creates a media.Dumper for a single call.
+//
+// PCM16 streams are written to WAV files, Opus streams are written to WebM files, other codecs are written as raw frames.
+// Each file is named "<call-id>_<stream>_<n>.<ext>", where n makes names unique within the call.
+func New(conf Config) media.Dumper {
+	d := &dumper{conf: conf}
+	if d.conf.Create == nil {
+		dir := conf.Dir
+		d.conf.Create = func(name string) (io.WriteCloser, error) {
+			return os.Create(filepath.Join(dir, name))
+		}
+	}
+	return d
+}
+
+type dumper struct {
+	conf Config
+	mu   sync.Mutex
+	cnt  map[string]int
+}
+
+func (d *dumper) fileName(name, ext string) string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.cnt == nil {
+		d.cnt = make(map[string]int)
+	}
+	d.cnt[name]++
+	n := d.cnt[name]
+	if d.conf.CallID != "" {
+		name = d.conf.CallID + "_" + name
+	}
+	return fmt.Sprintf("%s_%d.%s", name, n, ext)
+}
+
+func (d *dumper) Dump(s media.DumpStream) (media.WriteCloser[media.Frame], error) {
+	if s.SampleRate <= 0 {
+		return nil, fmt.Errorf("invalid sample rate for dump %q: %d", s.Name, s.SampleRate)
+	}
+	if s.Channels <= 0 {
+		s.Channels = 1
+	}
+	var ext string
+	switch {
+	case s.Codec == "":
+		ext = "wav"
+	case isOpus(s.Codec):
+		ext = "webm"
+	default:
+		ext = s.FileExt
+		if ext == "" {
+			ext = "raw"
+		}
+	}
+	f, err := d.conf.Create(d.fileName(s.Name, ext))
+	if err != nil {
+		return nil, err
+	}
+	switch ext {
+	case "wav":
+		return &pcmWriter{w: wav.NewWriter(f, s.SampleRate, s.Channels)}, nil
+	case "webm":
+		return newOpusWriter(f, s)
+	default:
+		return media.NewFileWriter[media.Frame](f, s.SampleRate), nil
+	}
+}
+
+func isOpus(codec string) bool {
+	name, _, _ := strings.Cut(codec, "/")
+	return strings.EqualFold(name, "opus")
+}
+
+// pcmWriter adapts PCM16 writer to arbitrary frames. Frames which are not PCM16Sample are decoded as 16 bit little-endian PCM.
+type pcmWriter struct {
+	w   media.PCM16Writer
+	buf []byte
+}
+
+func (w *pcmWriter) String() string {
+	return w.w.String()
+}
+
+func (w *pcmWriter) SampleRate() int {
+	return w.w.SampleRate()
+}
+
+func (w *pcmWriter) WriteSample(sample media.Frame) error {
+	if s, ok := sample.(media.PCM16Sample); ok {
+		return w.w.WriteSample(s)
+	}
+	if sz := sample.Size(); cap(w.buf) < sz {
+		w.buf = make([]byte, sz)
+	} else {
+		w.buf = w.buf[:sz]
+	}
+	n, err := sample.CopyTo(w.buf)
+	if err != nil {
+		return err
+	}
+	if n%2 != 0 {
+		return errors.New("odd PCM16 sample size")
+	}
+	s := make(media.PCM16Sample, n/2)
+	for i := range s {
+		s[i] = int16(binary.LittleEndian.Uint16(w.buf[2*i:]))
+	}
+	return w.w.WriteSample(s)
+}
+
+func (w *pcmWriter) Close() error {
+	return w.w.Close()
+}
+
+func newOpusWriter(f io.WriteCloser, s media.DumpStream) (media.WriteCloser[media.Frame], error) {
+	r := webm.NewRecorder(f)
+	tw, err := webm.NewTrackWriter[media.Frame](r, webm.Track{
+		Name:         s.Name,
+		Codec:        webm.CodecOpus,
+		CodecPrivate: ogg.AppendOpusHead(nil, s.Channels, ogg.OpusPreSkip, s.SampleRate),
+		SampleRate:   s.SampleRate,
+		Channels:     s.Channels,
+	})
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return &recorderWriter{TrackWriter: tw, r: r}, nil
+}
+
+// recorderWriter closes the recorder together with its only track.
+type recorderWriter struct {
+	*webm.TrackWriter[media.Frame]
+	r *webm.Recorder
+}
+
+func (w *recorderWriter) Close() error {
+	return w.r.Close()
+}
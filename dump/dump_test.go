@@ -0,0 +1,127 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dump
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/media-sdk"
+	"github.com/livekit/media-sdk/wav"
+	"github.com/livekit/media-sdk/webm"
+)
+
+type byteSample []byte
+
+func (s byteSample) Size() int { return len(s) }
+
+func (s byteSample) CopyTo(dst []byte) (int, error) {
+	if len(dst) < len(s) {
+		return 0, io.ErrShortBuffer
+	}
+	return copy(dst, s), nil
+}
+
+func TestDumpWAV(t *testing.T) {
+	dir := t.TempDir()
+	d := New(Config{Dir: dir, CallID: "call1"})
+
+	var got []media.PCM16Sample
+	for range 2 {
+		w, err := media.DumpTo(d, media.DumpStream{Name: "rtp_in"}, media.NewPCM16FrameWriter(&got, 16000))
+		require.NoError(t, err)
+		require.NoError(t, w.WriteSample(media.PCM16Sample{1, 2, 3}))
+		require.NoError(t, w.Close())
+	}
+	require.Len(t, got, 2)
+
+	for _, name := range []string{"call1_rtp_in_1.wav", "call1_rtp_in_2.wav"} {
+		f, err := os.Open(filepath.Join(dir, name))
+		require.NoError(t, err)
+		r, err := wav.NewReader(f, 16000, 1)
+		require.NoError(t, err)
+		buf := make(media.PCM16Sample, 10)
+		n, err := r.ReadSample(buf)
+		require.NoError(t, err)
+		require.Equal(t, media.PCM16Sample{1, 2, 3}, buf[:n])
+		require.NoError(t, r.Close())
+	}
+}
+
+type bufferCloser struct {
+	bytes.Buffer
+}
+
+func (*bufferCloser) Close() error { return nil }
+
+func TestDumpCreate(t *testing.T) {
+	files := make(map[string]*bufferCloser)
+	d := New(Config{CallID: "call2", Create: func(name string) (io.WriteCloser, error) {
+		b := &bufferCloser{}
+		files[name] = b
+		return b, nil
+	}})
+
+	dumpFrames := func(s media.DumpStream, frames ...byteSample) {
+		w, err := d.Dump(s)
+		require.NoError(t, err)
+		for _, f := range frames {
+			require.NoError(t, w.WriteSample(f))
+		}
+		require.NoError(t, w.Close())
+	}
+	dumpFrames(media.DumpStream{Name: "rtp_out", Codec: "opus/48000/2", SampleRate: 48000, Channels: 2}, byteSample{1, 2}, byteSample{3})
+	dumpFrames(media.DumpStream{Name: "rtp_out", Codec: "PCMU/8000", FileExt: "mulaw", SampleRate: 8000}, byteSample{1, 2}, byteSample{3})
+	dumpFrames(media.DumpStream{Name: "pcm", SampleRate: 8000}, byteSample{1, 0, 2, 0})
+
+	require.Len(t, files, 3)
+	require.Equal(t, []byte{1, 2, 3}, files["call2_rtp_out_2.mulaw"].Bytes())
+
+	r, err := webm.NewReader(bytes.NewReader(files["call2_rtp_out_1.webm"].Bytes()))
+	require.NoError(t, err)
+	tracks := r.Tracks()
+	require.Len(t, tracks, 1)
+	require.Equal(t, webm.CodecOpus, tracks[0].Codec)
+	require.Equal(t, 48000, tracks[0].SampleRate)
+	require.Equal(t, 2, tracks[0].Channels)
+	var blocks [][]byte
+	for {
+		b, err := r.ReadBlock()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		blocks = append(blocks, b.Data)
+	}
+	require.Equal(t, [][]byte{{1, 2}, {3}}, blocks)
+
+	wr, err := wav.NewReader(bytes.NewReader(files["call2_pcm_1.wav"].Bytes()), 8000, 1)
+	require.NoError(t, err)
+	buf := make(media.PCM16Sample, 10)
+	n, err := wr.ReadSample(buf)
+	require.NoError(t, err)
+	require.Equal(t, media.PCM16Sample{1, 2}, buf[:n])
+}
+
+func TestDumpError(t *testing.T) {
+	d := New(Config{Dir: filepath.Join(t.TempDir(), "missing")})
+	_, err := d.Dump(media.DumpStream{Name: "test", SampleRate: 8000})
+	require.Error(t, err)
+}
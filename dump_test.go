@@ -0,0 +1,90 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package media
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testDumper struct {
+	streams []DumpStream
+	frames  []Frame
+	err     error
+}
+
+func (d *testDumper) Dump(s DumpStream) (WriteCloser[Frame], error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	d.streams = append(d.streams, s)
+	return &testFrameWriter{d: d, rate: s.SampleRate}, nil
+}
+
+type testFrameWriter struct {
+	d    *testDumper
+	rate int
+}
+
+func (w *testFrameWriter) String() string  { return "test" }
+func (w *testFrameWriter) SampleRate() int { return w.rate }
+func (w *testFrameWriter) Close() error    { return nil }
+
+func (w *testFrameWriter) WriteSample(f Frame) error {
+	w.d.frames = append(w.d.frames, f)
+	return nil
+}
+
+func TestDumpTo(t *testing.T) {
+	var got []PCM16Sample
+	w := NewPCM16FrameWriter(&got, 16000)
+
+	w2, err := DumpTo[PCM16Sample](nil, DumpStream{Name: "test"}, w)
+	require.NoError(t, err)
+	require.True(t, w == w2)
+
+	d := &testDumper{}
+	w2, err = DumpTo(d, DumpStream{Name: "test"}, w)
+	require.NoError(t, err)
+	require.NoError(t, w2.WriteSample(PCM16Sample{1, 2}))
+	require.NoError(t, w2.Close())
+	require.Equal(t, []PCM16Sample{{1, 2}}, got)
+	require.Equal(t, []DumpStream{{Name: "test", SampleRate: 16000, Channels: 1}}, d.streams)
+	require.Equal(t, []Frame{PCM16Sample{1, 2}}, d.frames)
+
+	d = &testDumper{err: errors.New("test")}
+	_, err = DumpTo(d, DumpStream{Name: "test"}, w)
+	require.Error(t, err)
+}
+
+func TestRawDumper(t *testing.T) {
+	dir := t.TempDir()
+	var got []PCM16Sample
+	w, err := DumpTo(NewRawDumper(dir), DumpStream{Name: "test"}, NewPCM16FrameWriter(&got, 8000))
+	require.NoError(t, err)
+	require.NoError(t, w.WriteSample(PCM16Sample{1, 2}))
+	require.NoError(t, w.Close())
+
+	data, err := os.ReadFile(filepath.Join(dir, "test_ar8000.s16le"))
+	require.NoError(t, err)
+	require.Equal(t, []byte{1, 0, 2, 0}, data)
+
+	_, err = DumpTo(NewRawDumper(filepath.Join(dir, "missing")), DumpStream{Name: "test"}, NewPCM16FrameWriter(&got, 8000))
+	require.Error(t, err)
+}
@@ -30,12 +30,77 @@ import (
 const SDPName = "G722/8000"
 
 var (
-	g722ID     atomic.Uint32
-	dumpToFile = os.Getenv("LK_DUMP_G722") == "true"
+	g722ID atomic.Uint32
+	dumper atomic.Pointer[media.Dumper]
 )
 
+// SetDumper enables dumps of G.722 encoders and decoders created after this call. Nil disables the dumps.
+// It is enabled by LK_DUMP_G722=true environment variable by default.
+func SetDumper(d media.Dumper) {
+	if d == nil {
+		dumper.Store(nil)
+		return
+	}
+	dumper.Store(&d)
+}
+
 func init() {
-	media.RegisterCodec(rtp.NewAudioCodec(media.CodecInfo{
+	if os.Getenv("LK_DUMP_G722") == "true" {
+		SetDumper(media.NewRawDumper(""))
+	}
+	c, err := NewCodec()
+	if err != nil {
+		panic(err)
+	}
+	media.RegisterCodec(c)
+}
+
+// Supported G.722 bitrates. Lower bitrates leave the least significant bits of each octet for auxiliary data,
+// thus the RTP payload format is the same for all of them.
+const (
+	Bitrate64000 = g722.Rate64000
+	Bitrate56000 = g722.Rate56000
+	Bitrate48000 = g722.Rate48000
+)
+
+type config struct {
+	bitrate int
+}
+
+// Option configures G.722 codec, encoder or decoder.
+type Option func(c *config)
+
+// WithBitrate sets G.722 bitrate: Bitrate64000 (default), Bitrate56000 or Bitrate48000.
+func WithBitrate(bitrate int) Option {
+	return func(c *config) {
+		c.bitrate = bitrate
+	}
+}
+
+func newConfig(opts []Option) (config, error) {
+	c := config{bitrate: Bitrate64000}
+	for _, o := range opts {
+		o(&c)
+	}
+	switch c.bitrate {
+	case Bitrate64000, Bitrate56000, Bitrate48000:
+	default:
+		return c, fmt.Errorf("unsupported G.722 bitrate: %d", c.bitrate)
+	}
+	return c, nil
+}
+
+// auxBits returns the number of least significant bits in each octet that are not used by the codec.
+func (c config) auxBits() int {
+	return (Bitrate64000 - c.bitrate) / 8000
+}
+
+// NewCodec creates G.722 RTP codec with given options. The default codec registered by this package uses 64 kbit/s.
+func NewCodec(opts ...Option) (rtp.AudioCodec, error) {
+	if _, err := newConfig(opts); err != nil {
+		return nil, err
+	}
+	return rtp.NewAudioCodec(media.CodecInfo{
 		SDPName:      SDPName,
 		SampleRate:   16000,
 		RTPClockRate: 8000,
@@ -43,11 +108,19 @@ func init() {
 		RTPIsStatic:  true,
 		Priority:     -5,
 		FileExt:      "g722",
-	}, Decode, Encode))
+	}, func(w media.PCM16Writer) Writer {
+		return NewDecoder(w, opts...)
+	}, func(w Writer) media.PCM16Writer {
+		return NewEncoder(w, opts...)
+	}), nil
 }
 
 type Sample []byte
 
+func encStream(name string) media.DumpStream {
+	return media.DumpStream{Name: name, Codec: SDPName, FileExt: "g722", SampleRate: 16000}
+}
+
 func (s Sample) Size() int {
 	return len(s)
 }
@@ -85,7 +158,9 @@ type Writer = media.WriteCloser[Sample]
 type Decoder struct {
 	f   g722.Flags
 	d   *g722.Decoder
+	aux int
 	buf media.PCM16Sample
+	in  Sample
 	w   media.PCM16Writer
 }
 
@@ -106,11 +181,29 @@ func (d *Decoder) WriteSample(in Sample) error {
 	if cap(d.buf) < sz {
 		d.buf = make([]int16, sz)
 	}
+	if d.aux != 0 {
+		// Decoder expects codes without auxiliary bits.
+		d.in = append(d.in[:0], in...)
+		for i := range d.in {
+			d.in[i] >>= d.aux
+		}
+		in = d.in
+	}
 	n := d.d.Decode(d.buf, in)
 	return d.w.WriteSample(d.buf[:n])
 }
 
-func Decode(w media.PCM16Writer) (w2 Writer) {
+// Decode creates a 64 kbit/s G.722 decoder that writes PCM to w.
+func Decode(w media.PCM16Writer) Writer {
+	return NewDecoder(w)
+}
+
+// NewDecoder creates a G.722 decoder that writes PCM to w. It panics if options are invalid.
+func NewDecoder(w media.PCM16Writer, opts ...Option) (w2 Writer) {
+	conf, err := newConfig(opts)
+	if err != nil {
+		panic(err)
+	}
 	var f g722.Flags
 	switch w.SampleRate() {
 	case 8000:
@@ -121,20 +214,25 @@ func Decode(w media.PCM16Writer) (w2 Writer) {
 	case 16000:
 		// default
 	}
-	if dumpToFile {
+	if d := dumper.Load(); d != nil {
 		id := g722ID.Add(1)
 		pref := fmt.Sprintf("sip_g722_dec_%d", id)
-		w = media.DumpWriterPCM16(pref+"_out", w)
+		if dw, err := media.DumpTo(*d, media.DumpStream{Name: pref + "_out"}, w); err == nil {
+			w = dw
+		}
 		defer func() {
-			w2 = media.DumpWriter[Sample]("g722", pref+"_in", w2)
+			if dw, err := media.DumpTo(*d, encStream(pref+"_in"), w2); err == nil {
+				w2 = dw
+			}
 		}()
 	}
-	return &Decoder{w: w, f: f, d: g722.NewDecoder(g722.Rate64000, f)}
+	return &Decoder{w: w, f: f, aux: conf.auxBits(), d: g722.NewDecoder(conf.bitrate, f)}
 }
 
 type Encoder struct {
 	f   g722.Flags
 	e   *g722.Encoder
+	aux int
 	buf Sample
 	w   Writer
 }
@@ -157,10 +255,26 @@ func (e *Encoder) WriteSample(in media.PCM16Sample) error {
 		e.buf = make(Sample, sz)
 	}
 	n := e.e.Encode(e.buf, in)
+	if e.aux != 0 {
+		// Encoder returns codes without auxiliary bits, which must be set to zero.
+		for i := range e.buf[:n] {
+			e.buf[i] <<= e.aux
+		}
+	}
 	return e.w.WriteSample(e.buf[:n])
 }
 
-func Encode(w Writer) (w2 media.PCM16Writer) {
+// Encode creates a 64 kbit/s G.722 encoder that writes to w.
+func Encode(w Writer) media.PCM16Writer {
+	return NewEncoder(w)
+}
+
+// NewEncoder creates a G.722 encoder that writes to w. It panics if options are invalid.
+func NewEncoder(w Writer, opts ...Option) (w2 media.PCM16Writer) {
+	conf, err := newConfig(opts)
+	if err != nil {
+		panic(err)
+	}
 	var f g722.Flags
 	switch w.SampleRate() {
 	default:
@@ -170,13 +284,17 @@ func Encode(w Writer) (w2 media.PCM16Writer) {
 	case 16000:
 		// default
 	}
-	if dumpToFile {
+	if d := dumper.Load(); d != nil {
 		id := g722ID.Add(1)
 		pref := fmt.Sprintf("sip_g722_enc_%d", id)
-		w = media.DumpWriter[Sample]("g722", pref+"_out", w)
+		if dw, err := media.DumpTo(*d, encStream(pref+"_out"), w); err == nil {
+			w = dw
+		}
 		defer func() {
-			w2 = media.DumpWriterPCM16(pref+"_in", w2)
+			if dw, err := media.DumpTo(*d, media.DumpStream{Name: pref + "_in"}, w2); err == nil {
+				w2 = dw
+			}
 		}()
 	}
-	return &Encoder{w: w, f: f, e: g722.NewEncoder(g722.Rate64000, f)}
+	return &Encoder{w: w, f: f, aux: conf.auxBits(), e: g722.NewEncoder(conf.bitrate, f)}
 }
@@ -0,0 +1,102 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package g722
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/media-sdk"
+)
+
+type sampleWriter struct {
+	samples []Sample
+}
+
+func (w *sampleWriter) String() string  { return "test" }
+func (w *sampleWriter) SampleRate() int { return 16000 }
+func (w *sampleWriter) Close() error    { return nil }
+
+func (w *sampleWriter) WriteSample(s Sample) error {
+	w.samples = append(w.samples, append(Sample{}, s...))
+	return nil
+}
+
+func sine(n int) media.PCM16Sample {
+	out := make(media.PCM16Sample, n)
+	for i := range out {
+		out[i] = int16(8000 * math.Sin(2*math.Pi*500*float64(i)/16000))
+	}
+	return out
+}
+
+// snr returns signal to noise ratio in dB, skipping the initial codec delay.
+func snr(exp, got media.PCM16Sample) float64 {
+	const skip = 1000
+	best := 0.0
+	// Find the codec delay first.
+	for delay := 0; delay < 64; delay++ {
+		var sig, noise float64
+		for i := skip; i < len(exp)-delay; i++ {
+			d := float64(exp[i]) - float64(got[i+delay])
+			sig += float64(exp[i]) * float64(exp[i])
+			noise += d * d
+		}
+		if v := 10 * math.Log10(sig/noise); v > best {
+			best = v
+		}
+	}
+	return best
+}
+
+func TestBitrates(t *testing.T) {
+	src := sine(16000)
+	for _, rate := range []int{Bitrate64000, Bitrate56000, Bitrate48000} {
+		t.Run(strconv.Itoa(rate), func(t *testing.T) {
+			var enc sampleWriter
+			w := NewEncoder(&enc, WithBitrate(rate))
+			for i := 0; i < len(src); i += 320 {
+				require.NoError(t, w.WriteSample(src[i:i+320]))
+			}
+			require.NoError(t, w.Close())
+
+			aux := byte(1)<<((Bitrate64000-rate)/8000) - 1
+			for _, s := range enc.samples {
+				require.Len(t, s, 160)
+				for _, b := range s {
+					require.Zero(t, b&aux)
+				}
+			}
+
+			// Payload must be decodable by a 64 kbit/s decoder, and by a decoder with the same bitrate.
+			for _, opts := range [][]Option{nil, {WithBitrate(rate)}} {
+				var got media.PCM16Sample
+				d := NewDecoder(media.NewPCM16BufferWriter(&got, 16000), opts...)
+				for _, s := range enc.samples {
+					require.NoError(t, d.WriteSample(s))
+				}
+				require.NoError(t, d.Close())
+				require.Len(t, got, len(src))
+				require.Greater(t, snr(src, got), 15.0)
+			}
+		})
+	}
+
+	_, err := NewCodec(WithBitrate(32000))
+	require.Error(t, err)
+}
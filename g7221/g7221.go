@@ -0,0 +1,316 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package g7221 implements RTP payload format and SDP negotiation for G.722.1 (Siren7)
+// and G.722.1 Annex C (Siren14), as defined in RFC 5577.
+//
+// No codec implementation is registered by default. The "g7221" build tag enables one based on libg722_1.
+package g7221
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/livekit/media-sdk"
+	"github.com/livekit/media-sdk/rtp"
+)
+
+const (
+	// SDPName of G.722.1 (Siren7) with 7 kHz audio bandwidth.
+	SDPName = "G7221/16000"
+	// SDPNameC of G.722.1 Annex C (Siren14) with 14 kHz audio bandwidth.
+	SDPNameC = "G7221/32000"
+)
+
+var ErrInvalidPayload = errors.New("invalid G.722.1 payload size")
+
+// Bitrates returns bitrates supported for a given sample rate.
+func Bitrates(sampleRate int) []int {
+	switch sampleRate {
+	case 16000:
+		return []int{24000, 32000}
+	case 32000:
+		return []int{24000, 32000, 48000}
+	}
+	return nil
+}
+
+// FrameSize returns the size of a single 20ms frame in bytes for a given bitrate.
+func FrameSize(bitrate int) int {
+	return bitrate / rtp.DefFramesPerSec / 8
+}
+
+// FrameEncoder encodes 20ms frames of PCM audio.
+type FrameEncoder interface {
+	// Encode a frame of PCM samples to dst, which has exactly FrameSize bytes.
+	Encode(dst []byte, src media.PCM16Sample)
+	Close()
+}
+
+// FrameDecoder decodes 20ms frames.
+type FrameDecoder interface {
+	// Decode a frame to dst. Nil frame indicates a lost frame, which must be concealed by the decoder.
+	Decode(dst media.PCM16Sample, frame []byte)
+	Close()
+}
+
+// Implementation of G.722.1 codec.
+type Implementation interface {
+	NewEncoder(sampleRate, bitrate int) FrameEncoder
+	NewDecoder(sampleRate, bitrate int) FrameDecoder
+}
+
+// Config of G.722.1 codec.
+type Config struct {
+	// SampleRate is 16000 for G.722.1 and 32000 for G.722.1 Annex C.
+	SampleRate int
+	// Bitrate offered to the remote side. Defaults to 24000.
+	Bitrate int
+}
+
+var (
+	registerMu sync.Mutex
+	registered = make(map[int]bool)
+)
+
+// Register G.722.1 codec with a given implementation. It can only be called once for each sample rate.
+func Register(impl Implementation, conf Config) {
+	registerMu.Lock()
+	defer registerMu.Unlock()
+	c, err := NewCodec(impl, conf)
+	if err != nil {
+		panic(err)
+	}
+	if registered[conf.SampleRate] {
+		panic("g7221: codec is already registered")
+	}
+	registered[conf.SampleRate] = true
+	media.RegisterCodec(c)
+}
+
+// NewCodec creates G.722.1 RTP codec.
+func NewCodec(impl Implementation, conf Config) (*Codec, error) {
+	if conf.Bitrate == 0 {
+		conf.Bitrate = 24000
+	}
+	info := media.CodecInfo{SampleRate: conf.SampleRate, FileExt: "g7221"}
+	switch conf.SampleRate {
+	case 16000:
+		info.SDPName = SDPName
+		info.Priority = -4
+	case 32000:
+		info.SDPName = SDPNameC
+		info.Priority = -3
+	default:
+		return nil, fmt.Errorf("unsupported G.722.1 sample rate: %d", conf.SampleRate)
+	}
+	if !slices.Contains(Bitrates(conf.SampleRate), conf.Bitrate) {
+		return nil, fmt.Errorf("unsupported G.722.1 bitrate: %d", conf.Bitrate)
+	}
+	c := &Codec{impl: impl, conf: conf}
+	c.codec = rtp.NewAudioCodec(info, c.Decode, c.Encode).(audioCodec)
+	return c, nil
+}
+
+type audioCodec interface {
+	rtp.AudioCodec
+	rtp.AudioCodecDumper
+}
+
+// Codec is G.722.1 RTP codec, negotiated with bitrate format parameter.
+type Codec struct {
+	codec audioCodec
+	impl  Implementation
+	conf  Config
+}
+
+var (
+	_ rtp.FMTPCodec        = (*Codec)(nil)
+	_ rtp.AudioCodecDumper = (*Codec)(nil)
+)
+
+func (c *Codec) Info() media.CodecInfo {
+	return c.codec.Info()
+}
+
+// Bitrate returns codec bitrate.
+func (c *Codec) Bitrate() int {
+	return c.conf.Bitrate
+}
+
+func (c *Codec) FMTP() string {
+	return "bitrate=" + strconv.Itoa(c.conf.Bitrate)
+}
+
+// WithFMTP returns a codec with a bitrate selected by the remote side. The parameter is mandatory (RFC 5577),
+// but the offered bitrate is used if it's missing.
+func (c *Codec) WithFMTP(fmtp string) (rtp.AudioCodec, error) {
+	v, ok := rtp.ParseFMTP(fmtp)["bitrate"]
+	if !ok {
+		return c, nil
+	}
+	bitrate, err := strconv.Atoi(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bitrate: %q", v)
+	}
+	if bitrate == c.conf.Bitrate {
+		return c, nil
+	}
+	conf := c.conf
+	conf.Bitrate = bitrate
+	return NewCodec(c.impl, conf)
+}
+
+func (c *Codec) EncodeRTP(w *rtp.Stream) media.PCM16Writer {
+	return c.codec.EncodeRTP(w)
+}
+
+func (c *Codec) DecodeRTP(w media.Writer[media.PCM16Sample], typ byte) rtp.Handler {
+	return c.codec.DecodeRTP(w, typ)
+}
+
+func (c *Codec) EncodeRTPWithDump(w *rtp.Stream, d media.Dumper) (media.PCM16Writer, error) {
+	return c.codec.EncodeRTPWithDump(w, d)
+}
+
+func (c *Codec) DecodeRTPWithDump(w media.Writer[media.PCM16Sample], typ byte, d media.Dumper) (rtp.Handler, error) {
+	return c.codec.DecodeRTPWithDump(w, typ, d)
+}
+
+// Decode creates a decoder that writes PCM to w.
+func (c *Codec) Decode(w media.PCM16Writer) Writer {
+	if w.SampleRate() != c.conf.SampleRate {
+		w = media.ResampleWriter(w, c.conf.SampleRate)
+	}
+	return &Decoder{
+		w:       w,
+		d:       c.impl.NewDecoder(c.conf.SampleRate, c.conf.Bitrate),
+		frame:   FrameSize(c.conf.Bitrate),
+		samples: c.conf.SampleRate / rtp.DefFramesPerSec,
+	}
+}
+
+// Encode creates an encoder that writes RTP payloads to w.
+func (c *Codec) Encode(w Writer) media.PCM16Writer {
+	if w.SampleRate() != c.conf.SampleRate {
+		panic("unsupported sample rate")
+	}
+	return &Encoder{
+		w:       w,
+		e:       c.impl.NewEncoder(c.conf.SampleRate, c.conf.Bitrate),
+		frame:   FrameSize(c.conf.Bitrate),
+		samples: c.conf.SampleRate / rtp.DefFramesPerSec,
+	}
+}
+
+// Sample is an RTP payload with one or more G.722.1 frames.
+type Sample []byte
+
+func (s Sample) Size() int {
+	return len(s)
+}
+
+func (s Sample) CopyTo(dst []byte) (int, error) {
+	if len(dst) < len(s) {
+		return 0, io.ErrShortBuffer
+	}
+	n := copy(dst, s)
+	return n, nil
+}
+
+type Writer = media.WriteCloser[Sample]
+
+type Decoder struct {
+	w       media.PCM16Writer
+	d       FrameDecoder
+	frame   int
+	samples int
+	buf     media.PCM16Sample
+}
+
+func (d *Decoder) String() string {
+	return fmt.Sprintf("G7221(decode) -> %s", d.w)
+}
+
+func (d *Decoder) SampleRate() int {
+	return d.w.SampleRate()
+}
+
+func (d *Decoder) Close() error {
+	d.d.Close()
+	return d.w.Close()
+}
+
+func (d *Decoder) WriteSample(in Sample) error {
+	if len(in) == 0 || len(in)%d.frame != 0 {
+		return ErrInvalidPayload
+	}
+	n := len(in) / d.frame
+	sz := n * d.samples
+	if cap(d.buf) < sz {
+		d.buf = make(media.PCM16Sample, sz)
+	} else {
+		d.buf = d.buf[:sz]
+	}
+	for i := 0; i < n; i++ {
+		d.d.Decode(d.buf[i*d.samples:(i+1)*d.samples], in[i*d.frame:(i+1)*d.frame])
+	}
+	return d.w.WriteSample(d.buf)
+}
+
+type Encoder struct {
+	w       Writer
+	e       FrameEncoder
+	frame   int
+	samples int
+	in      media.PCM16Sample
+	out     Sample
+}
+
+func (e *Encoder) String() string {
+	return fmt.Sprintf("G7221(encode) -> %s", e.w)
+}
+
+func (e *Encoder) SampleRate() int {
+	return e.w.SampleRate()
+}
+
+func (e *Encoder) Close() error {
+	e.e.Close()
+	return e.w.Close()
+}
+
+// WriteSample encodes all complete 20ms frames and sends them as a single payload. Remaining samples are buffered.
+func (e *Encoder) WriteSample(in media.PCM16Sample) error {
+	e.in = append(e.in, in...)
+	n := len(e.in) / e.samples
+	if n == 0 {
+		return nil
+	}
+	sz := n * e.frame
+	if cap(e.out) < sz {
+		e.out = make(Sample, sz)
+	} else {
+		e.out = e.out[:sz]
+	}
+	for i := 0; i < n; i++ {
+		e.e.Encode(e.out[i*e.frame:(i+1)*e.frame], e.in[i*e.samples:(i+1)*e.samples])
+	}
+	e.in = e.in[:copy(e.in, e.in[n*e.samples:])]
+	return e.w.WriteSample(e.out)
+}
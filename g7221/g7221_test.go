@@ -0,0 +1,201 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package g7221
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/media-sdk"
+	"github.com/livekit/media-sdk/sdp"
+)
+
+// testImpl "encodes" a frame by storing its first sample in the first byte.
+type testImpl struct{}
+
+func (testImpl) NewEncoder(sampleRate, bitrate int) FrameEncoder { return testEncoder{} }
+func (testImpl) NewDecoder(sampleRate, bitrate int) FrameDecoder { return testDecoder{} }
+
+type testEncoder struct{}
+
+func (testEncoder) Encode(dst []byte, src media.PCM16Sample) {
+	clear(dst)
+	dst[0] = byte(src[0])
+}
+
+func (testEncoder) Close() {}
+
+type testDecoder struct{}
+
+func (testDecoder) Decode(dst media.PCM16Sample, frame []byte) {
+	v := int16(-1)
+	if frame != nil {
+		v = int16(frame[0])
+	}
+	for i := range dst {
+		dst[i] = v
+	}
+}
+
+func (testDecoder) Close() {}
+
+func frames(samples int, vals ...int16) media.PCM16Sample {
+	var out media.PCM16Sample
+	for _, v := range vals {
+		for range samples {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func payload(size int, vals ...byte) Sample {
+	out := make(Sample, size*len(vals))
+	for i, v := range vals {
+		out[i*size] = v
+	}
+	return out
+}
+
+type sampleWriter struct {
+	rate    int
+	samples []Sample
+}
+
+func (w *sampleWriter) String() string  { return "test" }
+func (w *sampleWriter) SampleRate() int { return w.rate }
+func (w *sampleWriter) Close() error    { return nil }
+
+func (w *sampleWriter) WriteSample(s Sample) error {
+	w.samples = append(w.samples, append(Sample{}, s...))
+	return nil
+}
+
+func TestEncode(t *testing.T) {
+	for _, c := range []struct {
+		conf    Config
+		samples int
+		size    int
+	}{
+		{Config{SampleRate: 16000}, 320, 60},
+		{Config{SampleRate: 16000, Bitrate: 32000}, 320, 80},
+		{Config{SampleRate: 32000, Bitrate: 48000}, 640, 120},
+	} {
+		c2, err := NewCodec(testImpl{}, c.conf)
+		require.NoError(t, err)
+		got := &sampleWriter{rate: c.conf.SampleRate}
+		w := c2.Encode(got)
+		require.NoError(t, w.WriteSample(frames(c.samples, 1, 2)))
+		require.NoError(t, w.WriteSample(frames(c.samples, 3)[:c.samples/2]))
+		require.NoError(t, w.WriteSample(frames(c.samples, 4)))
+		require.NoError(t, w.Close())
+		require.Equal(t, []Sample{
+			payload(c.size, 1, 2),
+			payload(c.size, 3), // the rest of the samples are buffered
+		}, got.samples)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	c, err := NewCodec(testImpl{}, Config{SampleRate: 16000})
+	require.NoError(t, err)
+	var got []media.PCM16Sample
+	w := c.Decode(media.NewPCM16FrameWriter(&got, 16000))
+	require.NoError(t, w.WriteSample(payload(60, 1, 2)))
+	require.NoError(t, w.WriteSample(payload(60, 3)))
+	require.ErrorIs(t, w.WriteSample(Sample{}), ErrInvalidPayload)
+	require.ErrorIs(t, w.WriteSample(make(Sample, 80)), ErrInvalidPayload)
+	require.NoError(t, w.Close())
+	require.Equal(t, []media.PCM16Sample{
+		frames(320, 1, 2),
+		frames(320, 3),
+	}, got)
+}
+
+func TestFMTP(t *testing.T) {
+	_, err := NewCodec(testImpl{}, Config{SampleRate: 8000})
+	require.Error(t, err)
+	_, err = NewCodec(testImpl{}, Config{SampleRate: 16000, Bitrate: 48000})
+	require.Error(t, err)
+
+	c, err := NewCodec(testImpl{}, Config{SampleRate: 16000})
+	require.NoError(t, err)
+	require.Equal(t, "bitrate=24000", c.FMTP())
+
+	for _, tc := range []struct {
+		fmtp    string
+		bitrate int
+	}{
+		{"", 24000},
+		{"bitrate=24000", 24000},
+		{"bitrate=32000", 32000},
+		{"Bitrate=32000; foo=bar", 32000},
+	} {
+		c2, err := c.WithFMTP(tc.fmtp)
+		require.NoError(t, err)
+		require.Equal(t, tc.bitrate, c2.(*Codec).Bitrate(), tc.fmtp)
+	}
+	_, err = c.WithFMTP("bitrate=48000")
+	require.Error(t, err)
+	_, err = c.WithFMTP("bitrate=fast")
+	require.Error(t, err)
+
+	c, err = NewCodec(testImpl{}, Config{SampleRate: 32000})
+	require.NoError(t, err)
+	c2, err := c.WithFMTP("bitrate=48000")
+	require.NoError(t, err)
+	require.Equal(t, 48000, c2.(*Codec).Bitrate())
+}
+
+func TestSDP(t *testing.T) {
+	c, err := NewCodec(testImpl{}, Config{SampleRate: 16000, Bitrate: 32000})
+	require.NoError(t, err)
+	// Builds with cgo register codecs based on libg722_1, use a separate registry for the test codec.
+	reg := media.NewRegistry()
+	reg.RegisterCodec(c)
+	opt := sdp.WithRegistry(reg)
+
+	const offerSDP = "v=0\r\n" +
+		"o=- 1 1 IN IP4 10.0.0.1\r\n" +
+		"s=-\r\n" +
+		"c=IN IP4 10.0.0.1\r\n" +
+		"t=0 0\r\n" +
+		"m=audio 10000 RTP/AVP 102 0 101\r\n" +
+		"a=rtpmap:102 G7221/16000\r\n" +
+		"a=fmtp:102 bitrate=24000\r\n" +
+		"a=rtpmap:0 PCMU/8000\r\n" +
+		"a=rtpmap:101 telephone-event/8000\r\n" +
+		"a=fmtp:101 0-16\r\n"
+	offer, err := sdp.ParseOffer([]byte(offerSDP), opt)
+	require.NoError(t, err)
+	answer, conf, err := offer.Answer(netip.MustParseAddr("10.0.0.2"), 20000, sdp.EncryptionNone, opt)
+	require.NoError(t, err)
+	require.Equal(t, SDPName, conf.Audio.Codec.Info().SDPName)
+	require.EqualValues(t, 102, conf.Audio.Type)
+	require.Equal(t, 24000, conf.Audio.Codec.(*Codec).Bitrate())
+
+	data, err := answer.SDP.Marshal()
+	require.NoError(t, err)
+	require.Contains(t, string(data), "a=rtpmap:102 G7221/16000\r\na=fmtp:102 bitrate=24000\r\n")
+
+	offer2, err := sdp.NewOffer(netip.MustParseAddr("10.0.0.2"), 20000, sdp.EncryptionNone, opt)
+	require.NoError(t, err)
+	data, err = offer2.SDP.Marshal()
+	require.NoError(t, err)
+	require.Contains(t, string(data), " G7221/16000\r\na=fmtp:")
+	require.Contains(t, string(data), " bitrate=32000\r\n")
+}
@@ -0,0 +1,88 @@
+//go:build cgo && g7221
+
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package g7221
+
+/*
+#cgo LDFLAGS: -lg722_1
+#include <stdint.h>
+#include <g722_1.h>
+*/
+import "C"
+
+import (
+	"unsafe"
+
+	"github.com/livekit/media-sdk"
+)
+
+func init() {
+	Register(libg7221{}, Config{SampleRate: 16000})
+	Register(libg7221{}, Config{SampleRate: 32000})
+}
+
+// libg7221 implements G.722.1 and G.722.1 Annex C using libg722_1.
+type libg7221 struct{}
+
+func (libg7221) NewEncoder(sampleRate, bitrate int) FrameEncoder {
+	return &encoder{st: C.g722_1_encode_init(nil, C.int(bitrate), C.int(sampleRate))}
+}
+
+func (libg7221) NewDecoder(sampleRate, bitrate int) FrameDecoder {
+	return &decoder{st: C.g722_1_decode_init(nil, C.int(bitrate), C.int(sampleRate)), size: FrameSize(bitrate)}
+}
+
+type encoder struct {
+	st *C.g722_1_encode_state_t
+}
+
+func (e *encoder) Encode(dst []byte, src media.PCM16Sample) {
+	C.g722_1_encode(e.st, (*C.uint8_t)(unsafe.Pointer(&dst[0])), (*C.int16_t)(unsafe.Pointer(&src[0])), C.int(len(src)))
+}
+
+func (e *encoder) Close() {
+	if e.st != nil {
+		C.g722_1_encode_release(e.st)
+		e.st = nil
+	}
+}
+
+type decoder struct {
+	st   *C.g722_1_decode_state_t
+	size int
+	last []byte
+}
+
+func (d *decoder) Decode(dst media.PCM16Sample, frame []byte) {
+	if frame == nil {
+		// Conceal the loss based on the last received frame.
+		if d.last == nil {
+			clear(dst)
+			return
+		}
+		C.g722_1_fillin(d.st, (*C.int16_t)(unsafe.Pointer(&dst[0])), (*C.uint8_t)(unsafe.Pointer(&d.last[0])), C.int(len(d.last)))
+		return
+	}
+	d.last = append(d.last[:0], frame...)
+	C.g722_1_decode(d.st, (*C.int16_t)(unsafe.Pointer(&dst[0])), (*C.uint8_t)(unsafe.Pointer(&frame[0])), C.int(len(frame)))
+}
+
+func (d *decoder) Close() {
+	if d.st != nil {
+		C.g722_1_decode_release(d.st)
+		d.st = nil
+	}
+}
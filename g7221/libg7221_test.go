@@ -0,0 +1,96 @@
+//go:build cgo && g7221
+
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package g7221
+
+import (
+	"math"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/media-sdk"
+	"github.com/livekit/media-sdk/rtp"
+	"github.com/livekit/media-sdk/sdp"
+)
+
+func rms(s media.PCM16Sample) float64 {
+	var sum float64
+	for _, v := range s {
+		sum += float64(v) * float64(v)
+	}
+	return math.Sqrt(sum / float64(len(s)))
+}
+
+// TestLibG7221 negotiates G.722.1 and G.722.1C with codecs registered by default, and sends a tone through them.
+func TestLibG7221(t *testing.T) {
+	for _, name := range []string{SDPName, SDPNameC} {
+		t.Run(name, func(t *testing.T) {
+			// Remote side only supports a single codec.
+			reg := media.DefaultRegistry().Clone()
+			for _, c := range reg.Codecs() {
+				if _, ok := c.(rtp.AudioCodec); ok && c.Info().SDPName != name {
+					reg.SetEnabled(c.Info().SDPName, false)
+				}
+			}
+			offer, err := sdp.NewOffer(netip.MustParseAddr("10.0.0.1"), 10000, sdp.EncryptionNone)
+			require.NoError(t, err)
+			data, err := offer.SDP.Marshal()
+			require.NoError(t, err)
+			roffer, err := sdp.ParseOffer(data, sdp.WithRegistry(reg))
+			require.NoError(t, err)
+			answer, rconf, err := roffer.Answer(netip.MustParseAddr("10.0.0.2"), 20000, sdp.EncryptionNone, sdp.WithRegistry(reg))
+			require.NoError(t, err)
+			require.Equal(t, name, rconf.Audio.Codec.Info().SDPName)
+
+			data, err = answer.SDP.Marshal()
+			require.NoError(t, err)
+			panswer, err := sdp.ParseAnswer(data)
+			require.NoError(t, err)
+			conf, err := panswer.Apply(offer, sdp.EncryptionNone)
+			require.NoError(t, err)
+			require.Equal(t, name, conf.Audio.Codec.Info().SDPName)
+
+			// Send one second of 440 Hz tone.
+			rate := conf.Audio.Codec.Info().SampleRate
+			var buf rtp.Buffer
+			enc := conf.Audio.Codec.EncodeRTP(rtp.NewSeqWriter(&buf).NewStream(conf.Audio.Type, rate))
+			frame := make(media.PCM16Sample, rate/50)
+			var sent media.PCM16Sample
+			for i := range 50 {
+				for j := range frame {
+					frame[j] = int16(8000 * math.Sin(2*math.Pi*440*float64(i*len(frame)+j)/float64(rate)))
+				}
+				sent = append(sent, frame...)
+				require.NoError(t, enc.WriteSample(frame))
+			}
+			require.NoError(t, enc.Close())
+			require.Len(t, buf, 50)
+
+			var got media.PCM16Sample
+			dec := rconf.Audio.Codec.DecodeRTP(media.NewPCM16BufferWriter(&got, rate), rconf.Audio.Type)
+			for _, p := range buf {
+				require.EqualValues(t, rconf.Audio.Type, p.PayloadType)
+				require.NoError(t, dec.HandleRTP(&p.Header, p.Payload))
+			}
+			require.Len(t, got, len(sent))
+			// Skip the codec delay and compare signal levels.
+			ratio := rms(got[len(got)/2:]) / rms(sent[len(sent)/2:])
+			require.InDelta(t, 1, ratio, 0.3)
+		})
+	}
+}
@@ -0,0 +1,95 @@
+//go:build cgo && bcg729
+
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package g729
+
+/*
+#cgo LDFLAGS: -lbcg729
+#include <stdint.h>
+#include <bcg729/encoder.h>
+#include <bcg729/decoder.h>
+*/
+import "C"
+
+import (
+	"unsafe"
+
+	"github.com/livekit/media-sdk"
+)
+
+// BCG729 implements G.729A encoder and G.729 decoder with Annex B using libbcg729.
+//
+// It is registered with Annex B enabled. To disable it, use NewCodec(BCG729, false) in a custom registry.
+var BCG729 Implementation = bcg729{}
+
+func init() {
+	Register(BCG729, true)
+}
+
+type bcg729 struct{}
+
+func (bcg729) NewEncoder(vad bool) FrameEncoder {
+	var enableVAD C.uint8_t
+	if vad {
+		enableVAD = 1
+	}
+	return &bcgEncoder{ctx: C.initBcg729EncoderChannel(enableVAD)}
+}
+
+func (bcg729) NewDecoder() FrameDecoder {
+	return &bcgDecoder{ctx: C.initBcg729DecoderChannel()}
+}
+
+type bcgEncoder struct {
+	ctx *C.bcg729EncoderChannelContextStruct
+}
+
+func (e *bcgEncoder) Encode(dst []byte, src media.PCM16Sample) int {
+	var n C.uint8_t
+	C.bcg729Encoder(e.ctx, (*C.int16_t)(unsafe.Pointer(&src[0])), (*C.uint8_t)(unsafe.Pointer(&dst[0])), &n)
+	return int(n)
+}
+
+func (e *bcgEncoder) Close() {
+	if e.ctx != nil {
+		C.closeBcg729EncoderChannel(e.ctx)
+		e.ctx = nil
+	}
+}
+
+type bcgDecoder struct {
+	ctx   *C.bcg729DecoderChannelContextStruct
+	empty [FrameSize]byte
+}
+
+func (d *bcgDecoder) Decode(dst media.PCM16Sample, frame []byte) {
+	var erasure, sid C.uint8_t
+	switch len(frame) {
+	case 0:
+		erasure = 1
+		frame = d.empty[:]
+	case SIDSize:
+		sid = 1
+	}
+	C.bcg729Decoder(d.ctx, (*C.uint8_t)(unsafe.Pointer(&frame[0])), C.uint8_t(len(frame)), erasure, sid, 0, (*C.int16_t)(unsafe.Pointer(&dst[0])))
+}
+
+func (d *bcgDecoder) Close() {
+	if d.ctx != nil {
+		C.closeBcg729DecoderChannel(d.ctx)
+		d.ctx = nil
+	}
+}
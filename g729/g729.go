@@ -0,0 +1,331 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package g729 implements RTP payload format and SDP negotiation for G.729 and G.729A (RFC 3551, RFC 4856).
+//
+// The package does not include a speech codec. Default builds do not register G.729, and offers with
+// no other common codec still fail with sdp.ErrNoCommonMedia. Building with cgo and "bcg729" tag registers
+// an implementation based on libbcg729, otherwise an implementation must be provided with Register.
+package g729
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+
+	prtp "github.com/pion/rtp"
+
+	"github.com/livekit/media-sdk"
+	"github.com/livekit/media-sdk/rtp"
+)
+
+const SDPName = "G729/8000"
+
+const (
+	// SampleRate of G.729 audio.
+	SampleRate = 8000
+	// FrameSamples is the number of PCM samples in a single 10ms G.729 frame.
+	FrameSamples = 80
+	// FrameSize is the size of encoded speech frame.
+	FrameSize = 10
+	// SIDSize is the size of encoded Annex B SID (silence insertion descriptor) frame.
+	SIDSize = 2
+)
+
+var ErrInvalidPayload = errors.New("invalid G.729 payload size")
+
+// FrameEncoder encodes individual G.729 frames.
+type FrameEncoder interface {
+	// Encode a frame of FrameSamples PCM samples to dst, which has at least FrameSize bytes.
+	// It returns FrameSize for speech frames, SIDSize for SID frames or 0 if nothing needs to be sent.
+	// The latter two are only used when the encoder was created with VAD enabled.
+	Encode(dst []byte, src media.PCM16Sample) int
+	Close()
+}
+
+// FrameDecoder decodes individual G.729 frames.
+type FrameDecoder interface {
+	// Decode a single frame to dst, which has at least FrameSamples samples.
+	// The frame is either a speech frame (FrameSize), SID frame (SIDSize), or an empty frame for a lost packet.
+	Decode(dst media.PCM16Sample, frame []byte)
+	Close()
+}
+
+// Implementation of the G.729 speech codec.
+type Implementation interface {
+	// NewEncoder creates a new encoder. If vad is set, the encoder uses Annex B voice activity detection
+	// and discontinuous transmission.
+	NewEncoder(vad bool) FrameEncoder
+	// NewDecoder creates a new decoder. Decoder must support Annex B SID frames.
+	NewDecoder() FrameDecoder
+}
+
+var (
+	registerMu sync.Mutex
+	registered bool
+)
+
+// Register the G.729 codec with a given implementation. It can only be called once.
+// The annexB flag controls whether Annex B (VAD, DTX and CNG) is offered and accepted, see NewCodec.
+func Register(impl Implementation, annexB bool) {
+	registerMu.Lock()
+	defer registerMu.Unlock()
+	if registered {
+		panic("g729: codec is already registered")
+	}
+	registered = true
+	media.RegisterCodec(NewCodec(impl, annexB))
+}
+
+// NewCodec creates a G.729 RTP codec. The annexB flag controls whether this side supports Annex B (VAD, DTX and CNG).
+func NewCodec(impl Implementation, annexB bool) *Codec {
+	c := &Codec{impl: impl, annexB: annexB}
+	c.codec = rtp.NewAudioCodec(media.CodecInfo{
+		SDPName:     SDPName,
+		SampleRate:  SampleRate,
+		RTPDefType:  prtp.PayloadTypeG729,
+		RTPIsStatic: true,
+		Priority:    -20,
+		FileExt:     "g729",
+	}, c.Decode, c.Encode).(audioCodec)
+	return c
+}
+
+type audioCodec interface {
+	rtp.AudioCodec
+	rtp.AudioCodecDumper
+}
+
+// Codec is a G.729 RTP codec, negotiated with annexb format parameter.
+type Codec struct {
+	codec  audioCodec
+	impl   Implementation
+	annexB bool
+}
+
+var (
+	_ rtp.FMTPCodec        = (*Codec)(nil)
+	_ rtp.AudioCodecDumper = (*Codec)(nil)
+)
+
+func (c *Codec) Info() media.CodecInfo {
+	return c.codec.Info()
+}
+
+// AnnexB reports if Annex B (VAD, DTX and CNG) is enabled for this codec.
+func (c *Codec) AnnexB() bool {
+	return c.annexB
+}
+
+func (c *Codec) FMTP() string {
+	if c.annexB {
+		return "annexb=yes"
+	}
+	return "annexb=no"
+}
+
+// WithFMTP returns a codec configured for annexb parameter of the remote side.
+// Annex B is enabled only if both sides support it. Missing parameter means Annex B is supported (RFC 4856).
+func (c *Codec) WithFMTP(fmtp string) (rtp.AudioCodec, error) {
+	annexB := true
+	if v, ok := rtp.ParseFMTP(fmtp)["annexb"]; ok {
+		switch strings.ToLower(v) {
+		case "yes":
+		case "no":
+			annexB = false
+		default:
+			return nil, fmt.Errorf("invalid annexb value: %q", v)
+		}
+	}
+	annexB = annexB && c.annexB
+	if annexB == c.annexB {
+		return c, nil
+	}
+	return NewCodec(c.impl, annexB), nil
+}
+
+func (c *Codec) EncodeRTP(w *rtp.Stream) media.PCM16Writer {
+	return c.codec.EncodeRTP(w)
+}
+
+func (c *Codec) DecodeRTP(w media.Writer[media.PCM16Sample], typ byte) rtp.Handler {
+	return c.codec.DecodeRTP(w, typ)
+}
+
+func (c *Codec) EncodeRTPWithDump(w *rtp.Stream, d media.Dumper) (media.PCM16Writer, error) {
+	return c.codec.EncodeRTPWithDump(w, d)
+}
+
+func (c *Codec) DecodeRTPWithDump(w media.Writer[media.PCM16Sample], typ byte, d media.Dumper) (rtp.Handler, error) {
+	return c.codec.DecodeRTPWithDump(w, typ, d)
+}
+
+// Decode creates a decoder that writes PCM to w.
+func (c *Codec) Decode(w media.PCM16Writer) Writer {
+	switch w.SampleRate() {
+	default:
+		w = media.ResampleWriter(w, SampleRate)
+	case SampleRate:
+	}
+	return &Decoder{w: w, d: c.impl.NewDecoder()}
+}
+
+// Encode creates an encoder that writes G.729 payloads to w. Annex B is used if it's enabled for the codec.
+func (c *Codec) Encode(w Writer) media.PCM16Writer {
+	switch w.SampleRate() {
+	default:
+		panic("unsupported sample rate")
+	case SampleRate:
+	}
+	return &Encoder{w: w, e: c.impl.NewEncoder(c.annexB)}
+}
+
+// Sample is an RTP payload that contains zero or more G.729 speech frames, optionally followed by a single SID frame.
+type Sample []byte
+
+func (s Sample) Size() int {
+	return len(s)
+}
+
+// DTX marks G.729 samples as frames with discontinuous transmission. See rtp.DTXFrame.
+func (Sample) DTX() {}
+
+func (s Sample) CopyTo(dst []byte) (int, error) {
+	if len(dst) < len(s) {
+		return 0, io.ErrShortBuffer
+	}
+	n := copy(dst, s)
+	return n, nil
+}
+
+// Frames splits the payload into speech frames and an optional SID frame.
+func (s Sample) Frames() (frames []Sample, sid Sample, err error) {
+	switch len(s) % FrameSize {
+	case 0:
+	case SIDSize:
+		s, sid = s[:len(s)-SIDSize], s[len(s)-SIDSize:]
+	default:
+		return nil, nil, ErrInvalidPayload
+	}
+	for len(s) > 0 {
+		frames = append(frames, s[:FrameSize])
+		s = s[FrameSize:]
+	}
+	return frames, sid, nil
+}
+
+type Writer = media.WriteCloser[Sample]
+
+type Decoder struct {
+	w   media.PCM16Writer
+	d   FrameDecoder
+	buf media.PCM16Sample
+}
+
+func (d *Decoder) String() string {
+	return fmt.Sprintf("G729(decode) -> %s", d.w)
+}
+
+func (d *Decoder) SampleRate() int {
+	return d.w.SampleRate()
+}
+
+func (d *Decoder) Close() error {
+	d.d.Close()
+	return d.w.Close()
+}
+
+func (d *Decoder) WriteSample(in Sample) error {
+	frames, sid, err := in.Frames()
+	if err != nil {
+		return err
+	}
+	if sid != nil {
+		frames = append(frames, sid)
+	}
+	if len(frames) == 0 {
+		return nil
+	}
+	sz := len(frames) * FrameSamples
+	if cap(d.buf) < sz {
+		d.buf = make(media.PCM16Sample, sz)
+	} else {
+		d.buf = d.buf[:sz]
+	}
+	for i, f := range frames {
+		d.d.Decode(d.buf[i*FrameSamples:(i+1)*FrameSamples], f)
+	}
+	return d.w.WriteSample(d.buf)
+}
+
+type Encoder struct {
+	w   Writer
+	e   FrameEncoder
+	in  media.PCM16Sample
+	out Sample
+	sid [SIDSize]byte
+}
+
+func (e *Encoder) String() string {
+	return fmt.Sprintf("G729(encode) -> %s", e.w)
+}
+
+func (e *Encoder) SampleRate() int {
+	return e.w.SampleRate()
+}
+
+func (e *Encoder) Close() error {
+	e.e.Close()
+	return e.w.Close()
+}
+
+// WriteSample encodes all complete frames and sends them as a single payload. Remaining samples are buffered.
+// When Annex B is enabled, the payload may only contain a SID frame or be empty, if no frames need to be sent.
+// Nothing is written if there are not enough samples for a frame.
+func (e *Encoder) WriteSample(in media.PCM16Sample) error {
+	e.in = append(e.in, in...)
+	e.out = e.out[:0]
+	hasSID := false
+	off := 0
+	for ; len(e.in)-off >= FrameSamples; off += FrameSamples {
+		frame := e.in[off : off+FrameSamples]
+		i := len(e.out)
+		e.out = slices.Grow(e.out, FrameSize)[:i+FrameSize]
+		n := e.e.Encode(e.out[i:], frame)
+		switch n {
+		case FrameSize:
+			// SID frame must be the last one in the payload, and it's not needed if speech follows.
+			hasSID = false
+		case SIDSize:
+			hasSID = true
+			copy(e.sid[:], e.out[i:i+SIDSize])
+			n = 0
+		case 0:
+		default:
+			return fmt.Errorf("g729: unexpected frame size: %d", n)
+		}
+		e.out = e.out[:i+n]
+	}
+	if hasSID {
+		e.out = append(e.out, e.sid[:SIDSize]...)
+	}
+	e.in = e.in[:copy(e.in, e.in[off:])]
+	if off == 0 {
+		return nil // not enough samples for a frame
+	}
+	return e.w.WriteSample(e.out)
+}
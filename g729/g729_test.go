@@ -0,0 +1,239 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package g729
+
+import (
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/media-sdk"
+	"github.com/livekit/media-sdk/rtp"
+	"github.com/livekit/media-sdk/sdp"
+)
+
+// testImpl "encodes" a frame by storing its first sample in the first byte.
+// Frames with zero samples are treated as silence: the first one produces SID, the rest are not sent.
+type testImpl struct{}
+
+func (testImpl) NewEncoder(vad bool) FrameEncoder { return &testEncoder{vad: vad} }
+func (testImpl) NewDecoder() FrameDecoder         { return testDecoder{} }
+
+type testEncoder struct {
+	vad    bool
+	silent bool
+}
+
+func (e *testEncoder) Encode(dst []byte, src media.PCM16Sample) int {
+	if e.vad && src[0] == 0 {
+		if e.silent {
+			return 0
+		}
+		e.silent = true
+		dst[0], dst[1] = 0xff, 0xff
+		return SIDSize
+	}
+	e.silent = false
+	clear(dst[:FrameSize])
+	dst[0] = byte(src[0])
+	return FrameSize
+}
+
+func (e *testEncoder) Close() {}
+
+type testDecoder struct{}
+
+func (testDecoder) Decode(dst media.PCM16Sample, frame []byte) {
+	v := int16(-1)
+	if len(frame) == FrameSize {
+		v = int16(frame[0])
+	}
+	for i := range dst {
+		dst[i] = v
+	}
+}
+
+func (testDecoder) Close() {}
+
+func frames(vals ...int16) media.PCM16Sample {
+	var out media.PCM16Sample
+	for _, v := range vals {
+		for range FrameSamples {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+type sampleWriter struct {
+	samples []Sample
+}
+
+func (w *sampleWriter) String() string  { return "test" }
+func (w *sampleWriter) SampleRate() int { return SampleRate }
+func (w *sampleWriter) Close() error    { return nil }
+
+func (w *sampleWriter) WriteSample(s Sample) error {
+	w.samples = append(w.samples, append(Sample{}, s...))
+	return nil
+}
+
+func TestEncode(t *testing.T) {
+	for _, c := range []struct {
+		name   string
+		annexB bool
+		in     []media.PCM16Sample
+		exp    []Sample
+	}{
+		{
+			name: "no vad",
+			in:   []media.PCM16Sample{frames(1, 2), frames(0, 0)},
+			exp: []Sample{
+				{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 2, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+				make(Sample, 2*FrameSize),
+			},
+		},
+		{
+			name: "partial",
+			in:   []media.PCM16Sample{frames(1)[:40], frames(1)[:60], frames(2)[:60]},
+			exp: []Sample{
+				{1, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+				{1, 0, 0, 0, 0, 0, 0, 0, 0, 0}, // 20 samples left from the previous write
+			},
+		},
+		{
+			name:   "dtx",
+			annexB: true,
+			in:     []media.PCM16Sample{frames(1, 0), frames(0, 0), frames(0, 3), frames(0, 0)},
+			exp: []Sample{
+				{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0xff, 0xff},
+				{},
+				{3, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+				{0xff, 0xff},
+			},
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			var got sampleWriter
+			w := NewCodec(testImpl{}, c.annexB).Encode(&got)
+			for _, in := range c.in {
+				require.NoError(t, w.WriteSample(in))
+			}
+			require.NoError(t, w.Close())
+			require.Equal(t, c.exp, got.samples)
+		})
+	}
+}
+
+func TestEncodeRTP(t *testing.T) {
+	var buf rtp.Buffer
+	s := rtp.NewSeqWriter(&buf).NewStream(18, SampleRate)
+	w := NewCodec(testImpl{}, true).EncodeRTP(s)
+	for _, in := range []media.PCM16Sample{frames(1, 0), frames(0, 0), frames(0, 0), frames(0, 3)} {
+		require.NoError(t, w.WriteSample(in))
+	}
+	// Silent packets are not sent, but the timestamp advances.
+	require.Len(t, buf, 2)
+	require.Equal(t, []byte{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0xff, 0xff}, buf[0].Payload)
+	require.Equal(t, []byte{3, 0, 0, 0, 0, 0, 0, 0, 0, 0}, buf[1].Payload)
+	require.Equal(t, buf[0].Timestamp+3*2*FrameSamples, buf[1].Timestamp)
+}
+
+func TestDecode(t *testing.T) {
+	var got []media.PCM16Sample
+	w := NewCodec(testImpl{}, false).Decode(media.NewPCM16FrameWriter(&got, SampleRate))
+	require.NoError(t, w.WriteSample(Sample{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 2, 0, 0, 0, 0, 0, 0, 0, 0, 0}))
+	require.NoError(t, w.WriteSample(Sample{3, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0xff, 0xff}))
+	require.NoError(t, w.WriteSample(Sample{0xff, 0xff}))
+	require.NoError(t, w.WriteSample(Sample{}))
+	require.ErrorIs(t, w.WriteSample(Sample{1, 2, 3}), ErrInvalidPayload)
+	require.NoError(t, w.Close())
+	require.Equal(t, []media.PCM16Sample{
+		frames(1, 2),
+		frames(3, -1),
+		frames(-1),
+	}, got)
+}
+
+func TestFMTP(t *testing.T) {
+	c := NewCodec(testImpl{}, true)
+	require.Equal(t, "annexb=yes", c.FMTP())
+
+	for _, tc := range []struct {
+		fmtp   string
+		annexB bool
+	}{
+		{"", true},
+		{"annexb=yes", true},
+		{"annexb=no", false},
+		{"annexb=NO; foo=bar", false},
+	} {
+		c2, err := c.WithFMTP(tc.fmtp)
+		require.NoError(t, err)
+		require.Equal(t, tc.annexB, c2.(*Codec).AnnexB(), tc.fmtp)
+	}
+	_, err := c.WithFMTP("annexb=maybe")
+	require.Error(t, err)
+
+	c = NewCodec(testImpl{}, false)
+	c2, err := c.WithFMTP("annexb=yes")
+	require.NoError(t, err)
+	require.False(t, c2.(*Codec).AnnexB())
+}
+
+func TestSDP(t *testing.T) {
+	reg := media.DefaultRegistry().Clone()
+	if reg.CodecByName(SDPName) == nil {
+		// Builds with libbcg729 already register the codec with Annex B enabled.
+		reg.RegisterCodec(NewCodec(testImpl{}, true))
+	}
+	reg.SetEnabled("PCMU/8000", false)
+	reg.SetEnabled("PCMA/8000", false)
+	opt := sdp.WithRegistry(reg)
+
+	const offerSDP = "v=0\r\n" +
+		"o=- 1 1 IN IP4 10.0.0.1\r\n" +
+		"s=-\r\n" +
+		"c=IN IP4 10.0.0.1\r\n" +
+		"t=0 0\r\n" +
+		"m=audio 10000 RTP/AVP 18 101\r\n" +
+		"a=rtpmap:18 G729/8000\r\n" +
+		"a=fmtp:18 annexb=no\r\n" +
+		"a=rtpmap:101 telephone-event/8000\r\n" +
+		"a=fmtp:101 0-16\r\n"
+	for _, annexB := range []string{"no", "yes"} {
+		t.Run("annexb="+annexB, func(t *testing.T) {
+			offer, err := sdp.ParseOffer([]byte(strings.Replace(offerSDP, "annexb=no", "annexb="+annexB, 1)), opt)
+			require.NoError(t, err)
+			answer, conf, err := offer.Answer(netip.MustParseAddr("10.0.0.2"), 20000, sdp.EncryptionNone, opt)
+			require.NoError(t, err)
+			require.Equal(t, SDPName, conf.Audio.Codec.Info().SDPName)
+			require.EqualValues(t, 18, conf.Audio.Type)
+			require.Equal(t, annexB == "yes", conf.Audio.Codec.(*Codec).AnnexB())
+
+			data, err := answer.SDP.Marshal()
+			require.NoError(t, err)
+			require.Contains(t, string(data), "a=rtpmap:18 G729/8000\r\na=fmtp:18 annexb="+annexB+"\r\n")
+		})
+	}
+
+	offer2, err := sdp.NewOffer(netip.MustParseAddr("10.0.0.2"), 20000, sdp.EncryptionNone, opt)
+	require.NoError(t, err)
+	data, err := offer2.SDP.Marshal()
+	require.NoError(t, err)
+	require.Contains(t, string(data), "a=fmtp:18 annexb=yes\r\n")
+}
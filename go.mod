@@ -12,6 +12,7 @@ require (
 	github.com/jfreymuth/oggvorbis v1.0.5
 	github.com/livekit/protocol v1.38.1-0.20250511053429-f8ea8179871e
 	github.com/pion/interceptor v0.1.37
+	github.com/pion/rtcp v1.2.15
 	github.com/pion/rtp v1.8.13
 	github.com/pion/sdp/v3 v3.0.11
 	github.com/pion/srtp/v3 v3.0.4
@@ -28,7 +29,6 @@ require (
 	github.com/kr/pretty v0.1.0 // indirect
 	github.com/pion/logging v0.2.3 // indirect
 	github.com/pion/randutil v0.1.0 // indirect
-	github.com/pion/rtcp v1.2.15 // indirect
 	github.com/pion/transport/v3 v3.0.7 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/puzpuzpuz/xsync/v3 v3.5.1 // indirect
@@ -0,0 +1,109 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package h264 implements H.264 RTP payload format (RFC 6184).
+//
+// Frames use Annex B byte stream format, with NAL units separated by start codes.
+// SDP negotiation is not included, FMTP can be used in video sections negotiated by the application.
+package h264
+
+import (
+	prtp "github.com/pion/rtp"
+	"github.com/pion/rtp/codecs"
+
+	"github.com/livekit/media-sdk"
+	"github.com/livekit/media-sdk/rtp"
+)
+
+const SDPName = "H264/90000"
+
+// FMTP contains default format parameters: packetization mode 1 (non-interleaved) and Constrained Baseline profile.
+const FMTP = "level-asymmetry-allowed=1;packetization-mode=1;profile-level-id=42e01f"
+
+// NAL unit types.
+const (
+	NALUSlice = 1
+	NALUIDR   = 5
+	NALUSEI   = 6
+	NALUSPS   = 7
+	NALUPPS   = 8
+)
+
+// NewCodec creates H.264 video codec.
+func NewCodec() rtp.VideoCodec {
+	return &codec{info: media.CodecInfo{
+		SDPName:      SDPName,
+		SampleRate:   rtp.VideoClockRate,
+		RTPClockRate: rtp.VideoClockRate,
+		RTPIsStatic:  false,
+		FileExt:      "h264",
+	}}
+}
+
+type codec struct {
+	info media.CodecInfo
+}
+
+func (c *codec) Info() media.CodecInfo {
+	return c.info
+}
+
+func (c *codec) FMTP() string {
+	return FMTP
+}
+
+func (c *codec) NewDepacketizer() prtp.Depacketizer {
+	return &codecs.H264Packet{}
+}
+
+func (c *codec) NewPayloader() prtp.Payloader {
+	return &codecs.H264Payloader{}
+}
+
+func (c *codec) IsKeyFrame(frame []byte) bool {
+	return IsKeyFrame(frame)
+}
+
+// NALUnits splits Annex B byte stream into NAL units, without start codes.
+func NALUnits(frame []byte, fnc func(nalu []byte)) {
+	start := -1
+	for i := 0; i+2 < len(frame); i++ {
+		if frame[i] != 0 || frame[i+1] != 0 || frame[i+2] != 1 {
+			continue
+		}
+		if start >= 0 {
+			end := i
+			if end > start && frame[end-1] == 0 {
+				end-- // 4-byte start code
+			}
+			fnc(frame[start:end])
+		}
+		i += 2
+		start = i + 1
+	}
+	if start >= 0 && start < len(frame) {
+		fnc(frame[start:])
+	}
+}
+
+// IsKeyFrame checks if the frame in Annex B format contains an IDR slice.
+func IsKeyFrame(frame []byte) bool {
+	key := false
+	NALUnits(frame, func(nalu []byte) {
+		if len(nalu) != 0 && nalu[0]&0x1f == NALUIDR {
+			key = true
+		}
+	})
+	return key
+}
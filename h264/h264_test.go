@@ -0,0 +1,97 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package h264
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/media-sdk/rtp"
+)
+
+type frameWriter struct {
+	frames []rtp.VideoFrame
+}
+
+func (w *frameWriter) String() string { return "frames" }
+
+func (w *frameWriter) WriteVideo(f rtp.VideoFrame) error {
+	f.Data = bytes.Clone(f.Data)
+	w.frames = append(w.frames, f)
+	return nil
+}
+
+func annexB(nalus ...[]byte) []byte {
+	var out []byte
+	for _, n := range nalus {
+		out = append(out, 0, 0, 0, 1)
+		out = append(out, n...)
+	}
+	return out
+}
+
+func TestNALUnits(t *testing.T) {
+	frame := []byte{0, 0, 0, 1, 0x67, 1, 2, 0, 0, 1, 0x68, 3, 0, 0, 0, 1, 0x65, 4, 5}
+	var got [][]byte
+	NALUnits(frame, func(nalu []byte) {
+		got = append(got, nalu)
+	})
+	require.Equal(t, [][]byte{
+		{0x67, 1, 2},
+		{0x68, 3},
+		{0x65, 4, 5},
+	}, got)
+	require.True(t, IsKeyFrame(frame))
+	require.False(t, IsKeyFrame(annexB([]byte{0x41, 1, 2})))
+	require.False(t, IsKeyFrame(nil))
+}
+
+func TestRoundTrip(t *testing.T) {
+	idr := make([]byte, 3000)
+	idr[0] = 0x65
+	for i := 1; i < len(idr); i++ {
+		idr[i] = byte(i%250) + 1 // avoid start codes
+	}
+	frames := []rtp.VideoFrame{
+		{Timestamp: 0, KeyFrame: true, Data: annexB([]byte{0x67, 0x42, 0xe0, 0x1f}, []byte{0x68, 0xce, 0x3c}, idr)},
+		{Timestamp: 3000, Data: annexB([]byte{0x41, 1, 2, 3})},
+	}
+	codec := NewCodec()
+
+	var buf rtp.Buffer
+	out := rtp.NewVideoStreamOut(rtp.NewSeqWriter(&buf).NewStream(96, rtp.VideoClockRate), codec, 0)
+	for _, f := range frames {
+		require.NoError(t, out.WriteVideo(f))
+	}
+	require.Greater(t, len(buf), 3)
+	for _, p := range buf {
+		require.LessOrEqual(t, len(p.Payload), rtp.DefVideoMTU)
+	}
+
+	var w frameWriter
+	r := rtp.NewVideoReceiver(codec, &w)
+	defer r.Close()
+	for _, p := range buf {
+		require.NoError(t, r.HandleRTP(&p.Header, p.Payload))
+	}
+	require.Len(t, w.frames, len(frames))
+	for i, f := range w.frames {
+		require.Equal(t, frames[i].KeyFrame, f.KeyFrame)
+		require.Equal(t, frames[i].Data, f.Data)
+		require.Equal(t, buf[0].Timestamp+frames[i].Timestamp, f.Timestamp)
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jitter
+
+import (
+	"time"
+
+	"github.com/pion/rtp"
+)
+
+const (
+	// adaptiveJitterMultiplier sets the target latency relative to the estimated jitter.
+	adaptiveJitterMultiplier = 3
+	// adaptiveShrinkDiv sets the fraction of the difference between current and desired latency
+	// that is applied on each packet, when the latency decreases.
+	adaptiveShrinkDiv = 256
+	// adaptiveMaxDelta is the max delay variation between two packets that is considered a jitter.
+	// Anything above that is considered a timestamp jump.
+	adaptiveMaxDelta = 3 * time.Second
+)
+
+// WithAdaptiveLatency enables adaptive latency mode for the buffer.
+//
+// In this mode, the buffer estimates network jitter by comparing packet arrival times with RTP timestamps
+// and adjusts the latency accordingly. Latency grows immediately on jitter spikes and shrinks slowly
+// once the network is stable. Latency always stays within min and max bounds.
+//
+// Latency passed to NewBuffer is used as an initial value.
+func WithAdaptiveLatency(clockRate int, minLatency, maxLatency time.Duration) Option {
+	return func(b *Buffer) {
+		if clockRate <= 0 {
+			return
+		}
+		if maxLatency < minLatency {
+			maxLatency = minLatency
+		}
+		b.adaptive = &adaptiveLatency{
+			clockRate: clockRate,
+			min:       minLatency,
+			max:       maxLatency,
+		}
+		b.latency = b.adaptive.clamp(b.latency)
+		b.adaptive.target = b.latency
+	}
+}
+
+type adaptiveLatency struct {
+	clockRate int
+	min, max  time.Duration
+
+	init     bool
+	ssrc     uint32
+	lastTS   uint32
+	lastRecv time.Time
+
+	jitter float64 // interarrival jitter (RFC 3550), in seconds
+	target time.Duration
+}
+
+func (a *adaptiveLatency) clamp(v time.Duration) time.Duration {
+	return min(max(v, a.min), a.max)
+}
+
+// estimate returns current jitter estimate.
+func (a *adaptiveLatency) estimate() time.Duration {
+	return time.Duration(a.jitter * float64(time.Second))
+}
+
+// update the jitter estimate with a new packet and returns the new target latency.
+func (a *adaptiveLatency) update(pkt *rtp.Packet, receivedAt time.Time) time.Duration {
+	if !a.init || pkt.SSRC != a.ssrc {
+		// Stream changed, timestamps cannot be compared to the previous ones.
+		a.init = true
+		a.ssrc = pkt.SSRC
+		a.lastTS = pkt.Timestamp
+		a.lastRecv = receivedAt
+		return a.target
+	}
+	dts := time.Duration(int32(pkt.Timestamp-a.lastTS)) * time.Second / time.Duration(a.clockRate)
+	delta := receivedAt.Sub(a.lastRecv) - dts
+	if delta < 0 {
+		delta = -delta
+	}
+	a.lastTS = pkt.Timestamp
+	a.lastRecv = receivedAt
+	if delta > adaptiveMaxDelta {
+		// Timestamp jump, not a network jitter.
+		return a.target
+	}
+	a.jitter += (delta.Seconds() - a.jitter) / 16
+
+	want := time.Duration(a.jitter * adaptiveJitterMultiplier * float64(time.Second))
+	want = a.clamp(max(want, delta))
+	if want > a.target {
+		// Grow quickly to avoid losing packets.
+		a.target = want
+	} else {
+		// Shrink slowly, in case the spike repeats.
+		a.target -= (a.target - want + adaptiveShrinkDiv - 1) / adaptiveShrinkDiv
+	}
+	return a.target
+}
@@ -34,6 +34,8 @@ type ExtPacket struct {
 type Buffer struct {
 	depacketizer rtp.Depacketizer
 	latency      time.Duration
+	adaptive     *adaptiveLatency
+	playout      *playout
 	logger       logger.Logger
 	onPacket     PacketFunc
 	onPacketLoss func()
@@ -43,6 +45,9 @@ type Buffer struct {
 
 	initialized bool
 	prevSN      uint16
+	history     seqHistory
+	seenSN      bool
+	maxSN       uint16
 	head        *packet
 	tail        *packet
 
@@ -62,6 +67,16 @@ type BufferStats struct {
 	PacketsDropped uint64 // packets dropped (incomplete)
 	PacketsPopped  uint64 // packets sent to handler
 	SamplesPopped  uint64 // samples sent to handler
+
+	PacketsLate      uint64 // packets arrived after they were declared lost (also counted as dropped)
+	PacketsDuplicate uint64 // duplicate packets
+	PacketsReordered uint64 // packets arrived after a packet with a higher sequence number
+	MaxReorderDepth  uint64 // max difference between the highest sequence number and a reordered packet
+
+	Delay DelayHistogram // time packets spent in the buffer
+
+	TargetLatency time.Duration // current buffer latency
+	Jitter        time.Duration // estimated network jitter (adaptive mode only)
 }
 
 type PacketFunc func(packets []ExtPacket)
@@ -121,9 +136,13 @@ func (b *Buffer) UpdateLatency(latency time.Duration) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
+	if b.adaptive != nil {
+		latency = b.adaptive.clamp(latency)
+		b.adaptive.target = latency
+	}
 	b.latency = latency
 	if b.head != nil {
-		b.timer.Reset(time.Until(b.head.extPacket.ReceivedAt.Add(latency)))
+		b.timer.Reset(time.Until(b.deadline(b.head)))
 	}
 }
 
@@ -164,14 +183,14 @@ func (b *Buffer) Stats() *BufferStats {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	return &BufferStats{
-		PacketsPushed:  b.stats.PacketsPushed,
-		PaddingPushed:  b.stats.PaddingPushed,
-		PacketsLost:    b.stats.PacketsLost,
-		PacketsDropped: b.stats.PacketsDropped,
-		PacketsPopped:  b.stats.PacketsPopped,
-		SamplesPopped:  b.stats.SamplesPopped,
+	var jitter time.Duration
+	if b.adaptive != nil {
+		jitter = b.adaptive.estimate()
 	}
+	stats := *b.stats
+	stats.TargetLatency = b.latency
+	stats.Jitter = jitter
+	return &stats
 }
 
 func (s *BufferStats) PacketLoss() float64 {
@@ -195,11 +214,21 @@ func (b *Buffer) push(pkt *rtp.Packet, receivedAt time.Time) {
 		if !b.initialized {
 			return
 		}
+	} else if b.adaptive != nil {
+		// Late packets must be accounted for as well, since they indicate that the latency is too low.
+		b.latency = b.adaptive.update(pkt, receivedAt)
 	}
 
 	if b.initialized && before(pkt.SequenceNumber, b.prevSN) {
+		if b.history.within(b.prevSN, pkt.SequenceNumber) && b.history.has(pkt.SequenceNumber) {
+			// already sent to the handler
+			b.stats.PacketsDuplicate++
+			return
+		}
+		b.trackReorder(pkt.SequenceNumber)
 		// packet expired
 		if !pkt.Padding {
+			b.stats.PacketsLate++
 			b.stats.PacketsDropped++
 			if b.onPacketLoss != nil {
 				b.onPacketLoss()
@@ -207,8 +236,16 @@ func (b *Buffer) push(pkt *rtp.Packet, receivedAt time.Time) {
 		}
 		return
 	}
+	if b.contains(pkt.SequenceNumber) {
+		b.stats.PacketsDuplicate++
+		return
+	}
+	b.trackReorder(pkt.SequenceNumber)
 
 	p := b.newPacket(pkt, receivedAt)
+	if b.playout != nil {
+		p.playoutAt, p.tsDiscont = b.playout.schedule(pkt, receivedAt)
+	}
 
 	discont := !b.initialized || !withinRange(pkt.SequenceNumber, b.prevSN)
 
@@ -276,19 +313,57 @@ func (b *Buffer) push(pkt *rtp.Packet, receivedAt time.Time) {
 	}
 }
 
+// trackReorder updates the highest sequence number and reordering stats.
+func (b *Buffer) trackReorder(sn uint16) {
+	if !b.seenSN || !before(sn, b.maxSN) || !withinRange(sn, b.maxSN) {
+		b.seenSN = true
+		b.maxSN = sn
+		return
+	}
+	if depth := uint64(b.maxSN - sn); depth > 0 {
+		b.stats.PacketsReordered++
+		b.stats.MaxReorderDepth = max(b.stats.MaxReorderDepth, depth)
+	}
+}
+
+// contains checks if a packet with a given sequence number is already in the buffer.
+func (b *Buffer) contains(sn uint16) bool {
+	if b.tail == nil || !before(sn, b.tail.extPacket.SequenceNumber) {
+		return false
+	}
+	for c := b.tail; c != nil; c = c.prev {
+		if c.extPacket.SequenceNumber == sn {
+			return true
+		}
+	}
+	return false
+}
+
+// deadline returns the time when the packet must be released, even if previous packets are still missing.
+func (b *Buffer) deadline(p *packet) time.Time {
+	if b.playout != nil {
+		return p.playoutAt.Add(b.latency)
+	}
+	return p.extPacket.ReceivedAt.Add(b.latency)
+}
+
 // popReady pushes all ready samples to the out channel
 func (b *Buffer) popReady() {
-	expiry := time.Now().Add(-b.latency)
+	now := time.Now()
 
-	b.dropIncompleteExpired(expiry)
+	b.dropIncompleteExpired(now)
 
 	loss := false
 	for b.head != nil &&
 		b.head.isComplete() {
 
+		if b.playout != nil && now.Before(b.deadline(b.head)) {
+			// scheduled for later
+			break
+		}
 		if b.head.extPacket.SequenceNumber == b.prevSN+1 || b.head.discont || !b.initialized {
 			// normal
-		} else if !expiry.Before(b.head.extPacket.ReceivedAt) {
+		} else if !now.Before(b.deadline(b.head)) {
 			// max latency reached
 			loss = true
 			b.stats.PacketsLost += uint64(b.head.extPacket.SequenceNumber - b.prevSN - 1)
@@ -296,9 +371,7 @@ func (b *Buffer) popReady() {
 			break
 		}
 
-		if sample := b.popSample(); len(sample) > 0 {
-			b.onPacket(sample)
-		}
+		b.emitSample()
 	}
 
 	if loss && b.onPacketLoss != nil {
@@ -306,13 +379,13 @@ func (b *Buffer) popReady() {
 	}
 
 	if b.head != nil {
-		b.timer.Reset(time.Until(b.head.extPacket.ReceivedAt.Add(b.latency)))
+		b.timer.Reset(time.Until(b.deadline(b.head)))
 	}
 }
 
 // dropIncompleteExpired drops incomplete expired packets
-func (b *Buffer) dropIncompleteExpired(expiry time.Time) {
-	dropped := b.dropIncomplete(expiry, false)
+func (b *Buffer) dropIncompleteExpired(now time.Time) {
+	dropped := b.dropIncomplete(now, false)
 
 	if dropped && b.onPacketLoss != nil {
 		b.onPacketLoss()
@@ -320,16 +393,18 @@ func (b *Buffer) dropIncompleteExpired(expiry time.Time) {
 }
 
 // dropIncomplete drops incomplete packets at the head of the buffer.
-// If force is false, only drops packets that arrived before expiry.
-func (b *Buffer) dropIncomplete(expiry time.Time, force bool) bool {
+// If force is false, only drops packets with a deadline before now.
+func (b *Buffer) dropIncomplete(now time.Time, force bool) bool {
 	dropped := false
 
-	for b.head != nil && !b.head.isComplete() && (force || b.head.extPacket.ReceivedAt.Before(expiry)) {
+	for b.head != nil && !b.head.isComplete() && (force || b.deadline(b.head).Before(now)) {
 		if b.initialized && !b.head.discont {
 			b.stats.PacketsLost += uint64(b.head.extPacket.SequenceNumber - b.prevSN - 1)
 		}
 
-		b.free(b.popHead())
+		c := b.popHead()
+		b.history.set(c.extPacket.SequenceNumber, false)
+		b.free(c)
 
 		dropped = true
 		b.stats.PacketsDropped++
@@ -356,9 +431,7 @@ func (b *Buffer) Flush() {
 			b.stats.PacketsLost += uint64(b.head.extPacket.SequenceNumber - b.prevSN - 1)
 		}
 
-		if sample := b.popSample(); len(sample) > 0 {
-			b.onPacket(sample)
-		}
+		b.emitSample()
 
 		if b.dropIncomplete(time.Time{}, true) {
 			dropped = true
@@ -370,8 +443,28 @@ func (b *Buffer) Flush() {
 	}
 }
 
+// emitSample pops a sample from the head of the buffer and sends it to the handler.
+func (b *Buffer) emitSample() {
+	var (
+		gap    Gap
+		hasGap bool
+	)
+	if b.playout != nil {
+		gap, hasGap = b.playout.next(b.head)
+	}
+	sample := b.popSample()
+	if len(sample) == 0 {
+		return
+	}
+	if hasGap && b.playout.onGap != nil {
+		b.playout.onGap(gap)
+	}
+	b.onPacket(sample)
+}
+
 func (b *Buffer) popSample() []ExtPacket {
 	sample := make([]ExtPacket, 0, b.size)
+	now := time.Now()
 	end := false
 	for !end {
 		c := b.popHead()
@@ -379,6 +472,7 @@ func (b *Buffer) popSample() []ExtPacket {
 
 		if !c.extPacket.Padding {
 			sample = append(sample, c.extPacket)
+			b.stats.Delay.observe(now.Sub(c.extPacket.ReceivedAt))
 		}
 
 		b.stats.PacketsPopped++
@@ -393,6 +487,7 @@ func (b *Buffer) popSample() []ExtPacket {
 
 func (b *Buffer) popHead() *packet {
 	c := b.head
+	b.history.advance(b.prevSN, c.extPacket.SequenceNumber)
 	b.prevSN = c.extPacket.SequenceNumber
 	b.head = c.next
 	if b.head == nil {
@@ -290,6 +290,207 @@ func TestFlushReportsLoss(t *testing.T) {
 	})
 }
 
+func TestAdaptiveLatency(t *testing.T) {
+	const (
+		minLatency = 20 * time.Millisecond
+		maxLatency = 500 * time.Millisecond
+		frameDur   = 20 * time.Millisecond
+		frameSize  = 160
+	)
+	b := NewBuffer(&testDepacketizer{}, 100*time.Millisecond, func(packets []ExtPacket) {},
+		WithAdaptiveLatency(8000, minLatency, maxLatency))
+	defer b.Close()
+	require.Equal(t, 100*time.Millisecond, b.Stats().TargetLatency)
+
+	s := newTestStream()
+	now := time.Now()
+	var ts uint32
+	push := func(delay time.Duration) {
+		p := s.gen(true, true)
+		p.Timestamp = ts
+		b.PushAt(p, now.Add(delay))
+		ts += frameSize
+		now = now.Add(frameDur)
+	}
+
+	// Stable network: latency shrinks to the minimum.
+	for range 5000 {
+		push(0)
+	}
+	stats := b.Stats()
+	require.Equal(t, minLatency, stats.TargetLatency)
+	require.Zero(t, stats.Jitter)
+
+	// Spike: latency grows immediately.
+	push(0)
+	push(150 * time.Millisecond)
+	stats = b.Stats()
+	require.Equal(t, 150*time.Millisecond, stats.TargetLatency)
+	require.NotZero(t, stats.Jitter)
+
+	// Stable again: latency shrinks, but slowly.
+	for range 50 {
+		push(0)
+	}
+	stats = b.Stats()
+	require.Less(t, stats.TargetLatency, 150*time.Millisecond)
+	require.Greater(t, stats.TargetLatency, 100*time.Millisecond)
+
+	for range 5000 {
+		push(0)
+	}
+	require.Equal(t, minLatency, b.Stats().TargetLatency)
+
+	// Large spikes are limited by the max latency.
+	push(time.Second)
+	require.Equal(t, maxLatency, b.Stats().TargetLatency)
+}
+
+func TestAudioPlayout(t *testing.T) {
+	out := make(chan []ExtPacket, 100)
+	var gaps []Gap
+	b := NewBuffer(&testDepacketizer{}, 100*time.Millisecond, chanFunc(t, out), WithAudioPlayout(8000, func(gap Gap) {
+		gaps = append(gaps, gap)
+	}))
+	defer b.Close()
+
+	s := newTestStream()
+	at := time.Now().Add(-time.Minute) // all packets are due immediately
+	var ts uint32
+	push := func(ssrc uint32) {
+		p := s.gen(true, true)
+		p.SSRC = ssrc
+		p.Timestamp = ts
+		b.PushAt(p, at)
+		ts += 160
+		at = at.Add(20 * time.Millisecond)
+	}
+	skip := func(frames int) {
+		ts += uint32(frames) * 160
+		at = at.Add(time.Duration(frames) * 20 * time.Millisecond)
+	}
+
+	for range 5 {
+		push(1)
+		checkSample(t, out, 1)
+	}
+	require.Empty(t, gaps)
+
+	// DTX
+	skip(10)
+	push(1)
+	checkSample(t, out, 1)
+	require.Equal(t, []Gap{{Samples: 1600}}, gaps)
+	gaps = nil
+
+	// packet loss
+	_ = s.gen(true, true)
+	skip(1)
+	push(1)
+	checkSample(t, out, 1)
+	require.Equal(t, []Gap{{Samples: 160, Lost: 1}}, gaps)
+	gaps = nil
+
+	// packet duration change reports a gap only once
+	for range 3 {
+		push(1)
+		ts += 160
+		checkSample(t, out, 1)
+	}
+	require.Equal(t, []Gap{{Samples: 160}}, gaps)
+	gaps = nil
+
+	// timestamp jump
+	ts += 8000 * 100
+	push(1)
+	checkSample(t, out, 1)
+	require.Equal(t, []Gap{{Discont: true}}, gaps)
+	gaps = nil
+
+	// new stream
+	push(2)
+	checkSample(t, out, 1)
+	require.Equal(t, []Gap{{Discont: true}}, gaps)
+	gaps = nil
+
+	// packets are held until their scheduled playout time
+	at = time.Now().Add(-100 * time.Millisecond)
+	push(2)
+	checkSample(t, out, 1)
+	push(2)
+	checkSample(t, out, 0)
+	time.Sleep(100 * time.Millisecond)
+	checkSample(t, out, 1)
+	require.Equal(t, []Gap{{Discont: true}}, gaps)
+}
+
+func TestLateAndDuplicatePackets(t *testing.T) {
+	out := make(chan []ExtPacket, 100)
+	b := NewBuffer(&testDepacketizer{}, 100*time.Millisecond, chanFunc(t, out))
+	defer b.Close()
+	s := newTestStream()
+
+	var pkts []*rtp.Packet
+	for range 12 {
+		pkts = append(pkts, s.gen(true, true))
+	}
+	for _, p := range pkts[:5] {
+		b.Push(p)
+		checkSample(t, out, 1)
+	}
+
+	// duplicate of a packet that was already sent
+	b.Push(pkts[3])
+	checkSample(t, out, 0)
+
+	// packet 5 is missing
+	for _, p := range pkts[6:9] {
+		b.Push(p)
+		checkSample(t, out, 0)
+	}
+	time.Sleep(200 * time.Millisecond)
+	for range 3 {
+		checkSample(t, out, 1)
+	}
+
+	// packet 5 arrives after it was declared lost
+	b.Push(pkts[5])
+	checkSample(t, out, 0)
+
+	// duplicate of a packet that is still in the buffer
+	b.Push(pkts[10])
+	b.Push(pkts[10])
+	checkSample(t, out, 0)
+
+	b.Push(pkts[9])
+	checkSample(t, out, 1)
+	checkSample(t, out, 1)
+
+	stats := b.Stats()
+	checkStats(t, b, &BufferStats{
+		PacketsPushed:  13,
+		PacketsLost:    1,
+		PacketsDropped: 1,
+		PacketsPopped:  10,
+		SamplesPopped:  10,
+	})
+	require.EqualValues(t, 1, stats.PacketsLate)
+	require.EqualValues(t, 2, stats.PacketsDuplicate)
+	require.EqualValues(t, 2, stats.PacketsReordered)
+	require.EqualValues(t, 3, stats.MaxReorderDepth)
+	require.EqualValues(t, 10, stats.Delay.Count)
+
+	count, sum, buckets := stats.Delay.Prometheus()
+	require.EqualValues(t, 10, count)
+	require.Greater(t, sum, 0.1)
+	require.Len(t, buckets, len(DelayBuckets))
+	require.EqualValues(t, 10, buckets[time.Second.Seconds()])
+
+	metrics := stats.Metrics()
+	require.EqualValues(t, 1, metrics["packets_late_total"])
+	require.EqualValues(t, 0.1, metrics["target_latency_seconds"])
+}
+
 func checkSample(t *testing.T, out chan []ExtPacket, expected int) {
 	select {
 	case sample := <-out:
@@ -25,6 +25,10 @@ type packet struct {
 	prev, next *packet
 	start, end bool
 	discont    bool
+
+	// used in audio playout mode only
+	playoutAt time.Time // time when the packet is expected to be played, without the buffer latency
+	tsDiscont bool      // timestamp jump or a new stream
 }
 
 func (b *Buffer) newPacket(pkt *rtp.Packet, receivedAt time.Time) *packet {
@@ -38,6 +42,8 @@ func (b *Buffer) newPacket(pkt *rtp.Packet, receivedAt time.Time) *packet {
 
 	p.prev = nil
 	p.next = nil
+	p.playoutAt = time.Time{}
+	p.tsDiscont = false
 	p.start = b.depacketizer.IsPartitionHead(pkt.Payload)
 	p.end = b.depacketizer.IsPartitionTail(pkt.Marker, pkt.Payload)
 	p.extPacket = ExtPacket{receivedAt, pkt}
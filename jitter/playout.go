@@ -0,0 +1,161 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jitter
+
+import (
+	"time"
+
+	"github.com/pion/rtp"
+)
+
+const (
+	// playoutMaxJump is the max difference between expected and actual packet arrival time.
+	// Anything above that is considered a timestamp jump.
+	playoutMaxJump = 3 * time.Second
+	// playoutSkewDiv controls how fast the playout schedule follows increasing network delay.
+	// This compensates for the sender clock being slower than ours.
+	playoutSkewDiv = 1024
+)
+
+// Gap describes a gap in the audio stream detected in the audio playout mode.
+type Gap struct {
+	// Samples is the duration of the gap in RTP clock units. It is zero for discontinuities.
+	Samples uint32
+	// Lost is the number of packets lost in the gap. It is zero when the gap is caused by DTX.
+	Lost int
+	// Discont is set when the RTP timestamp jumps or the stream (SSRC) changes.
+	// The gap duration is unknown in this case, and downstream should reset its state.
+	Discont bool
+}
+
+// GapFunc is called right before the packet which follows the gap is sent to the handler.
+type GapFunc func(gap Gap)
+
+// WithAudioPlayout enables timestamp-aware playout for audio streams.
+//
+// In this mode, packets are scheduled based on their RTP timestamps instead of the receive time,
+// and are released when their playout time (plus the buffer latency) is reached.
+// Gaps in the stream (lost packets, DTX) and discontinuities (timestamp jumps, SSRC changes) are reported to onGap,
+// which could be used to generate packet loss concealment or comfort noise.
+func WithAudioPlayout(clockRate int, onGap GapFunc) Option {
+	return func(b *Buffer) {
+		if clockRate <= 0 {
+			return
+		}
+		b.playout = &playout{
+			clockRate: clockRate,
+			onGap:     onGap,
+		}
+	}
+}
+
+type playout struct {
+	clockRate int
+	onGap     GapFunc
+
+	// schedule
+	scheduled bool
+	ssrc      uint32
+	refTS     uint32
+	refAt     time.Time
+
+	// gap detection
+	started bool
+	lastTS  uint32
+	lastSN  uint16
+	ptime   uint32 // packet duration in RTP clock units
+	delta   uint32 // last timestamp difference between consecutive packets
+}
+
+func (o *playout) tsDuration(ts int32) time.Duration {
+	return time.Duration(ts) * time.Second / time.Duration(o.clockRate)
+}
+
+// schedule returns the time when the packet is expected to be played, not accounting for the buffer latency.
+// It also reports if the packet starts a new timeline.
+func (o *playout) schedule(pkt *rtp.Packet, receivedAt time.Time) (time.Time, bool) {
+	if !o.scheduled || pkt.SSRC != o.ssrc {
+		discont := o.scheduled
+		o.scheduled = true
+		o.ssrc = pkt.SSRC
+		o.refTS = pkt.Timestamp
+		o.refAt = receivedAt
+		return receivedAt, discont
+	}
+	expected := o.refAt.Add(o.tsDuration(int32(pkt.Timestamp - o.refTS)))
+	delay := receivedAt.Sub(expected)
+	if delay > playoutMaxJump || delay < -playoutMaxJump {
+		// Timestamp jump, start a new timeline.
+		o.refTS = pkt.Timestamp
+		o.refAt = receivedAt
+		return receivedAt, true
+	}
+	if delay < 0 {
+		// Packet arrived earlier than expected: network delay decreased, or the sender clock is faster.
+		expected = receivedAt
+	} else {
+		expected = expected.Add(delay / playoutSkewDiv)
+	}
+	// Keep the reference close to the last packet, so that timestamp difference never overflows.
+	o.refTS = pkt.Timestamp
+	o.refAt = expected
+	return expected, false
+}
+
+// next updates gap detection state with a packet that is about to be sent to the handler and returns a gap preceding it.
+func (o *playout) next(p *packet) (Gap, bool) {
+	pkt := p.extPacket
+	if pkt.Padding {
+		return Gap{}, false
+	}
+	ts, sn := pkt.Timestamp, pkt.SequenceNumber
+	lost := int(sn - o.lastSN - 1)
+	delta := ts - o.lastTS
+	if !o.started || p.tsDiscont || p.discont || int32(delta) <= 0 {
+		started := o.started
+		o.started = true
+		o.lastTS, o.lastSN = ts, sn
+		o.ptime, o.delta = 0, 0
+		// No need to report a discontinuity for the first packet.
+		return Gap{Discont: true}, started
+	}
+	o.lastTS, o.lastSN = ts, sn
+
+	var gap uint32
+	if lost == 0 {
+		switch {
+		case o.ptime == 0 || delta <= o.ptime:
+			o.ptime = delta
+		case delta == o.delta:
+			// Packet duration increased: two consecutive packets have the same timestamp difference.
+			o.ptime = delta
+		default:
+			// Either DTX, or the packet duration increased. Cannot tell until the next packet arrives.
+			gap = delta - o.ptime
+		}
+		o.delta = delta
+	} else {
+		o.delta = 0
+		if o.ptime != 0 && delta > o.ptime {
+			gap = delta - o.ptime
+		} else if o.ptime == 0 {
+			gap = uint32(uint64(delta) * uint64(lost) / uint64(lost+1))
+		}
+	}
+	if gap == 0 && lost == 0 {
+		return Gap{}, false
+	}
+	return Gap{Samples: gap, Lost: lost}, true
+}
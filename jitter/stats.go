@@ -0,0 +1,127 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jitter
+
+import (
+	"time"
+)
+
+// DelayBuckets are upper bounds of buffering delay histogram buckets.
+var DelayBuckets = [...]time.Duration{
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	20 * time.Millisecond,
+	40 * time.Millisecond,
+	60 * time.Millisecond,
+	80 * time.Millisecond,
+	100 * time.Millisecond,
+	150 * time.Millisecond,
+	200 * time.Millisecond,
+	300 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+}
+
+// DelayHistogram tracks the time packets spent in the buffer.
+type DelayHistogram struct {
+	// Counts for each bucket in DelayBuckets. These are not cumulative.
+	// The last element counts delays above the largest bucket.
+	Counts [len(DelayBuckets) + 1]uint64
+	Sum    time.Duration
+	Count  uint64
+}
+
+func (h *DelayHistogram) observe(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	i := 0
+	for i < len(DelayBuckets) && d > DelayBuckets[i] {
+		i++
+	}
+	h.Counts[i]++
+	h.Sum += d
+	h.Count++
+}
+
+// Prometheus returns the histogram in a form accepted by prometheus.MustNewConstHistogram.
+// Buckets are cumulative and keyed by the upper bound in seconds.
+func (h *DelayHistogram) Prometheus() (count uint64, sum float64, buckets map[float64]uint64) {
+	buckets = make(map[float64]uint64, len(DelayBuckets))
+	var total uint64
+	for i, b := range DelayBuckets {
+		total += h.Counts[i]
+		buckets[b.Seconds()] = total
+	}
+	return h.Count, h.Sum.Seconds(), buckets
+}
+
+// Metrics returns a snapshot of buffer counters and gauges keyed by Prometheus-style metric names (without a namespace).
+// Counters have a "_total" suffix, durations are in seconds.
+func (s *BufferStats) Metrics() map[string]float64 {
+	return map[string]float64{
+		"packets_pushed_total":    float64(s.PacketsPushed),
+		"padding_pushed_total":    float64(s.PaddingPushed),
+		"packets_lost_total":      float64(s.PacketsLost),
+		"packets_dropped_total":   float64(s.PacketsDropped),
+		"packets_popped_total":    float64(s.PacketsPopped),
+		"samples_popped_total":    float64(s.SamplesPopped),
+		"packets_late_total":      float64(s.PacketsLate),
+		"packets_duplicate_total": float64(s.PacketsDuplicate),
+		"packets_reordered_total": float64(s.PacketsReordered),
+		"max_reorder_depth":       float64(s.MaxReorderDepth),
+		"target_latency_seconds":  s.TargetLatency.Seconds(),
+		"jitter_seconds":          s.Jitter.Seconds(),
+		"packet_loss_ratio":       s.PacketLoss(),
+	}
+}
+
+const seqHistorySize = 512
+
+// seqHistory tracks which of the recent sequence numbers were sent to the handler.
+type seqHistory struct {
+	bits [seqHistorySize / 64]uint64
+}
+
+func (h *seqHistory) set(sn uint16, v bool) {
+	i := sn % seqHistorySize
+	if v {
+		h.bits[i/64] |= 1 << (i % 64)
+	} else {
+		h.bits[i/64] &^= 1 << (i % 64)
+	}
+}
+
+func (h *seqHistory) has(sn uint16) bool {
+	i := sn % seqHistorySize
+	return h.bits[i/64]&(1<<(i%64)) != 0
+}
+
+// advance marks sn as sent and all sequence numbers between last and sn as missing.
+func (h *seqHistory) advance(last, sn uint16) {
+	if d := sn - last; d > seqHistorySize || before(sn, last) {
+		h.bits = [seqHistorySize / 64]uint64{}
+	} else {
+		for c := last + 1; c != sn; c++ {
+			h.set(c, false)
+		}
+	}
+	h.set(sn, true)
+}
+
+// within checks if the history still has information about sn.
+func (h *seqHistory) within(last, sn uint16) bool {
+	return last-sn < seqHistorySize
+}
@@ -32,6 +32,15 @@ const (
 	DefaultInputBufferMin    = DefaultInputBufferFrames/2 + 1
 )
 
+const (
+	// driftSmoothing is a weight of the new buffer fill measurement in the moving average.
+	driftSmoothing = 0.05
+	// driftGain is a speed adjustment for each frame of difference between the buffer fill and the target.
+	driftGain = 0.002
+	// driftMaxAdjust limits the speed adjustment, so that it stays inaudible.
+	driftMaxAdjust = 0.005
+)
+
 type Stats struct {
 	Tracks       atomic.Int64
 	TracksTotal  atomic.Uint64
@@ -65,6 +74,30 @@ type Input struct {
 	mu         sync.Mutex
 	buf        *ring.Buffer[int16]
 	buffering  bool
+	conv       *msdk.VarResampler // converts samples to the mixer sample rate; nil if not needed
+	drift      float64            // smoothed difference between the buffer fill and the target, in frames
+}
+
+type InputOptions func(*Input)
+
+// WithInputSampleRate sets the sample rate of the input. Samples will be converted to the mixer sample rate.
+func WithInputSampleRate(sampleRate int) InputOptions {
+	return func(i *Input) {
+		if sampleRate > 0 {
+			i.sampleRate = sampleRate
+		}
+	}
+}
+
+// Clock is a time source for the mixer.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
 }
 
 type Mixer struct {
@@ -75,6 +108,8 @@ type Mixer struct {
 	mu     sync.Mutex
 	inputs []*Input
 
+	clock     Clock
+	manual    bool // mixing is driven by the caller instead of the ticker
 	tickerDur time.Duration
 	ticker    *time.Ticker
 	mixBuf    []int32          // mix result buffer
@@ -90,6 +125,8 @@ type Mixer struct {
 	// inputBufferMin is the minimal number of buffered frames required to start mixing.
 	// It affects inputs initially, or after they start to starve.
 	inputBufferMin int
+	// driftCompensation enables adjusting the resampling ratio of inputs based on their buffer fill.
+	driftCompensation bool
 
 	stats *Stats
 }
@@ -122,6 +159,24 @@ func WithInputBufferFrames(frames int) MixerOptions {
 	}
 }
 
+// WithDriftCompensation makes mixer inputs gently adjust their resampling ratio to keep the buffer fill stable.
+// This compensates for the clock drift between the sources and the mixer, instead of dropping samples on overflow
+// or restarting inputs when they starve.
+func WithDriftCompensation() MixerOptions {
+	return func(m *Mixer) {
+		m.driftCompensation = true
+	}
+}
+
+// WithClock sets a time source used to determine how many frames must be mixed on each update.
+func WithClock(clock Clock) MixerOptions {
+	return func(m *Mixer) {
+		if clock != nil {
+			m.clock = clock
+		}
+	}
+}
+
 func WithStats(stats *Stats) MixerOptions {
 	return func(m *Mixer) {
 		m.stats = stats
@@ -129,6 +184,32 @@ func WithStats(stats *Stats) MixerOptions {
 }
 
 func NewMixer(out msdk.Writer[msdk.PCM16Sample], bufferDur time.Duration, channels int, options ...MixerOptions) (*Mixer, error) {
+	m, err := newMixerDur(out, bufferDur, channels, options...)
+	if err != nil {
+		return nil, err
+	}
+	m.ticker = time.NewTicker(bufferDur)
+
+	go m.start()
+
+	return m, nil
+}
+
+// NewManualMixer creates a mixer that is driven by the caller, instead of a real-time ticker.
+// Frames are mixed by calling MixN or Update. This allows mixing faster than real time, for example for offline processing.
+func NewManualMixer(out msdk.Writer[msdk.PCM16Sample], bufferDur time.Duration, channels int, options ...MixerOptions) (*Mixer, error) {
+	m, err := newMixerDur(out, bufferDur, channels, options...)
+	if err != nil {
+		return nil, err
+	}
+	m.manual = true
+	if m.outchan != nil {
+		go m.writer()
+	}
+	return m, nil
+}
+
+func newMixerDur(out msdk.Writer[msdk.PCM16Sample], bufferDur time.Duration, channels int, options ...MixerOptions) (*Mixer, error) {
 	if channels != 1 {
 		return nil, fmt.Errorf("only mono mixing is supported")
 	}
@@ -136,10 +217,6 @@ func NewMixer(out msdk.Writer[msdk.PCM16Sample], bufferDur time.Duration, channe
 	mixSize := int(time.Duration(out.SampleRate()) * bufferDur / time.Second)
 	m := newMixer(out, mixSize, options...)
 	m.tickerDur = bufferDur
-	m.ticker = time.NewTicker(bufferDur)
-
-	go m.start()
-
 	return m, nil
 }
 
@@ -148,6 +225,7 @@ func newMixer(out msdk.Writer[msdk.PCM16Sample], mixSize int, options ...MixerOp
 		out:               out,
 		outchan:           nil, // Write directly to out
 		sampleRate:        out.SampleRate(),
+		clock:             systemClock{},
 		mixBuf:            make([]int32, mixSize),
 		mixTmp:            make(msdk.PCM16Sample, mixSize),
 		stats:             nil,
@@ -233,7 +311,7 @@ func (m *Mixer) mixOnce() {
 
 func (m *Mixer) mixUpdate() {
 	n := 0
-	now := time.Now()
+	now := m.clock.Now()
 
 	if m.lastMixEndTs.IsZero() {
 		m.stats.TimedMixes.Add(1)
@@ -299,13 +377,39 @@ func (m *Mixer) start() {
 	}
 }
 
+// MixN immediately mixes n frames. It can only be used with mixers created by NewManualMixer.
+func (m *Mixer) MixN(n int) {
+	if !m.manual {
+		panic("mixer is driven by a ticker")
+	}
+	if m.stopped.IsBroken() {
+		return
+	}
+	for i := 0; i < n; i++ {
+		m.stats.TimedMixes.Add(1)
+		m.mixOnce()
+	}
+}
+
+// Update mixes a number of frames according to the time passed on the mixer clock since the last update,
+// the same way the real-time ticker does. It can only be used with mixers created by NewManualMixer.
+func (m *Mixer) Update() {
+	if !m.manual {
+		panic("mixer is driven by a ticker")
+	}
+	if m.stopped.IsBroken() {
+		return
+	}
+	m.mixUpdate()
+}
+
 func (m *Mixer) Stop() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.stopped.Break()
 }
 
-func (m *Mixer) NewInput() *Input {
+func (m *Mixer) NewInput(options ...InputOptions) *Input {
 	if m == nil {
 		return nil
 	}
@@ -324,6 +428,12 @@ func (m *Mixer) NewInput() *Input {
 		buf:        ring.NewBuffer[int16](len(m.mixBuf) * m.inputBufferFrames),
 		buffering:  true, // buffer some data initially
 	}
+	for _, option := range options {
+		option(inp)
+	}
+	if inp.sampleRate != m.sampleRate || m.driftCompensation {
+		inp.conv = msdk.VarResampleWriter(inputBuffer{inp}, inp.sampleRate)
+	}
 	m.inputs = append(m.inputs, inp)
 	return inp
 }
@@ -390,6 +500,33 @@ func (i *Input) WriteSample(sample msdk.PCM16Sample) error {
 
 	i.m.stats.InputFrames.Add(1)
 	i.m.stats.InputSamples.Add(uint64(len(sample)))
+	if i.conv == nil {
+		return i.write(sample)
+	}
+	if err := i.conv.WriteSample(sample); err != nil {
+		return err
+	}
+	if i.m.driftCompensation {
+		i.compensateDrift()
+	}
+	return nil
+}
+
+// compensateDrift adjusts the resampling ratio to keep the buffer fill around the level at which the input starts playing.
+func (i *Input) compensateDrift() {
+	if i.buffering {
+		return
+	}
+	frame := len(i.m.mixBuf)
+	target := i.m.inputBufferMin * frame
+	diff := float64(i.buf.Len()-target) / float64(frame)
+	i.drift += (diff - i.drift) * driftSmoothing
+	adjust := min(max(i.drift*driftGain, -driftMaxAdjust), driftMaxAdjust)
+	i.conv.SetSpeed(1 + adjust)
+}
+
+// write converted samples to the buffer. Must be called with the lock held.
+func (i *Input) write(sample msdk.PCM16Sample) error {
 	if discarded := i.buf.Len() + len(sample) - i.buf.Size(); discarded > 0 {
 		i.m.stats.InputFramesDropped.Add(1)
 		i.m.stats.InputSamplesDropped.Add(uint64(discarded))
@@ -398,3 +535,24 @@ func (i *Input) WriteSample(sample msdk.PCM16Sample) error {
 	_, err := i.buf.Write(sample)
 	return err
 }
+
+// inputBuffer writes resampled samples to the input buffer.
+type inputBuffer struct {
+	i *Input
+}
+
+func (b inputBuffer) String() string {
+	return fmt.Sprintf("MixBuffer(%d)", b.i.m.sampleRate)
+}
+
+func (b inputBuffer) SampleRate() int {
+	return b.i.m.sampleRate
+}
+
+func (b inputBuffer) WriteSample(sample msdk.PCM16Sample) error {
+	return b.i.write(sample)
+}
+
+func (b inputBuffer) Close() error {
+	return nil
+}
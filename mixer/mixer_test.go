@@ -62,13 +62,25 @@ type testMixer struct {
 	*Mixer
 }
 
-func newTestMixer(t testing.TB) *testMixer {
+func newTestMixer(t testing.TB, options ...MixerOptions) *testMixer {
 	m := &testMixer{t: t}
 
-	m.Mixer = newMixer(newTestWriter(&m.sample, 8000), 5)
+	m.Mixer = newMixer(newTestWriter(&m.sample, 8000), 5, options...)
 	return m
 }
 
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func (c *testClock) Add(dt time.Duration) {
+	c.now = c.now.Add(dt)
+}
+
 func (m *testMixer) Expect(exp msdk.PCM16Sample, msgAndArgs ...any) {
 	m.t.Helper()
 	m.mixOnce()
@@ -199,7 +211,8 @@ func TestMixer(t *testing.T) {
 
 	t.Run("catches up after not running for long", func(t *testing.T) {
 		step := 20 * time.Millisecond
-		m := newTestMixer(t)
+		clock := &testClock{now: time.Unix(0, 0)}
+		m := newTestMixer(t, WithClock(clock))
 		m.tickerDur = step
 
 		inp := m.NewInput()
@@ -209,15 +222,90 @@ func TestMixer(t *testing.T) {
 			WriteSampleN(inp, i)
 		}
 
-		time.Sleep(step)
+		clock.Add(step)
 		m.mixUpdate()
 		require.EqualValues(t, 1, m.mixCnt)
 		m.CheckSampleN(0)
 
 		const steps = DefaultInputBufferFrames/2 + 1
-		time.Sleep(step*steps + step/2)
+		clock.Add(step*steps + step/2)
 		m.mixUpdate()
 		require.EqualValues(t, 1+steps, m.mixCnt)
 		m.CheckSampleN(steps)
 	})
+
+	t.Run("converts input sample rate", func(t *testing.T) {
+		var sample msdk.PCM16Sample
+		m := newMixer(newTestWriter(&sample, 8000), 160)
+		inp := m.NewInput(WithInputSampleRate(16000))
+		defer inp.Close()
+		require.Equal(t, 16000, inp.SampleRate())
+
+		frame := make(msdk.PCM16Sample, 320)
+		for i := range frame {
+			frame[i] = 1000
+		}
+		for i := 0; i < 10; i++ {
+			inp.WriteSample(frame)
+			m.mixOnce()
+		}
+		require.Len(t, sample, 160)
+		for _, v := range sample {
+			require.InDelta(t, 1000, v, 1)
+		}
+		require.Zero(t, m.stats.InputSamplesDropped.Load())
+	})
+
+	t.Run("compensates clock drift", func(t *testing.T) {
+		var sample msdk.PCM16Sample
+		m := newMixer(newTestWriter(&sample, 8000), 160, WithDriftCompensation())
+		inp := m.NewInput()
+		defer inp.Close()
+
+		frame := make(msdk.PCM16Sample, 161)
+		for i := 0; i < 5000; i++ {
+			// Source clock is slightly faster than the mixer: one extra sample every 5 frames.
+			if i%5 == 0 {
+				inp.WriteSample(frame)
+			} else {
+				inp.WriteSample(frame[:160])
+			}
+			m.mixOnce()
+		}
+		require.Zero(t, m.stats.InputSamplesDropped.Load())
+		require.Zero(t, m.stats.Restarts.Load())
+		require.Greater(t, inp.conv.Speed(), 1.0)
+	})
+
+	t.Run("manual mixing", func(t *testing.T) {
+		var out msdk.PCM16Sample
+		clock := &testClock{now: time.Unix(0, 0)}
+		m, err := NewManualMixer(msdk.NewPCM16BufferWriter(&out, 8000), 20*time.Millisecond, 1, WithClock(clock))
+		require.NoError(t, err)
+		defer m.Stop()
+		inp := m.NewInput()
+		defer inp.Close()
+
+		frame := make(msdk.PCM16Sample, 160)
+		for i := range frame {
+			frame[i] = int16(i)
+		}
+		for i := 0; i < DefaultInputBufferFrames; i++ {
+			inp.WriteSample(frame)
+		}
+
+		m.MixN(2)
+		require.Len(t, out, 2*160)
+		require.Equal(t, frame, out[:160])
+		require.EqualValues(t, 2, m.stats.Mixes.Load())
+
+		m.Update() // first update sets the baseline and mixes a single frame
+		require.Len(t, out, 3*160)
+		clock.Add(40 * time.Millisecond)
+		m.Update()
+		require.Len(t, out, 5*160)
+		require.Equal(t, frame, out[4*160:])
+		require.EqualValues(t, 5, m.stats.OutputFrames.Load())
+		require.EqualValues(t, 2, m.stats.JumpMixes.Load())
+	})
 }
@@ -0,0 +1,307 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ogg implements Ogg container pages (RFC 3533) for a single logical stream.
+package ogg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	headerSize  = 27
+	maxSegments = 255
+
+	flagContinued = 0x01
+	flagBOS       = 0x02
+	flagEOS       = 0x04
+)
+
+var capturePattern = []byte("OggS")
+
+var (
+	ErrInvalidPage = errors.New("invalid ogg page")
+	ErrChecksum    = errors.New("ogg page checksum mismatch")
+)
+
+var crcTable = func() [256]uint32 {
+	var t [256]uint32
+	for i := range t {
+		r := uint32(i) << 24
+		for range 8 {
+			if r&0x80000000 != 0 {
+				r = (r << 1) ^ 0x04c11db7
+			} else {
+				r <<= 1
+			}
+		}
+		t[i] = r
+	}
+	return t
+}()
+
+func crc32(crc uint32, data []byte) uint32 {
+	for _, b := range data {
+		crc = (crc << 8) ^ crcTable[byte(crc>>24)^b]
+	}
+	return crc
+}
+
+// NewWriter creates a writer for a logical Ogg stream with a given serial number.
+func NewWriter(w io.Writer, serial uint32) *Writer {
+	return &Writer{w: w, serial: serial, granule: -1}
+}
+
+// Writer splits packets into Ogg pages.
+// Packets are buffered until Flush is called, or the page is full.
+type Writer struct {
+	w       io.Writer
+	serial  uint32
+	seq     uint32
+	started bool // BOS page was written
+	cont    bool // current page starts with a continued packet
+	ended   bool // at least one packet ends on the current page
+	granule int64
+	segs    []byte
+	data    []byte
+	buf     []byte
+}
+
+// WritePacket adds a packet to the current page. Granule is the position of the stream at the end of this packet.
+func (w *Writer) WritePacket(packet []byte, granule int64) error {
+	for first := true; ; first = false {
+		if len(w.segs) == maxSegments {
+			if err := w.writePage(0); err != nil {
+				return err
+			}
+			// Packet continues on the next page.
+			w.cont = !first
+		}
+		n := min(len(packet), 255)
+		w.segs = append(w.segs, byte(n))
+		w.data = append(w.data, packet[:n]...)
+		packet = packet[n:]
+		if n < 255 {
+			break
+		}
+	}
+	w.granule = granule
+	w.ended = true
+	return nil
+}
+
+// Size returns the size of the data buffered in the current page.
+func (w *Writer) Size() int {
+	return len(w.data)
+}
+
+// Flush writes buffered packets as a page.
+func (w *Writer) Flush() error {
+	if len(w.segs) == 0 {
+		return nil
+	}
+	return w.writePage(0)
+}
+
+// Close writes remaining packets as the last page of the stream. It doesn't close the underlying writer.
+func (w *Writer) Close() error {
+	return w.writePage(flagEOS)
+}
+
+func (w *Writer) writePage(flags byte) error {
+	if !w.started {
+		w.started = true
+		flags |= flagBOS
+	}
+	if w.cont {
+		flags |= flagContinued
+		w.cont = false
+	}
+	granule := w.granule
+	if !w.ended && len(w.segs) > 0 {
+		// No packet ends on this page.
+		granule = -1
+	}
+	w.ended = false
+	b := w.buf[:0]
+	b = append(b, capturePattern...)
+	b = append(b, 0, flags)
+	b = binary.LittleEndian.AppendUint64(b, uint64(granule))
+	b = binary.LittleEndian.AppendUint32(b, w.serial)
+	b = binary.LittleEndian.AppendUint32(b, w.seq)
+	b = binary.LittleEndian.AppendUint32(b, 0) // checksum
+	b = append(b, byte(len(w.segs)))
+	b = append(b, w.segs...)
+	b = append(b, w.data...)
+	binary.LittleEndian.PutUint32(b[22:], crc32(0, b))
+	w.buf = b
+	w.seq++
+	w.segs = w.segs[:0]
+	w.data = w.data[:0]
+	_, err := w.w.Write(b)
+	return err
+}
+
+// Packet is a single packet read from Ogg stream.
+type Packet struct {
+	Data []byte
+	// Granule position of the page, if this packet is the last one that ends on the page. Otherwise, it's -1.
+	Granule int64
+	// BOS is set for packets from the first page of the stream.
+	BOS bool
+	// EOS is set for the last packet of the stream.
+	EOS bool
+}
+
+// NewReader creates a reader for the first logical stream in r. Pages of other streams are ignored.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: r}
+}
+
+type Reader struct {
+	r       io.Reader
+	started bool
+	serial  uint32
+	seq     uint32
+
+	page    []byte
+	segs    []byte
+	granule int64
+	flags   byte
+	partial []byte // packet continued from the previous page
+	skip    bool   // drop the continued packet, since the previous page was lost
+	eos     bool
+}
+
+func (r *Reader) readPage() error {
+	var hdr [headerSize]byte
+	for {
+		if _, err := io.ReadFull(r.r, hdr[:]); err != nil {
+			if errors.Is(err, io.ErrUnexpectedEOF) {
+				err = fmt.Errorf("%w: %w", ErrInvalidPage, err)
+			}
+			return err
+		}
+		if !bytes.Equal(hdr[:4], capturePattern) || hdr[4] != 0 {
+			return ErrInvalidPage
+		}
+		nseg := int(hdr[26])
+		page := make([]byte, headerSize+nseg, headerSize+nseg+nseg*255)
+		copy(page, hdr[:])
+		if _, err := io.ReadFull(r.r, page[headerSize:]); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidPage, err)
+		}
+		size := 0
+		for _, s := range page[headerSize:] {
+			size += int(s)
+		}
+		page = page[:len(page)+size]
+		if _, err := io.ReadFull(r.r, page[headerSize+nseg:]); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidPage, err)
+		}
+		sum := binary.LittleEndian.Uint32(page[22:])
+		binary.LittleEndian.PutUint32(page[22:], 0)
+		if crc32(0, page) != sum {
+			return ErrChecksum
+		}
+		serial := binary.LittleEndian.Uint32(page[14:])
+		if !r.started {
+			r.started = true
+			r.serial = serial
+		} else if serial != r.serial {
+			continue // other logical stream
+		}
+		seq := binary.LittleEndian.Uint32(page[18:])
+		r.flags = page[5]
+		r.granule = int64(binary.LittleEndian.Uint64(page[6:]))
+		r.segs = page[headerSize : headerSize+nseg]
+		r.page = page[headerSize+nseg:]
+		if r.flags&flagContinued == 0 {
+			r.partial = r.partial[:0]
+		} else if seq != r.seq {
+			// Page lost, the continued packet cannot be recovered.
+			r.skip = true
+		}
+		r.seq = seq + 1
+		return nil
+	}
+}
+
+// ReadPacket returns the next packet from the stream. It returns io.EOF after the last packet.
+func (r *Reader) ReadPacket() (Packet, error) {
+	for {
+		if len(r.segs) == 0 {
+			if r.eos {
+				return Packet{}, io.EOF
+			}
+			if err := r.readPage(); err != nil {
+				return Packet{}, err
+			}
+			if len(r.segs) == 0 && r.flags&flagEOS != 0 {
+				r.eos = true
+			}
+			continue
+		}
+		// Collect segments until the packet ends.
+		size, i, done := 0, 0, false
+		for ; i < len(r.segs); i++ {
+			size += int(r.segs[i])
+			if r.segs[i] < 255 {
+				done = true
+				i++
+				break
+			}
+		}
+		r.partial = append(r.partial, r.page[:size]...)
+		r.page = r.page[size:]
+		r.segs = r.segs[i:]
+		if !done {
+			continue
+		}
+		if r.skip {
+			r.skip = false
+			r.partial = r.partial[:0]
+			continue
+		}
+		p := Packet{
+			Data:    bytes.Clone(r.partial),
+			Granule: -1,
+			BOS:     r.flags&flagBOS != 0,
+		}
+		r.partial = r.partial[:0]
+		if !hasPacketEnd(r.segs) {
+			// Last packet ending on this page.
+			p.Granule = r.granule
+			if r.flags&flagEOS != 0 {
+				p.EOS = true
+				r.eos = true
+			}
+		}
+		return p, nil
+	}
+}
+
+// hasPacketEnd checks if any packet ends in the remaining segments.
+func hasPacketEnd(segs []byte) bool {
+	for _, s := range segs {
+		if s < 255 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogg
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func genPacket(n int, v byte) []byte {
+	p := make([]byte, n)
+	for i := range p {
+		p[i] = v + byte(i)
+	}
+	return p
+}
+
+func TestWriteRead(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, 1234)
+
+	packets := [][]byte{
+		genPacket(19, 1),
+		genPacket(100, 2),
+		genPacket(255, 3),
+		genPacket(0, 4),
+		genPacket(255*300, 5), // spans multiple pages
+		genPacket(510, 6),
+	}
+	require.NoError(t, w.WritePacket(packets[0], 0))
+	require.NoError(t, w.Flush())
+	for i, p := range packets[1:] {
+		require.NoError(t, w.WritePacket(p, int64(i+1)*960))
+	}
+	require.NoError(t, w.Close())
+
+	r := NewReader(bytes.NewReader(buf.Bytes()))
+	for i, exp := range packets {
+		p, err := r.ReadPacket()
+		require.NoError(t, err, "packet %d", i)
+		require.Equal(t, exp, p.Data, "packet %d", i)
+		require.Equal(t, i == 0, p.BOS, "packet %d", i)
+		require.Equal(t, i == len(packets)-1, p.EOS, "packet %d", i)
+		switch i {
+		case 0:
+			require.EqualValues(t, 0, p.Granule)
+		case 1, 2, 4:
+			// Other packets end on the same page.
+			require.EqualValues(t, -1, p.Granule)
+		case 3:
+			// Last packet that ends on the second page, packet 4 starts there, but continues on the next one.
+			require.EqualValues(t, 3*960, p.Granule)
+		case 5:
+			require.EqualValues(t, 5*960, p.Granule)
+		}
+	}
+	_, err := r.ReadPacket()
+	require.Equal(t, io.EOF, err)
+}
+
+func TestChecksum(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, 1)
+	require.NoError(t, w.WritePacket([]byte("OpusHead"), 0))
+	require.NoError(t, w.Close())
+
+	data := buf.Bytes()
+	require.Equal(t, "OggS", string(data[:4]))
+
+	data[len(data)-1] ^= 0xff
+	_, err := NewReader(bytes.NewReader(data)).ReadPacket()
+	require.ErrorIs(t, err, ErrChecksum)
+
+	_, err = NewReader(bytes.NewReader([]byte("not an ogg file at all, really"))).ReadPacket()
+	require.ErrorIs(t, err, ErrInvalidPage)
+}
@@ -0,0 +1,285 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+)
+
+const (
+	// OpusGranuleRate is the rate of Ogg Opus granule positions, regardless of the input sample rate.
+	OpusGranuleRate = 48000
+	// OpusPreSkip is the pre-skip value recommended by RFC 7845 for libopus encoder.
+	OpusPreSkip = 312
+	// maxOpusPageDur is the maximal duration of audio in a single page, in granule units.
+	maxOpusPageDur = OpusGranuleRate
+	opusVendor     = "livekit/media-sdk"
+)
+
+var (
+	ErrNotOpus         = errors.New("not an Ogg Opus stream")
+	errInvalidOpusHead = errors.New("invalid OpusHead packet")
+	errInvalidOpusTags = errors.New("invalid OpusTags packet")
+	errInvalidOpus     = errors.New("invalid opus packet")
+	opusHeadSignature  = []byte("OpusHead")
+	opusTagsSignature  = []byte("OpusTags")
+)
+
+// OpusPacketDuration returns the duration of an Opus packet in 48 kHz samples, as described by its TOC byte (RFC 6716, 3.1).
+func OpusPacketDuration(pkt []byte) (int, error) {
+	if len(pkt) == 0 {
+		return 0, errInvalidOpus
+	}
+	toc := pkt[0]
+	var frame int // in 1/400 s units, to fit 2.5 ms CELT frames
+	switch config := toc >> 3; {
+	case config < 12: // SILK: 10, 20, 40, 60 ms
+		frame = [4]int{4, 8, 16, 24}[config%4]
+	case config < 16: // Hybrid: 10, 20 ms
+		frame = [2]int{4, 8}[config%2]
+	default: // CELT: 2.5, 5, 10, 20 ms
+		frame = [4]int{1, 2, 4, 8}[config%4]
+	}
+	var frames int
+	switch toc & 0x3 {
+	case 0:
+		frames = 1
+	case 1, 2:
+		frames = 2
+	default:
+		if len(pkt) < 2 {
+			return 0, errInvalidOpus
+		}
+		frames = int(pkt[1] & 0x3f)
+	}
+	return frames * frame * OpusGranuleRate / 400, nil
+}
+
+// AppendOpusHead appends OpusHead header packet (RFC 7845, 5.1) with channel mapping family 0.
+// It is also used as codec private data for Opus in other containers.
+func AppendOpusHead(b []byte, channels, preSkip, sampleRate int) []byte {
+	b = append(b, opusHeadSignature...)
+	b = append(b, 1, byte(channels)) // version, channels
+	b = binary.LittleEndian.AppendUint16(b, uint16(preSkip))
+	b = binary.LittleEndian.AppendUint32(b, uint32(sampleRate))
+	b = binary.LittleEndian.AppendUint16(b, 0) // output gain
+	b = append(b, 0)                           // channel mapping family
+	return b
+}
+
+func appendOpusTags(b []byte, vendor string) []byte {
+	b = append(b, opusTagsSignature...)
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(vendor)))
+	b = append(b, vendor...)
+	b = binary.LittleEndian.AppendUint32(b, 0) // comments
+	return b
+}
+
+// NewOpusWriter creates a writer for Ogg Opus stream (RFC 7845).
+// Sample rate is the rate of the encoder input, it's only stored in the header. Close will also close w.
+func NewOpusWriter(w io.WriteCloser, sampleRate int, channels int) *OpusWriter {
+	return &OpusWriter{
+		w:          w,
+		ogg:        NewWriter(w, rand.Uint32()),
+		sampleRate: sampleRate,
+		channels:   channels,
+	}
+}
+
+// OpusWriter writes Opus packets to Ogg stream. See NewOpusWriter.
+type OpusWriter struct {
+	w          io.WriteCloser
+	ogg        *Writer
+	sampleRate int
+	channels   int
+	started    bool
+	// Granule positions count all decoded samples, including the pre-skip (RFC 7845, 4).
+	granule   int64
+	pageStart int64
+}
+
+// Channels returns the number of channels in the stream.
+func (w *OpusWriter) Channels() int {
+	return w.channels
+}
+
+// SampleRate returns the sample rate of the encoder input.
+func (w *OpusWriter) SampleRate() int {
+	return w.sampleRate
+}
+
+func (w *OpusWriter) writeHeaders() error {
+	// Both headers must be on separate pages.
+	if err := w.ogg.WritePacket(AppendOpusHead(nil, w.channels, OpusPreSkip, w.sampleRate), 0); err != nil {
+		return err
+	}
+	if err := w.ogg.Flush(); err != nil {
+		return err
+	}
+	if err := w.ogg.WritePacket(appendOpusTags(nil, opusVendor), 0); err != nil {
+		return err
+	}
+	return w.ogg.Flush()
+}
+
+// WritePacket writes a single Opus packet. Empty packets are ignored.
+func (w *OpusWriter) WritePacket(pkt []byte) error {
+	if len(pkt) == 0 {
+		return nil
+	}
+	if !w.started {
+		w.started = true
+		if err := w.writeHeaders(); err != nil {
+			return err
+		}
+	}
+	dur, err := OpusPacketDuration(pkt)
+	if err != nil {
+		return err
+	}
+	w.granule += int64(dur)
+	if err = w.ogg.WritePacket(pkt, w.granule); err != nil {
+		return err
+	}
+	if w.granule-w.pageStart >= maxOpusPageDur {
+		w.pageStart = w.granule
+		return w.ogg.Flush()
+	}
+	return nil
+}
+
+// Close writes the last page and closes the underlying writer.
+func (w *OpusWriter) Close() error {
+	var err error
+	if !w.started {
+		w.started = true
+		err = w.writeHeaders()
+	}
+	if err == nil {
+		err = w.ogg.Close()
+	}
+	return errors.Join(err, w.w.Close())
+}
+
+// NewOpusReader parses Ogg Opus stream headers and returns a reader for Opus packets.
+// Decoder should discard PreSkip samples (at 48 kHz) from the start of the stream.
+func NewOpusReader(r io.Reader) (*OpusReader, error) {
+	or := &OpusReader{r: r, ogg: NewReader(r)}
+	if err := or.readHeaders(); err != nil {
+		return nil, err
+	}
+	return or, nil
+}
+
+// OpusReader reads Opus packets from Ogg stream. See NewOpusReader.
+type OpusReader struct {
+	r          io.Reader
+	ogg        *Reader
+	channels   int
+	preSkip    int
+	sampleRate int
+	vendor     string
+}
+
+// Channels returns the number of channels in the stream.
+func (r *OpusReader) Channels() int {
+	return r.channels
+}
+
+// PreSkip returns the number of samples (at 48 kHz) that must be discarded from the decoder output.
+func (r *OpusReader) PreSkip() int {
+	return r.preSkip
+}
+
+// SampleRate returns the sample rate of the original input, if it was set by the encoder.
+func (r *OpusReader) SampleRate() int {
+	return r.sampleRate
+}
+
+// Vendor returns the vendor string from OpusTags header.
+func (r *OpusReader) Vendor() string {
+	return r.vendor
+}
+
+func (r *OpusReader) readHeaders() error {
+	p, err := r.ogg.ReadPacket()
+	if err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, ErrInvalidPage) {
+			return fmt.Errorf("%w: %w", ErrNotOpus, err)
+		}
+		return err
+	}
+	head := p.Data
+	if !p.BOS || !bytes.HasPrefix(head, opusHeadSignature) {
+		return ErrNotOpus
+	}
+	if len(head) < 19 {
+		return errInvalidOpusHead
+	}
+	if head[8]&0xf0 != 0 {
+		return fmt.Errorf("unsupported Ogg Opus version: %d", head[8])
+	}
+	r.channels = int(head[9])
+	r.preSkip = int(binary.LittleEndian.Uint16(head[10:]))
+	r.sampleRate = int(binary.LittleEndian.Uint32(head[12:]))
+	if r.channels == 0 {
+		return errInvalidOpusHead
+	}
+	if mapping := head[18]; mapping != 0 {
+		return fmt.Errorf("unsupported Ogg Opus channel mapping: %d", mapping)
+	}
+
+	p, err = r.ogg.ReadPacket()
+	if err != nil {
+		return fmt.Errorf("cannot read OpusTags: %w", err)
+	}
+	tags := p.Data
+	if !bytes.HasPrefix(tags, opusTagsSignature) || len(tags) < 12 {
+		return errInvalidOpusTags
+	}
+	n := int(binary.LittleEndian.Uint32(tags[8:]))
+	if len(tags) < 12+n {
+		return errInvalidOpusTags
+	}
+	r.vendor = string(tags[12 : 12+n])
+	return nil
+}
+
+// ReadPacket returns the next Opus packet. It returns io.EOF at the end of the stream.
+func (r *OpusReader) ReadPacket() ([]byte, error) {
+	for {
+		p, err := r.ogg.ReadPacket()
+		if err != nil {
+			return nil, err
+		}
+		if len(p.Data) == 0 {
+			continue
+		}
+		return p.Data, nil
+	}
+}
+
+// Close closes the underlying reader, if it implements io.Closer.
+func (r *OpusReader) Close() error {
+	if c, ok := r.r.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
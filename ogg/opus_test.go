@@ -0,0 +1,97 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ogg
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type nopCloser struct {
+	bytes.Buffer
+}
+
+func (*nopCloser) Close() error { return nil }
+
+func TestOpusPacketDuration(t *testing.T) {
+	for _, c := range []struct {
+		pkt []byte
+		dur int
+	}{
+		{[]byte{0x08 << 3}, 480},          // SILK NB 10 ms
+		{[]byte{0x0b << 3}, 2880},         // SILK WB 60 ms
+		{[]byte{0x0d << 3}, 960},          // Hybrid FB 20 ms
+		{[]byte{0x1c << 3}, 120},          // CELT 2.5 ms
+		{[]byte{0x1f<<3 | 1}, 1920},       // CELT 2x20 ms
+		{[]byte{0x1f<<3 | 3, 0x83}, 2880}, // CELT 3x20 ms, VBR
+		{[]byte{0x1e<<3 | 3, 0x06}, 2880}, // CELT 6x10 ms
+	} {
+		dur, err := OpusPacketDuration(c.pkt)
+		require.NoError(t, err)
+		require.Equal(t, c.dur, dur, "%x", c.pkt)
+	}
+	_, err := OpusPacketDuration(nil)
+	require.Error(t, err)
+	_, err = OpusPacketDuration([]byte{0x03})
+	require.Error(t, err)
+}
+
+func TestOpus(t *testing.T) {
+	var buf nopCloser
+	w := NewOpusWriter(&buf, 16000, 1)
+	var packets [][]byte
+	for i := range 150 {
+		p := []byte{0x1f << 3, byte(i), 1, 2, 3} // CELT 20 ms
+		packets = append(packets, p)
+		require.NoError(t, w.WritePacket(p))
+	}
+	require.NoError(t, w.Close())
+
+	r, err := NewOpusReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, 1, r.Channels())
+	require.Equal(t, 16000, r.SampleRate())
+	require.Equal(t, OpusPreSkip, r.PreSkip())
+	require.Equal(t, opusVendor, r.Vendor())
+
+	for i, exp := range packets {
+		p, err := r.ReadPacket()
+		require.NoError(t, err, "packet %d", i)
+		require.Equal(t, exp, p, "packet %d", i)
+	}
+	_, err = r.ReadPacket()
+	require.Equal(t, io.EOF, err)
+
+	// Granule positions count all decoded samples, including the pre-skip.
+	or := NewReader(bytes.NewReader(buf.Bytes()))
+	var granule int64
+	for {
+		p, err := or.ReadPacket()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		if p.Granule >= 0 {
+			granule = p.Granule
+		}
+	}
+	require.EqualValues(t, 150*960, granule)
+
+	_, err = NewOpusReader(bytes.NewReader([]byte("RIFF....WAVE")))
+	require.ErrorIs(t, err, ErrNotOpus)
+}
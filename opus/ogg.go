@@ -0,0 +1,85 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package opus
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/livekit/media-sdk"
+	"github.com/livekit/media-sdk/ogg"
+)
+
+// ErrNotOggOpus is returned by NewOggReader for streams without Opus headers.
+var ErrNotOggOpus = ogg.ErrNotOpus
+
+// PacketDuration returns the duration of an Opus packet in 48 kHz samples. See ogg.OpusPacketDuration.
+func PacketDuration(pkt []byte) (int, error) {
+	return ogg.OpusPacketDuration(pkt)
+}
+
+// NewOggWriter creates a writer for Ogg Opus stream (RFC 7845). See ogg.NewOpusWriter.
+// Sample rate is the rate of the encoder input, it's only stored in the header. Close will also close w.
+func NewOggWriter(w io.WriteCloser, sampleRate int, channels int) media.WriteCloser[Sample] {
+	return &oggWriter{w: ogg.NewOpusWriter(w, sampleRate, channels)}
+}
+
+type oggWriter struct {
+	w *ogg.OpusWriter
+}
+
+func (w *oggWriter) String() string {
+	return fmt.Sprintf("OggOpus(%d,%d)", w.w.Channels(), w.w.SampleRate())
+}
+
+func (w *oggWriter) SampleRate() int {
+	return w.w.SampleRate()
+}
+
+func (w *oggWriter) WriteSample(sample Sample) error {
+	return w.w.WritePacket(sample)
+}
+
+func (w *oggWriter) Close() error {
+	return w.w.Close()
+}
+
+// NewOggReader parses Ogg Opus stream headers and returns a reader for Opus packets.
+// Packets can be passed to Decode. Decoder should discard PreSkip samples (at 48 kHz) from the start of the stream.
+func NewOggReader(r io.Reader) (*OggReader, error) {
+	or, err := ogg.NewOpusReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return &OggReader{OpusReader: or}, nil
+}
+
+type OggReader struct {
+	*ogg.OpusReader
+}
+
+// ReadPacket returns the next Opus packet. It returns io.EOF at the end of the stream.
+func (r *OggReader) ReadPacket() (Sample, error) {
+	return r.OpusReader.ReadPacket()
+}
+
+// ReadSample implements media.Reader. The buffer must be large enough to fit the whole packet.
+func (r *OggReader) ReadSample(buf Sample) (int, error) {
+	p, err := r.ReadPacket()
+	if err != nil {
+		return 0, err
+	}
+	return p.CopyTo(buf)
+}
@@ -0,0 +1,60 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package opus
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type nopCloser struct {
+	bytes.Buffer
+}
+
+func (*nopCloser) Close() error { return nil }
+
+func TestOgg(t *testing.T) {
+	var buf nopCloser
+	w := NewOggWriter(&buf, 16000, 1)
+	var packets []Sample
+	for i := range 150 {
+		p := Sample{0x1f << 3, byte(i), 1, 2, 3} // CELT 20 ms
+		packets = append(packets, p)
+		require.NoError(t, w.WriteSample(p))
+	}
+	require.NoError(t, w.Close())
+
+	r, err := NewOggReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, 1, r.Channels())
+	require.Equal(t, 16000, r.SampleRate())
+	require.Equal(t, 312, r.PreSkip())
+	require.Equal(t, "livekit/media-sdk", r.Vendor())
+
+	sample := make(Sample, 100)
+	for i, exp := range packets {
+		n, err := r.ReadSample(sample)
+		require.NoError(t, err, "packet %d", i)
+		require.Equal(t, exp, sample[:n], "packet %d", i)
+	}
+	_, err = r.ReadSample(sample)
+	require.Equal(t, io.EOF, err)
+
+	_, err = NewOggReader(bytes.NewReader([]byte("RIFF....WAVE")))
+	require.ErrorIs(t, err, ErrNotOggOpus)
+}
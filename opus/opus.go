@@ -25,6 +25,7 @@ import (
 	"github.com/livekit/protocol/logger"
 
 	"github.com/livekit/media-sdk"
+	"github.com/livekit/media-sdk/ogg"
 	"github.com/livekit/media-sdk/rtp"
 	"github.com/livekit/media-sdk/webm"
 )
@@ -189,5 +190,27 @@ func (e *encoder) Close() error {
 }
 
 func NewWebmWriter(w io.WriteCloser, sampleRate int, channels int, sampleDur time.Duration) media.WriteCloser[Sample] {
-	return webm.NewWriter[Sample](w, "A_OPUS", channels, sampleRate, sampleDur)
+	return webm.NewWriter[Sample](w, webm.CodecOpus, channels, sampleRate, sampleDur)
+}
+
+// WebmTrack returns parameters of Opus track for webm.NewTrackWriter.
+func WebmTrack(name string, sampleRate int, channels int) webm.Track {
+	return webm.Track{
+		Name:         name,
+		Codec:        webm.CodecOpus,
+		CodecPrivate: ogg.AppendOpusHead(nil, channels, ogg.OpusPreSkip, sampleRate),
+		SampleRate:   sampleRate,
+		Channels:     channels,
+	}
+}
+
+// NewWebmReader creates a reader for the first Opus track in WebM file, for example written by NewWebmWriter.
+func NewWebmReader(r io.Reader) (*webm.SampleReader[Sample, byte], error) {
+	dr, err := webm.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return webm.NewSampleReader(dr, webm.CodecOpus, func(data []byte) (Sample, error) {
+		return data, nil
+	})
 }
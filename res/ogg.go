@@ -0,0 +1,131 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package res
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/jfreymuth/oggvorbis"
+
+	"github.com/livekit/media-sdk"
+)
+
+// NewOggReader decodes Ogg Vorbis stream from r.
+// Samples are converted to the requested sample rate and number of channels.
+func NewOggReader(r io.Reader, sampleRate int, channels int) (*OggReader, error) {
+	if sampleRate <= 0 || channels <= 0 {
+		return nil, fmt.Errorf("invalid output format: %d channels at %d Hz", channels, sampleRate)
+	}
+	or, err := oggvorbis.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read ogg vorbis: %w", err)
+	}
+	if or.SampleRate() <= 0 || or.Channels() <= 0 {
+		return nil, fmt.Errorf("unsupported ogg vorbis format: %d channels at %d Hz", or.Channels(), or.SampleRate())
+	}
+	d := &vorbisDecoder{src: r, r: or}
+	return &OggReader{
+		srcRate:     or.SampleRate(),
+		srcChannels: or.Channels(),
+		sampleRate:  sampleRate,
+		channels:    channels,
+		r:           media.ConvertReader(d, or.SampleRate(), or.Channels(), sampleRate, channels),
+	}, nil
+}
+
+type OggReader struct {
+	srcRate     int
+	srcChannels int
+	sampleRate  int
+	channels    int
+	r           media.ReadCloser[media.PCM16Sample]
+}
+
+// SourceFormat returns the sample rate and the number of channels of the source stream.
+func (r *OggReader) SourceFormat() (sampleRate, channels int) {
+	return r.srcRate, r.srcChannels
+}
+
+func (r *OggReader) SampleRate() int {
+	return r.sampleRate
+}
+
+func (r *OggReader) Channels() int {
+	return r.channels
+}
+
+func (r *OggReader) ReadSample(buf media.PCM16Sample) (int, error) {
+	return r.r.ReadSample(buf)
+}
+
+// Close closes the source reader, if it implements io.Closer.
+func (r *OggReader) Close() error {
+	return r.r.Close()
+}
+
+// ReadOggAudio decodes the whole Ogg Vorbis stream and splits it into frames for media.PlayAudio.
+func ReadOggAudio(r io.Reader, sampleRate int, channels int) ([]media.PCM16Sample, error) {
+	or, err := NewOggReader(r, sampleRate, channels)
+	if err != nil {
+		return nil, err
+	}
+	defer or.Close()
+	perFrame := max(sampleRate/media.DefFramesPerSec, 1) * channels
+	var frames []media.PCM16Sample
+	for {
+		frame := make(media.PCM16Sample, perFrame)
+		n, err := or.ReadSample(frame)
+		if n != 0 {
+			frames = append(frames, frame[:n])
+		}
+		if errors.Is(err, io.EOF) {
+			return frames, nil
+		} else if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// vorbisDecoder reads interleaved samples from Vorbis decoder.
+type vorbisDecoder struct {
+	src io.Reader
+	r   *oggvorbis.Reader
+	buf []float32
+}
+
+func (d *vorbisDecoder) ReadSample(out media.PCM16Sample) (int, error) {
+	if cap(d.buf) < len(out) {
+		d.buf = make([]float32, len(out))
+	}
+	buf := d.buf[:len(out)]
+	n, err := d.r.Read(buf)
+	for i, v := range buf[:n] {
+		// Decoder already clamps the values to [-1, 1].
+		out[i] = int16(v * 0x7fff)
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		err = fmt.Errorf("cannot decode ogg vorbis: %w", err)
+	}
+	return n, err
+}
+
+func (d *vorbisDecoder) Close() error {
+	if c, ok := d.src.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
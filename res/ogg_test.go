@@ -0,0 +1,82 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package res
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/media-sdk"
+)
+
+func readAll(t testing.TB, r media.Reader[media.PCM16Sample]) media.PCM16Sample {
+	var out media.PCM16Sample
+	buf := make(media.PCM16Sample, 333)
+	for {
+		n, err := r.ReadSample(buf)
+		out = append(out, buf[:n]...)
+		if err == io.EOF {
+			return out
+		}
+		require.NoError(t, err)
+	}
+}
+
+func TestOggReader(t *testing.T) {
+	r, err := NewOggReader(bytes.NewReader(EnterPinOgg), SampleRate, 1)
+	require.NoError(t, err)
+	srcRate, srcChannels := r.SourceFormat()
+	require.Equal(t, SampleRate, srcRate)
+	require.Equal(t, 1, srcChannels)
+	orig := readAll(t, r)
+	require.NoError(t, r.Close())
+	require.NotEmpty(t, orig)
+
+	frames := ReadOggAudioFile(EnterPinOgg, SampleRate, 1)
+	var joined media.PCM16Sample
+	for i, f := range frames {
+		if i != len(frames)-1 {
+			require.Len(t, f, SampleRate/media.DefFramesPerSec)
+		}
+		joined = append(joined, f...)
+	}
+	require.Equal(t, orig, joined)
+
+	t.Run("convert", func(t *testing.T) {
+		r, err := NewOggReader(bytes.NewReader(EnterPinOgg), 8000, 2)
+		require.NoError(t, err)
+		out := readAll(t, r)
+		require.Zero(t, len(out)%2)
+		require.InEpsilon(t, len(orig)/6, len(out)/2, 0.01)
+		for i := 0; i < len(out); i += 2 {
+			require.Equal(t, out[i], out[i+1])
+		}
+
+		frames, err := ReadOggAudio(bytes.NewReader(EnterPinOgg), 8000, 2)
+		require.NoError(t, err)
+		require.Len(t, frames[0], 8000/media.DefFramesPerSec*2)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := NewOggReader(bytes.NewReader([]byte("not an ogg file")), 8000, 1)
+		require.Error(t, err)
+
+		_, err = ReadOggAudio(bytes.NewReader(EnterPinOgg[:len(EnterPinOgg)/2]), 8000, 1)
+		require.Error(t, err)
+	})
+}
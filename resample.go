@@ -21,10 +21,26 @@ import (
 )
 
 var (
-	resampleID         atomic.Uint32
-	resampleDumpToFile = os.Getenv("LK_DUMP_RESAMPLE") == "true"
+	resampleID     atomic.Uint32
+	resampleDumper atomic.Pointer[Dumper]
 )
 
+func init() {
+	if os.Getenv("LK_DUMP_RESAMPLE") == "true" {
+		SetResampleDumper(NewRawDumper(""))
+	}
+}
+
+// SetResampleDumper enables dumps of resampler input and output for all new resamplers. Nil disables the dumps.
+// It is enabled by LK_DUMP_RESAMPLE=true environment variable by default.
+func SetResampleDumper(d Dumper) {
+	if d == nil {
+		resampleDumper.Store(nil)
+		return
+	}
+	resampleDumper.Store(&d)
+}
+
 // Resample the source sample into the destination sample rate.
 // It appends resulting samples to dst and returns the result.
 func Resample(dst PCM16Sample, dstSampleRate int, src PCM16Sample, srcSampleRate int) PCM16Sample {
@@ -43,12 +59,17 @@ func ResampleWriter(w PCM16Writer, sampleRate int) (w2 PCM16Writer) {
 		return w
 	}
 
-	if resampleDumpToFile {
+	if d := resampleDumper.Load(); d != nil {
 		id := resampleID.Add(1)
 		pref := fmt.Sprintf("sip_resample_%d", id)
-		w = DumpWriterPCM16(pref+"_out", w)
+		// Dumps are optional, ignore errors.
+		if dw, err := DumpTo(*d, DumpStream{Name: pref + "_out"}, w); err == nil {
+			w = dw
+		}
 		defer func() {
-			w2 = DumpWriterPCM16(pref+"_in", w2)
+			if dw, err := DumpTo(*d, DumpStream{Name: pref + "_in", SampleRate: srcRate}, w2); err == nil {
+				w2 = dw
+			}
 		}()
 	}
 	return newResampleWriter(w, sampleRate)
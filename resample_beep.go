@@ -16,6 +16,8 @@
 
 package media
 
+import "fmt"
+
 const quality = 3
 
 func resampleBuffer(dst PCM16Sample, dstSampleRate int, src PCM16Sample, srcSampleRate int) PCM16Sample {
@@ -54,6 +56,10 @@ type resampleWriter struct {
 	buf     PCM16Sample
 }
 
+func (w *resampleWriter) String() string {
+	return fmt.Sprintf("Resample(%d->%d) -> %s", w.srcRate, w.dstRate, w.w.String())
+}
+
 func (w *resampleWriter) SampleRate() int {
 	return w.srcRate
 }
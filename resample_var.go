@@ -0,0 +1,127 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package media
+
+import (
+	"fmt"
+	"math"
+	"sync"
+)
+
+const varResampleQuality = 3
+
+// VarResampleWriter returns a new writer that expects samples of a given sample rate
+// and resamples them for the destination writer.
+//
+// Unlike ResampleWriter, the conversion ratio can be adjusted on the fly with SetSpeed,
+// which allows compensating for a clock drift between the source and the destination.
+func VarResampleWriter(w PCM16Writer, sampleRate int) *VarResampler {
+	if sampleRate <= 0 {
+		panic("invalid sample rate")
+	}
+	r := &VarResampler{
+		w:       w,
+		srcRate: sampleRate,
+		dstRate: w.SampleRate(),
+		speed:   1,
+	}
+	r.r = beepResample(varResampleQuality, r.srcRate, r.dstRate, &r.in)
+	return r
+}
+
+type VarResampler struct {
+	mu      sync.Mutex
+	w       PCM16Writer
+	r       *beepResampler
+	in      bufferReaderPCM16
+	srcRate int
+	dstRate int
+	speed   float64
+	buf     PCM16Sample
+}
+
+func (w *VarResampler) String() string {
+	return fmt.Sprintf("VarResample(%d->%d) -> %s", w.srcRate, w.dstRate, w.w.String())
+}
+
+func (w *VarResampler) SampleRate() int {
+	return w.srcRate
+}
+
+// Speed returns current speed of the resampler. See SetSpeed.
+func (w *VarResampler) Speed() float64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.speed
+}
+
+// SetSpeed adjusts the conversion ratio relative to the nominal one.
+// Speed above 1 consumes the source faster, thus producing fewer samples. Speed below 1 produces more samples.
+// The change is applied without any discontinuities in the output.
+func (w *VarResampler) SetSpeed(speed float64) {
+	if speed <= 0 || math.IsInf(speed, 0) || math.IsNaN(speed) {
+		panic(fmt.Errorf("resample: invalid speed: %f", speed))
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.speed == speed {
+		return
+	}
+	w.speed = speed
+	w.r.SetRatio(float64(w.srcRate) / float64(w.dstRate) * speed)
+}
+
+// available returns the number of output samples that can be produced from the buffered input,
+// without interpolating past the end of it.
+func (w *VarResampler) available() int {
+	total := len(w.in.buf)
+	if !w.r.first {
+		total += w.r.off + len(w.r.buf2)
+	}
+	// Interpolation uses this many samples after the current position.
+	total -= len(w.r.pts) / 2
+	n := 0
+	for int(w.r.srcPos(n)) < total {
+		n++
+	}
+	return n
+}
+
+func (w *VarResampler) WriteSample(data PCM16Sample) error {
+	if len(data) == 0 {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.in.buf = append(w.in.buf, data...)
+	sz := w.available()
+	if sz == 0 {
+		return nil
+	}
+	if cap(w.buf) < sz {
+		w.buf = make(PCM16Sample, sz)
+	} else {
+		w.buf = w.buf[:sz]
+	}
+	n, _ := w.r.Stream(w.buf)
+	if n == 0 {
+		return nil
+	}
+	return w.w.WriteSample(w.buf[:n])
+}
+
+func (w *VarResampler) Close() error {
+	return w.w.Close()
+}
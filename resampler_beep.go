@@ -1,5 +1,3 @@
-//go:build !cgo
-
 // MIT License
 //
 // Copyright (c) 2017 Michal Štrba
@@ -100,6 +98,7 @@ type beepResampler struct {
 	pts        []point             // pts is for points used for interpolation
 	off        int                 // off is the position of the start of buf2 in the original data
 	pos        int                 // pos is the current position in the resampled data
+	start      float64             // start is the position in the original data where ratio was last changed
 }
 
 // Stream streams the original audio resampled according to the current ratio.
@@ -114,7 +113,7 @@ func (r *beepResampler) Stream(samples PCM16Sample) (n int, ok bool) {
 	for len(samples) > 0 {
 	again:
 		// calculate the current position in the original data
-		j := float64(r.pos) * r.ratio
+		j := r.srcPos(0)
 
 		// find quality*2 closest samples to j and translate them to points for interpolation
 		for pi := range r.pts {
@@ -131,8 +130,8 @@ func (r *beepResampler) Stream(samples PCM16Sample) (n int, ok bool) {
 				y = r.buf2[k-r.off]
 			// the sample is beyond buf2, so we need to load new data
 			case k >= r.off+len(r.buf2):
-				// we load into buf1
-				sn, _ := r.s.ReadSample(r.buf1)
+				// we load into buf1, using all of its capacity, since it might have been shortened by the previous read
+				sn, _ := r.s.ReadSample(r.buf1[:cap(r.buf1)])
 				// this condition happens when the original Streamer got
 				// drained and j is after the end of the
 				// original data
@@ -167,6 +166,11 @@ func (r *beepResampler) Stream(samples PCM16Sample) (n int, ok bool) {
 	return n, true
 }
 
+// srcPos returns a position in the original data for the n-th sample after the current one.
+func (r *beepResampler) srcPos(n int) float64 {
+	return r.start + float64(r.pos+n)*r.ratio
+}
+
 // Ratio returns the current resampling ratio.
 func (r *beepResampler) Ratio() float64 {
 	return r.ratio
@@ -177,7 +181,9 @@ func (r *beepResampler) SetRatio(ratio float64) {
 	if math.IsInf(ratio, 0) || math.IsNaN(ratio) {
 		panic(fmt.Errorf("resample: invalid ratio: %f", ratio))
 	}
-	r.pos = int(float64(r.pos) * r.ratio / ratio)
+	// keep the exact position in the original data, instead of scaling an integer position
+	r.start = r.srcPos(0)
+	r.pos = 0
 	r.ratio = ratio
 }
 
@@ -0,0 +1,587 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"sync"
+	"time"
+
+	"github.com/pion/rtp"
+)
+
+// CaptureFormat is a file format for RTP captures.
+type CaptureFormat int
+
+const (
+	// CapturePcap writes packets to pcap file with synthetic IP/UDP headers.
+	CapturePcap CaptureFormat = iota
+	// CaptureRTPDump writes packets in rtpdump format used by rtptools (rtpplay, rtpdump).
+	CaptureRTPDump
+)
+
+const (
+	pcapMagicMicro = 0xa1b2c3d4
+	pcapMagicNano  = 0xa1b23c4d
+	pcapSnapLen    = 65535
+
+	linkTypeNull     = 0
+	linkTypeEthernet = 1
+	linkTypeRaw      = 101
+	linkTypeLinuxSLL = 113
+
+	rtpDumpMagic = "#!rtpplay1.0 "
+)
+
+var (
+	ErrUnknownCapture = errors.New("unknown capture file format")
+
+	defCaptureSrc = netip.MustParseAddrPort("127.0.0.1:5004")
+	defCaptureDst = netip.MustParseAddrPort("127.0.0.1:5006")
+)
+
+type CaptureOption func(*CaptureWriter)
+
+// WithCaptureAddrs sets addresses used in the capture. Addresses are not known when capturing from a Handler,
+// so by default packets are recorded as sent between two ports on a loopback interface.
+// Pcap captures use IPv6 headers if any of the addresses is IPv6.
+func WithCaptureAddrs(src, dst netip.AddrPort) CaptureOption {
+	return func(w *CaptureWriter) {
+		w.src, w.dst = src, dst
+	}
+}
+
+// NewCaptureWriter creates a writer that records RTP packets to a pcap or rtpdump file.
+// It is safe to use from multiple goroutines.
+func NewCaptureWriter(w io.Writer, format CaptureFormat, opts ...CaptureOption) *CaptureWriter {
+	c := &CaptureWriter{
+		w:      w,
+		bw:     bufio.NewWriter(w),
+		format: format,
+		src:    defCaptureSrc,
+		dst:    defCaptureDst,
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// CaptureWriter records RTP packets with their arrival time.
+type CaptureWriter struct {
+	mu      sync.Mutex
+	w       io.Writer
+	bw      *bufio.Writer
+	format  CaptureFormat
+	src     netip.AddrPort
+	dst     netip.AddrPort
+	started bool
+	start   time.Time
+	buf     []byte
+	err     error
+}
+
+func (w *CaptureWriter) writeHeader(at time.Time) error {
+	var b []byte
+	switch w.format {
+	case CapturePcap:
+		b = binary.LittleEndian.AppendUint32(b, pcapMagicNano)
+		b = binary.LittleEndian.AppendUint16(b, 2) // version
+		b = binary.LittleEndian.AppendUint16(b, 4)
+		b = binary.LittleEndian.AppendUint32(b, 0) // time zone
+		b = binary.LittleEndian.AppendUint32(b, 0) // accuracy
+		b = binary.LittleEndian.AppendUint32(b, pcapSnapLen)
+		b = binary.LittleEndian.AppendUint32(b, linkTypeRaw)
+	case CaptureRTPDump:
+		b = fmt.Appendf(b, "%s%s/%d\n", rtpDumpMagic, w.dst.Addr(), w.dst.Port())
+		b = binary.BigEndian.AppendUint32(b, uint32(at.Unix()))
+		b = binary.BigEndian.AppendUint32(b, uint32(at.Nanosecond()/1000))
+		var src uint32
+		if a := w.src.Addr().Unmap(); a.Is4() {
+			src = binary.BigEndian.Uint32(a.AsSlice())
+		}
+		b = binary.BigEndian.AppendUint32(b, src)
+		b = binary.BigEndian.AppendUint16(b, w.src.Port())
+		b = binary.BigEndian.AppendUint16(b, 0) // padding
+	default:
+		return fmt.Errorf("unsupported capture format: %d", w.format)
+	}
+	_, err := w.bw.Write(b)
+	return err
+}
+
+// WritePacket records a packet received or sent at a given time.
+func (w *CaptureWriter) WritePacket(at time.Time, h *rtp.Header, payload []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return w.err
+	}
+	if !w.started {
+		w.started = true
+		w.start = at
+		if w.err = w.writeHeader(at); w.err != nil {
+			return w.err
+		}
+	}
+	hsz := h.MarshalSize()
+	pkt := make([]byte, hsz+len(payload))
+	if _, w.err = h.MarshalTo(pkt); w.err != nil {
+		return w.err
+	}
+	copy(pkt[hsz:], payload)
+
+	b := w.buf[:0]
+	switch w.format {
+	case CapturePcap:
+		data := appendUDP(nil, w.src, w.dst, pkt)
+		b = binary.LittleEndian.AppendUint32(b, uint32(at.Unix()))
+		b = binary.LittleEndian.AppendUint32(b, uint32(at.Nanosecond()))
+		b = binary.LittleEndian.AppendUint32(b, uint32(len(data)))
+		b = binary.LittleEndian.AppendUint32(b, uint32(len(data)))
+		b = append(b, data...)
+	case CaptureRTPDump:
+		b = binary.BigEndian.AppendUint16(b, uint16(8+len(pkt)))
+		b = binary.BigEndian.AppendUint16(b, uint16(len(pkt)))
+		b = binary.BigEndian.AppendUint32(b, uint32(max(0, at.Sub(w.start).Milliseconds())))
+		b = append(b, pkt...)
+	}
+	w.buf = b
+	_, w.err = w.bw.Write(b)
+	return w.err
+}
+
+// Flush writes buffered packets to the underlying writer.
+func (w *CaptureWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return w.err
+	}
+	w.err = w.bw.Flush()
+	return w.err
+}
+
+// Close flushes buffered packets and closes the underlying writer, if it implements io.Closer.
+func (w *CaptureWriter) Close() error {
+	err := w.Flush()
+	if c, ok := w.w.(io.Closer); ok {
+		err = errors.Join(err, c.Close())
+	}
+	return err
+}
+
+// appendUDP appends IP and UDP headers with a given payload.
+// If only one of the addresses is IPv4, both are written as IPv6, with IPv4 address mapped to IPv6.
+func appendUDP(b []byte, src, dst netip.AddrPort, payload []byte) []byte {
+	srcIP, dstIP := src.Addr().Unmap(), dst.Addr().Unmap()
+	udpLen := 8 + len(payload)
+	start := len(b)
+	var pseudo []byte
+	if !srcIP.Is4() || !dstIP.Is4() {
+		srcIP, dstIP = netip.AddrFrom16(srcIP.As16()), netip.AddrFrom16(dstIP.As16())
+	}
+	if srcIP.Is4() {
+		b = append(b, 0x45, 0) // version, IHL, DSCP
+		b = binary.BigEndian.AppendUint16(b, uint16(20+udpLen))
+		b = append(b, 0, 0, 0x40, 0) // ID, don't fragment
+		b = append(b, 64, 17, 0, 0)  // TTL, UDP, checksum
+		b = append(b, srcIP.AsSlice()...)
+		b = append(b, dstIP.AsSlice()...)
+		binary.BigEndian.PutUint16(b[start+10:], checksum(0, b[start:]))
+		pseudo = append(pseudo, srcIP.AsSlice()...)
+		pseudo = append(pseudo, dstIP.AsSlice()...)
+		pseudo = append(pseudo, 0, 17)
+		pseudo = binary.BigEndian.AppendUint16(pseudo, uint16(udpLen))
+	} else {
+		b = append(b, 0x60, 0, 0, 0)
+		b = binary.BigEndian.AppendUint16(b, uint16(udpLen))
+		b = append(b, 17, 64) // UDP, hop limit
+		b = append(b, srcIP.AsSlice()...)
+		b = append(b, dstIP.AsSlice()...)
+		pseudo = append(pseudo, b[start+8:start+40]...)
+		pseudo = binary.BigEndian.AppendUint32(pseudo, uint32(udpLen))
+		pseudo = append(pseudo, 0, 0, 0, 17)
+	}
+	udp := len(b)
+	b = binary.BigEndian.AppendUint16(b, src.Port())
+	b = binary.BigEndian.AppendUint16(b, dst.Port())
+	b = binary.BigEndian.AppendUint16(b, uint16(udpLen))
+	b = append(b, 0, 0) // checksum
+	b = append(b, payload...)
+	sum := checksum(checksumAdd(0, pseudo), b[udp:])
+	if sum == 0 {
+		sum = 0xffff
+	}
+	binary.BigEndian.PutUint16(b[udp+6:], sum)
+	return b
+}
+
+func checksumAdd(sum uint32, data []byte) uint32 {
+	for i := 0; i+1 < len(data); i += 2 {
+		sum += uint32(binary.BigEndian.Uint16(data[i:]))
+	}
+	if len(data)%2 != 0 {
+		sum += uint32(data[len(data)-1]) << 8
+	}
+	return sum
+}
+
+func checksum(sum uint32, data []byte) uint16 {
+	sum = checksumAdd(sum, data)
+	for sum>>16 != 0 {
+		sum = sum&0xffff + sum>>16
+	}
+	return ^uint16(sum)
+}
+
+// CaptureHandler returns a Handler that records all packets before passing them to h.
+// Capture errors are ignored, they are reported by CaptureWriter.Flush and Close.
+func CaptureHandler(h Handler, c *CaptureWriter) Handler {
+	return &captureHandler{h: h, c: c}
+}
+
+type captureHandler struct {
+	h Handler
+	c *CaptureWriter
+}
+
+func (h *captureHandler) String() string {
+	return "Capture -> " + h.h.String()
+}
+
+func (h *captureHandler) HandleRTP(hdr *rtp.Header, payload []byte) error {
+	_ = h.c.WritePacket(time.Now(), hdr, payload)
+	return h.h.HandleRTP(hdr, payload)
+}
+
+// CaptureWrites returns a Writer that records all packets before writing them to w.
+func CaptureWrites(w Writer, c *CaptureWriter) Writer {
+	return &captureWriter{w: w, c: c}
+}
+
+type captureWriter struct {
+	w Writer
+	c *CaptureWriter
+}
+
+func (w *captureWriter) String() string {
+	return "Capture -> " + w.w.String()
+}
+
+func (w *captureWriter) WriteRTP(h *rtp.Header, payload []byte) (int, error) {
+	_ = w.c.WritePacket(time.Now(), h, payload)
+	return w.w.WriteRTP(h, payload)
+}
+
+// CaptureSession wraps the session and records all received packets to in and all sent packets to out.
+// Either of the capture writers can be nil.
+func CaptureSession(s Session, in, out *CaptureWriter) Session {
+	return &captureSession{Session: s, in: in, out: out}
+}
+
+type captureSession struct {
+	Session
+	in, out *CaptureWriter
+}
+
+func (s *captureSession) OpenWriteStream() (WriteStream, error) {
+	w, err := s.Session.OpenWriteStream()
+	if err != nil || s.out == nil {
+		return w, err
+	}
+	return &captureWriter{w: w, c: s.out}, nil
+}
+
+func (s *captureSession) AcceptStream() (ReadStream, uint32, error) {
+	r, ssrc, err := s.Session.AcceptStream()
+	if err != nil || s.in == nil {
+		return r, ssrc, err
+	}
+	return &captureReadStream{r: r, c: s.in}, ssrc, nil
+}
+
+type captureReadStream struct {
+	r ReadStream
+	c *CaptureWriter
+}
+
+func (r *captureReadStream) ReadRTP(h *rtp.Header, payload []byte) (int, error) {
+	n, err := r.r.ReadRTP(h, payload)
+	if err == nil {
+		_ = r.c.WritePacket(time.Now(), h, payload[:n])
+	}
+	return n, err
+}
+
+// CapturedPacket is an RTP packet read from a capture file.
+type CapturedPacket struct {
+	Time time.Time
+	rtp.Packet
+}
+
+// NewCaptureReader creates a reader for pcap or rtpdump capture. Format is detected automatically.
+// Only UDP packets that look like RTP are returned from pcap files, RTCP and other traffic is skipped.
+func NewCaptureReader(r io.Reader) (*CaptureReader, error) {
+	cr := &CaptureReader{r: bufio.NewReader(r)}
+	if err := cr.readHeader(); err != nil {
+		return nil, err
+	}
+	return cr, nil
+}
+
+// CaptureReader reads RTP packets from a capture file.
+type CaptureReader struct {
+	r        *bufio.Reader
+	format   CaptureFormat
+	order    binary.ByteOrder
+	nano     bool
+	linkType uint32
+	start    time.Time
+}
+
+// Format returns the format of the capture file.
+func (r *CaptureReader) Format() CaptureFormat {
+	return r.format
+}
+
+func (r *CaptureReader) readHeader() error {
+	magic, err := r.r.Peek(4)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrUnknownCapture, err)
+	}
+	if bytes.Equal(magic, []byte(rtpDumpMagic[:4])) {
+		r.format = CaptureRTPDump
+		line, err := r.r.ReadString('\n')
+		if err != nil || len(line) > 256 {
+			return ErrUnknownCapture
+		}
+		var hdr [16]byte
+		if _, err = io.ReadFull(r.r, hdr[:]); err != nil {
+			return fmt.Errorf("%w: %w", ErrUnknownCapture, err)
+		}
+		r.order = binary.BigEndian
+		r.start = time.Unix(int64(r.order.Uint32(hdr[0:])), int64(r.order.Uint32(hdr[4:]))*1000)
+		return nil
+	}
+	r.format = CapturePcap
+	switch {
+	case binary.LittleEndian.Uint32(magic) == pcapMagicMicro:
+		r.order = binary.LittleEndian
+	case binary.LittleEndian.Uint32(magic) == pcapMagicNano:
+		r.order, r.nano = binary.LittleEndian, true
+	case binary.BigEndian.Uint32(magic) == pcapMagicMicro:
+		r.order = binary.BigEndian
+	case binary.BigEndian.Uint32(magic) == pcapMagicNano:
+		r.order, r.nano = binary.BigEndian, true
+	default:
+		return ErrUnknownCapture
+	}
+	var hdr [24]byte
+	if _, err = io.ReadFull(r.r, hdr[:]); err != nil {
+		return fmt.Errorf("%w: %w", ErrUnknownCapture, err)
+	}
+	r.linkType = r.order.Uint32(hdr[20:]) & 0xffff
+	switch r.linkType {
+	case linkTypeNull, linkTypeEthernet, linkTypeRaw, linkTypeLinuxSLL:
+	default:
+		return fmt.Errorf("unsupported pcap link type: %d", r.linkType)
+	}
+	return nil
+}
+
+// ReadPacket returns the next RTP packet from the capture. It returns io.EOF at the end of the file.
+func (r *CaptureReader) ReadPacket() (*CapturedPacket, error) {
+	for {
+		at, data, err := r.readRecord()
+		if err != nil {
+			return nil, err
+		}
+		if r.format == CapturePcap {
+			if data = udpPayload(data, r.linkType); data == nil {
+				continue
+			}
+			if len(data) < 12 || data[0]>>6 != 2 || (data[1] >= 192 && data[1] <= 223) {
+				continue // not RTP, or RTCP
+			}
+		}
+		p := &CapturedPacket{Time: at}
+		if err = p.Unmarshal(data); err != nil {
+			if r.format == CapturePcap {
+				continue
+			}
+			return nil, err
+		}
+		return p, nil
+	}
+}
+
+func (r *CaptureReader) readRecord() (time.Time, []byte, error) {
+	switch r.format {
+	case CaptureRTPDump:
+		var hdr [8]byte
+		if _, err := io.ReadFull(r.r, hdr[:]); err != nil {
+			if errors.Is(err, io.ErrUnexpectedEOF) {
+				return time.Time{}, nil, fmt.Errorf("truncated capture: %w", err)
+			}
+			return time.Time{}, nil, err
+		}
+		size, plen := int(r.order.Uint16(hdr[0:])), int(r.order.Uint16(hdr[2:]))
+		offset := time.Duration(r.order.Uint32(hdr[4:])) * time.Millisecond
+		if size < 8 || plen > size-8 {
+			return time.Time{}, nil, fmt.Errorf("invalid rtpdump record")
+		}
+		data := make([]byte, size-8)
+		if _, err := io.ReadFull(r.r, data); err != nil {
+			return time.Time{}, nil, fmt.Errorf("truncated capture: %w", err)
+		}
+		if plen == 0 {
+			// RTCP packets are stored without plen.
+			return r.readRecord()
+		}
+		return r.start.Add(offset), data[:plen], nil
+	default:
+		var hdr [16]byte
+		if _, err := io.ReadFull(r.r, hdr[:]); err != nil {
+			if errors.Is(err, io.ErrUnexpectedEOF) {
+				return time.Time{}, nil, fmt.Errorf("truncated capture: %w", err)
+			}
+			return time.Time{}, nil, err
+		}
+		sec, frac := int64(r.order.Uint32(hdr[0:])), int64(r.order.Uint32(hdr[4:]))
+		if !r.nano {
+			frac *= 1000
+		}
+		size := r.order.Uint32(hdr[8:])
+		if size > pcapSnapLen*4 {
+			return time.Time{}, nil, fmt.Errorf("invalid pcap record size: %d", size)
+		}
+		data := make([]byte, size)
+		if _, err := io.ReadFull(r.r, data); err != nil {
+			return time.Time{}, nil, fmt.Errorf("truncated capture: %w", err)
+		}
+		return time.Unix(sec, frac), data, nil
+	}
+}
+
+// udpPayload extracts UDP payload from a captured frame. It returns nil for non-UDP packets and IP fragments.
+func udpPayload(data []byte, linkType uint32) []byte {
+	var proto uint16
+	switch linkType {
+	case linkTypeEthernet:
+		if len(data) < 14 {
+			return nil
+		}
+		proto, data = binary.BigEndian.Uint16(data[12:]), data[14:]
+		for proto == 0x8100 && len(data) >= 4 { // VLAN
+			proto, data = binary.BigEndian.Uint16(data[2:]), data[4:]
+		}
+	case linkTypeLinuxSLL:
+		if len(data) < 16 {
+			return nil
+		}
+		proto, data = binary.BigEndian.Uint16(data[14:]), data[16:]
+	case linkTypeNull:
+		if len(data) < 4 {
+			return nil
+		}
+		data = data[4:]
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	if proto == 0 {
+		// Raw IP, detect by version.
+		switch data[0] >> 4 {
+		case 4:
+			proto = 0x0800
+		case 6:
+			proto = 0x86dd
+		}
+	}
+	switch proto {
+	case 0x0800:
+		if len(data) < 20 {
+			return nil
+		}
+		ihl := int(data[0]&0xf) * 4
+		if data[9] != 17 || len(data) < ihl+8 || binary.BigEndian.Uint16(data[6:])&0x3fff != 0 {
+			return nil
+		}
+		data = data[ihl:]
+	case 0x86dd:
+		if len(data) < 48 || data[6] != 17 {
+			return nil
+		}
+		data = data[40:]
+	default:
+		return nil
+	}
+	size := int(binary.BigEndian.Uint16(data[4:]))
+	if size < 8 || size > len(data) {
+		return nil
+	}
+	return data[8:size]
+}
+
+// ReplayCapture reads all packets from the capture and passes them to h.
+// If realtime is set, packets are delayed according to their original timing, otherwise they are sent as fast as possible.
+// It returns nil when the end of the capture is reached.
+func ReplayCapture(ctx context.Context, r *CaptureReader, h Handler, realtime bool) error {
+	var (
+		first time.Time
+		start time.Time
+		timer *time.Timer
+	)
+	if realtime {
+		timer = time.NewTimer(0)
+		defer timer.Stop()
+		<-timer.C
+	}
+	for {
+		p, err := r.ReadPacket()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if realtime {
+			if first.IsZero() {
+				first, start = p.Time, time.Now()
+			}
+			if wait := time.Until(start.Add(p.Time.Sub(first))); wait > 0 {
+				timer.Reset(wait)
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-timer.C:
+				}
+			}
+		}
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		if err = h.HandleRTP(&p.Header, p.Payload); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtp
+
+import (
+	"bytes"
+	"context"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapture(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	var packets []*Packet
+	for i := range 10 {
+		packets = append(packets, &Packet{
+			Header: Header{
+				Version:        2,
+				PayloadType:    0,
+				SequenceNumber: uint16(65530 + i),
+				Timestamp:      uint32(i * 160),
+				SSRC:           0x1234,
+				CSRC:           []uint32{},
+				Marker:         i == 0,
+			},
+			Payload: bytes.Repeat([]byte{byte(i)}, 160),
+		})
+	}
+	for _, c := range []struct {
+		name   string
+		format CaptureFormat
+		addrs  []netip.AddrPort
+	}{
+		{name: "pcap", format: CapturePcap},
+		{name: "pcap6", format: CapturePcap, addrs: []netip.AddrPort{
+			netip.MustParseAddrPort("[::1]:1000"),
+			netip.MustParseAddrPort("[::1]:2000"),
+		}},
+		{name: "pcap mixed", format: CapturePcap, addrs: []netip.AddrPort{
+			netip.MustParseAddrPort("127.0.0.1:1000"),
+			netip.MustParseAddrPort("[::1]:2000"),
+		}},
+		{name: "rtpdump", format: CaptureRTPDump},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			var (
+				buf  bytes.Buffer
+				opts []CaptureOption
+			)
+			if c.addrs != nil {
+				opts = append(opts, WithCaptureAddrs(c.addrs[0], c.addrs[1]))
+			}
+			w := NewCaptureWriter(&buf, c.format, opts...)
+			for i, p := range packets {
+				require.NoError(t, w.WritePacket(start.Add(time.Duration(i)*20*time.Millisecond), &p.Header, p.Payload))
+			}
+			require.NoError(t, w.Close())
+
+			r, err := NewCaptureReader(bytes.NewReader(buf.Bytes()))
+			require.NoError(t, err)
+			require.Equal(t, c.format, r.Format())
+			for i, p := range packets {
+				got, err := r.ReadPacket()
+				require.NoError(t, err)
+				require.Equal(t, start.Add(time.Duration(i)*20*time.Millisecond), got.Time)
+				require.Equal(t, p.Header, got.Header)
+				require.Equal(t, p.Payload, got.Payload)
+			}
+
+			t.Run("replay", func(t *testing.T) {
+				for _, realtime := range []bool{false, true} {
+					r, err := NewCaptureReader(bytes.NewReader(buf.Bytes()))
+					require.NoError(t, err)
+					var got Buffer
+					h := HandlerFunc(func(h *Header, payload []byte) error {
+						_, err := got.WriteRTP(h, payload)
+						return err
+					})
+					t0 := time.Now()
+					require.NoError(t, ReplayCapture(context.Background(), r, h, realtime))
+					if realtime {
+						require.GreaterOrEqual(t, time.Since(t0), 180*time.Millisecond)
+					}
+					require.Len(t, got, len(packets))
+					require.Equal(t, packets[3].SequenceNumber, got[3].SequenceNumber)
+				}
+			})
+		})
+	}
+
+	t.Run("handler", func(t *testing.T) {
+		var (
+			buf bytes.Buffer
+			got Buffer
+		)
+		w := NewCaptureWriter(&buf, CapturePcap)
+		h := CaptureHandler(HandlerFunc(func(h *Header, payload []byte) error {
+			_, err := got.WriteRTP(h, payload)
+			return err
+		}), w)
+		for _, p := range packets {
+			require.NoError(t, h.HandleRTP(&p.Header, p.Payload))
+		}
+		require.NoError(t, w.Flush())
+		require.Len(t, got, len(packets))
+
+		r, err := NewCaptureReader(&buf)
+		require.NoError(t, err)
+		p, err := r.ReadPacket()
+		require.NoError(t, err)
+		require.Equal(t, packets[0].Header, p.Header)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		var (
+			buf bytes.Buffer
+			got Buffer
+		)
+		w := NewCaptureWriter(&buf, CapturePcap)
+		h := CaptureHandler(HandlerFunc(func(h *Header, payload []byte) error {
+			_, err := got.WriteRTP(h, payload)
+			return err
+		}), w)
+		// RFC 3550 extension must be padded to 32 bits, thus the header cannot be marshaled.
+		bad := packets[0].Header.Clone()
+		bad.Extension, bad.ExtensionProfile = true, 0x1234
+		require.NoError(t, bad.SetExtension(0, []byte{1, 2, 3}))
+		require.NoError(t, h.HandleRTP(&bad, nil))
+		require.Len(t, got, 1)
+		require.Error(t, w.Flush())
+		require.Error(t, w.Close())
+	})
+
+	_, err := NewCaptureReader(bytes.NewReader([]byte("RIFF0000WAVEfmt ")))
+	require.ErrorIs(t, err, ErrUnknownCapture)
+}
@@ -17,31 +17,46 @@ package rtp
 import (
 	"fmt"
 	"os"
+	"strings"
 	"sync/atomic"
 
 	"github.com/livekit/media-sdk"
 )
 
 var (
-	mediaID         atomic.Uint32
-	mediaDumpToFile = os.Getenv("LK_DUMP_MEDIA") == "true"
+	mediaID     atomic.Uint32
+	mediaDumper atomic.Pointer[media.Dumper]
 )
 
-var (
-	codecByType [0xff]media.Codec
-)
+// SetMediaDumper enables dumps of encoded media for all new RTP codec pipelines. Nil disables the dumps.
+// It is enabled by LK_DUMP_MEDIA=true environment variable by default. See AudioCodecDumper for per-call dumps.
+func SetMediaDumper(d media.Dumper) {
+	if d == nil {
+		mediaDumper.Store(nil)
+		return
+	}
+	mediaDumper.Store(&d)
+}
 
 func init() {
-	media.OnRegister(func(c media.Codec) {
-		info := c.Info()
-		if info.RTPIsStatic {
-			codecByType[info.RTPDefType] = c
-		}
-	})
+	if os.Getenv("LK_DUMP_MEDIA") == "true" {
+		SetMediaDumper(media.NewRawDumper(""))
+	}
 }
 
+// CodecByPayloadType returns a codec with a given static payload type from the default registry.
+// See RegistryCodecByPayloadType for custom registries.
 func CodecByPayloadType(typ byte) media.Codec {
-	return codecByType[typ]
+	return RegistryCodecByPayloadType(nil, typ)
+}
+
+// RegistryCodecByPayloadType returns a codec with a given static payload type from the registry.
+// Nil registry means the default one.
+func RegistryCodecByPayloadType(r *media.Registry, typ byte) media.Codec {
+	if r == nil {
+		r = media.DefaultRegistry()
+	}
+	return r.CodecByPayloadType(typ)
 }
 
 type AudioCodec interface {
@@ -50,6 +65,39 @@ type AudioCodec interface {
 	DecodeRTP(w media.Writer[media.PCM16Sample], typ byte) Handler
 }
 
+// FMTPCodec is implemented by audio codecs that use format parameters (a=fmtp) in SDP.
+type FMTPCodec interface {
+	AudioCodec
+	// FMTP returns format parameters sent in SDP offer or answer. Empty string means no parameters.
+	FMTP() string
+	// WithFMTP returns a codec configured with format parameters received from the remote side.
+	// It returns an error if parameters are not compatible with the codec.
+	WithFMTP(fmtp string) (AudioCodec, error)
+}
+
+// ParseFMTP parses format parameters in "key1=value1;key2=value2" form. Keys are converted to lower case.
+// Parameters without a value are stored with an empty value.
+func ParseFMTP(fmtp string) map[string]string {
+	out := make(map[string]string)
+	for _, p := range strings.Split(fmtp, ";") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		k, v, _ := strings.Cut(p, "=")
+		out[strings.ToLower(strings.TrimSpace(k))] = strings.TrimSpace(v)
+	}
+	return out
+}
+
+// AudioCodecDumper is implemented by audio codecs that can dump encoded media for debugging.
+type AudioCodecDumper interface {
+	// EncodeRTPWithDump is the same as EncodeRTP, but also dumps encoded media to d.
+	EncodeRTPWithDump(w *Stream, d media.Dumper) (media.PCM16Writer, error)
+	// DecodeRTPWithDump is the same as DecodeRTP, but also dumps encoded media to d.
+	DecodeRTPWithDump(w media.Writer[media.PCM16Sample], typ byte, d media.Dumper) (Handler, error)
+}
+
 type AudioEncoder[S BytesFrame] interface {
 	AudioCodec
 	Decode(writer media.PCM16Writer) media.WriteCloser[S]
@@ -92,30 +140,79 @@ func (c *audioCodec[S]) Encode(w media.WriteCloser[S]) media.PCM16Writer {
 	return c.encode(w)
 }
 
+func (c *audioCodec[S]) dumpStream(name string) media.DumpStream {
+	ext := c.info.FileExt
+	if ext == "" {
+		ext = "raw"
+	}
+	return media.DumpStream{
+		Name:       name,
+		Codec:      c.info.SDPName,
+		FileExt:    ext,
+		SampleRate: c.info.SampleRate,
+	}
+}
+
+// defaultDump returns the dumper set by SetMediaDumper and a unique stream name.
+func defaultDump(dir string) (media.Dumper, string) {
+	d := mediaDumper.Load()
+	if d == nil {
+		return nil, ""
+	}
+	id := mediaID.Add(1)
+	return *d, fmt.Sprintf("sip_rtp_%s_%d", dir, id)
+}
+
 func (c *audioCodec[S]) EncodeRTP(w *Stream) media.PCM16Writer {
+	d, name := defaultDump("out")
+	if d != nil {
+		if pw, err := c.encodeRTP(w, d, name); err == nil {
+			return pw
+		}
+	}
+	pw, _ := c.encodeRTP(w, nil, "")
+	return pw
+}
+
+func (c *audioCodec[S]) EncodeRTPWithDump(w *Stream, d media.Dumper) (media.PCM16Writer, error) {
+	return c.encodeRTP(w, d, "rtp_out")
+}
+
+func (c *audioCodec[S]) encodeRTP(w *Stream, d media.Dumper, name string) (media.PCM16Writer, error) {
 	var s media.WriteCloser[S] = NewMediaStreamOut[S](w, c.info.SampleRate)
-	if mediaDumpToFile {
-		id := mediaID.Add(1)
-		name := fmt.Sprintf("sip_rtp_out_%d", id)
-		ext := c.info.FileExt
-		if ext == "" {
-			ext = "raw"
+	if d != nil {
+		var err error
+		s, err = media.DumpTo(d, c.dumpStream(name), media.NopCloser(s))
+		if err != nil {
+			return nil, err
 		}
-		s = media.DumpWriter[S](ext, name, media.NopCloser(s))
 	}
-	return c.encode(s)
+	return c.encode(s), nil
 }
 
 func (c *audioCodec[S]) DecodeRTP(w media.Writer[media.PCM16Sample], typ byte) Handler {
+	d, name := defaultDump("in")
+	if d != nil {
+		if h, err := c.decodeRTP(w, d, name); err == nil {
+			return h
+		}
+	}
+	h, _ := c.decodeRTP(w, nil, "")
+	return h
+}
+
+func (c *audioCodec[S]) DecodeRTPWithDump(w media.Writer[media.PCM16Sample], typ byte, d media.Dumper) (Handler, error) {
+	return c.decodeRTP(w, d, "rtp_in")
+}
+
+func (c *audioCodec[S]) decodeRTP(w media.Writer[media.PCM16Sample], d media.Dumper, name string) (Handler, error) {
 	s := c.decode(media.NopCloser(w))
-	if mediaDumpToFile {
-		id := mediaID.Add(1)
-		name := fmt.Sprintf("sip_rtp_in_%d", id)
-		ext := c.info.FileExt
-		if ext == "" {
-			ext = "raw"
+	if d != nil {
+		var err error
+		s, err = media.DumpTo(d, c.dumpStream(name), media.NopCloser(s))
+		if err != nil {
+			return nil, err
 		}
-		s = media.DumpWriter[S](ext, name, media.NopCloser(s))
 	}
-	return NewMediaStreamIn(s)
+	return NewMediaStreamIn(s), nil
 }
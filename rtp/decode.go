@@ -0,0 +1,109 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtp
+
+import (
+	"sync/atomic"
+
+	"github.com/pion/rtp"
+
+	"github.com/livekit/media-sdk"
+)
+
+// PayloadCodec is an audio codec negotiated for a specific RTP payload type.
+type PayloadCodec struct {
+	Type  byte
+	Codec AudioCodec
+}
+
+// StaticPayloadCodecs returns audio codecs for static payload types from the registry, to be used with NewAudioDecoder.
+// Nil registry means the default one. Types without an enabled audio codec are skipped.
+func StaticPayloadCodecs(r *media.Registry, types ...byte) []PayloadCodec {
+	if r == nil {
+		r = media.DefaultRegistry()
+	}
+	out := make([]PayloadCodec, 0, len(types))
+	for _, typ := range types {
+		c, ok := RegistryCodecByPayloadType(r, typ).(AudioCodec)
+		if !ok || !r.Enabled(c) {
+			continue
+		}
+		out = append(out, PayloadCodec{Type: typ, Codec: c})
+	}
+	return out
+}
+
+// NewAudioDecoder creates an RTP handler that decodes all given payload types into the same writer.
+//
+// Audio is resampled to the sample rate of the writer if a codec uses a different rate.
+// This allows the remote to switch between negotiated codecs without renegotiation.
+// Other handlers, for example DTMF, can be registered with Register.
+func NewAudioDecoder(w media.Writer[media.PCM16Sample], codecs []PayloadCodec) *AudioDecoder {
+	d := &AudioDecoder{Mux: NewMux(nil), types: make(map[byte]struct{}, len(codecs))}
+	d.last.Store(-1)
+	for _, c := range codecs {
+		var cw media.PCM16Writer = media.NopCloser(w)
+		if rate := c.Codec.Info().SampleRate; rate != w.SampleRate() {
+			cw = media.ResampleWriter(cw, rate)
+			d.closers = append(d.closers, cw)
+		}
+		d.Mux.Register(c.Type, c.Codec.DecodeRTP(cw, c.Type))
+		d.types[c.Type] = struct{}{}
+	}
+	return d
+}
+
+// AudioDecoder decodes multiple negotiated audio payload types. See NewAudioDecoder.
+type AudioDecoder struct {
+	*Mux
+	types    map[byte]struct{}
+	closers  []media.PCM16Writer
+	last     atomic.Int32
+	onSwitch atomic.Pointer[func(typ byte)]
+}
+
+// OnSwitch sets a callback that is called when the remote switches to a different payload type.
+// It is also called for the first received packet.
+func (d *AudioDecoder) OnSwitch(fnc func(typ byte)) {
+	if fnc == nil {
+		d.onSwitch.Store(nil)
+		return
+	}
+	d.onSwitch.Store(&fnc)
+}
+
+// PayloadType returns the payload type of the last decoded packet, or -1 if nothing was received yet.
+func (d *AudioDecoder) PayloadType() int {
+	return int(d.last.Load())
+}
+
+func (d *AudioDecoder) HandleRTP(h *rtp.Header, payload []byte) error {
+	if _, ok := d.types[h.PayloadType]; ok {
+		if prev := d.last.Swap(int32(h.PayloadType)); prev != int32(h.PayloadType) {
+			if fnc := d.onSwitch.Load(); fnc != nil {
+				(*fnc)(h.PayloadType)
+			}
+		}
+	}
+	return d.Mux.HandleRTP(h, payload)
+}
+
+// Close releases resamplers used by the decoder. The destination writer is not closed.
+func (d *AudioDecoder) Close() {
+	for _, w := range d.closers {
+		_ = w.Close()
+	}
+	d.closers = nil
+}
@@ -0,0 +1,79 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtp_test
+
+import (
+	"testing"
+
+	prtp "github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/media-sdk"
+	_ "github.com/livekit/media-sdk/g711"
+	_ "github.com/livekit/media-sdk/g722"
+	"github.com/livekit/media-sdk/rtp"
+)
+
+func TestAudioDecoder(t *testing.T) {
+	// Custom registry without PCMA.
+	reg := media.NewRegistry()
+	reg.RegisterCodec(rtp.CodecByPayloadType(0))
+	reg.RegisterCodec(rtp.CodecByPayloadType(9))
+	require.Nil(t, rtp.RegistryCodecByPayloadType(reg, 8))
+	codecs := rtp.StaticPayloadCodecs(reg, 0, 8, 9)
+	require.Len(t, codecs, 2)
+
+	var frames []media.PCM16Sample
+	w := media.NewPCM16FrameWriter(&frames, 16000)
+	d := rtp.NewAudioDecoder(w, codecs)
+	defer d.Close()
+
+	var dtmf int
+	d.Register(101, rtp.HandlerFunc(func(h *prtp.Header, payload []byte) error {
+		dtmf++
+		return nil
+	}))
+	var switches []byte
+	d.OnSwitch(func(typ byte) {
+		switches = append(switches, typ)
+	})
+	require.Equal(t, -1, d.PayloadType())
+
+	send := func(typ byte, n int) int {
+		frames = frames[:0]
+		for i := range n {
+			h := &prtp.Header{PayloadType: typ, SequenceNumber: uint16(i), Timestamp: uint32(i * 160)}
+			require.NoError(t, d.HandleRTP(h, make([]byte, 160)))
+		}
+		total := 0
+		for _, f := range frames {
+			total += len(f)
+		}
+		return total
+	}
+	const N = 10
+	// G.722 is decoded at 16 kHz directly.
+	require.Equal(t, N*320, send(9, N))
+	// PCMU is resampled from 8 kHz.
+	require.InDelta(t, N*320, send(0, N), 320)
+	send(101, 3)
+	require.Equal(t, 3, dtmf)
+	require.Equal(t, N*320, send(9, N))
+	// Unknown payload types are dropped.
+	require.Zero(t, send(8, N))
+
+	require.Equal(t, []byte{9, 0, 9}, switches)
+	require.Equal(t, 9, d.PayloadType())
+}
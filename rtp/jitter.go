@@ -26,6 +26,8 @@ const (
 	jitterMaxLatency = 60 * time.Millisecond // should match mixer's target buffer size
 )
 
+// HandleJitter reorders packets with a jitter buffer before passing them to the handler.
+// The buffer uses a fixed latency by default, pass jitter.WithAdaptiveLatency to adjust it based on network conditions.
 func HandleJitter(h HandlerCloser, opts ...jitter.Option) HandlerCloser {
 	handler := &jitterHandler{
 		h:   h,
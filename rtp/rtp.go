@@ -34,6 +34,13 @@ type BytesFrame interface {
 	media.Frame
 }
 
+// DTXFrame is implemented by frames of codecs with discontinuous transmission (DTX).
+// Encoders of such codecs write an empty frame when nothing needs to be sent for the packet duration.
+type DTXFrame interface {
+	media.Frame
+	DTX()
+}
+
 type Writer interface {
 	String() string
 	WriteRTP(h *rtp.Header, payload []byte) (int, error)
@@ -175,11 +182,13 @@ func (s *SeqWriter) WriteEvent(ev *Event) error {
 
 // NewStream creates a new media stream in RTP and tracks timestamps associated with it.
 func (s *SeqWriter) NewStream(typ byte, clockRate int) *Stream {
-	return s.NewStreamWithDur(typ, uint32(clockRate/DefFramesPerSec))
+	st := s.NewStreamWithDur(typ, uint32(clockRate/DefFramesPerSec))
+	st.clockRate = clockRate
+	return st
 }
 
 func (s *SeqWriter) NewStreamWithDur(typ byte, packetDur uint32) *Stream {
-	st := &Stream{s: s, packetDur: packetDur}
+	st := &Stream{s: s, packetDur: packetDur, clockRate: int(packetDur) * DefFramesPerSec}
 	st.ev.Type = typ
 	return st
 }
@@ -187,6 +196,7 @@ func (s *SeqWriter) NewStreamWithDur(typ byte, packetDur uint32) *Stream {
 type Stream struct {
 	s         *SeqWriter
 	packetDur uint32
+	clockRate int
 	mu        sync.Mutex
 	ev        Event
 	followup  bool
@@ -224,6 +234,11 @@ func (s *Stream) WritePayloadAtCurrent(data []byte, marker bool) error {
 	return s.writePayload(false, data, marker)
 }
 
+// ClockRate returns RTP clock rate of the stream.
+func (s *Stream) ClockRate() int {
+	return s.clockRate
+}
+
 // Delay advances the timestamp of the next frame. Typically used in combination with WritePayloadAtCurrent.
 func (s *Stream) Delay(dur uint32) {
 	s.mu.Lock()
@@ -277,7 +292,13 @@ func (s *MediaStreamOut[T]) Close() error {
 	return nil
 }
 
+// WriteSample sends the sample as a single RTP packet.
+// Empty DTXFrame samples are not sent, but advance the timestamp.
 func (s *MediaStreamOut[T]) WriteSample(sample T) error {
+	if _, ok := any(sample).(DTXFrame); ok && len(sample) == 0 {
+		s.s.DelayN(1)
+		return nil
+	}
 	return s.s.WritePayload([]byte(sample), false)
 }
 
@@ -276,3 +276,44 @@ func TestRTPStreams(t *testing.T) {
 		require.Equal(t, exp, got)
 	})
 }
+
+type rawSample []byte
+
+func (s rawSample) Size() int { return len(s) }
+
+func (s rawSample) CopyTo(dst []byte) (int, error) { return copy(dst, s), nil }
+
+type dtxSample []byte
+
+func (s dtxSample) Size() int { return len(s) }
+
+func (s dtxSample) CopyTo(dst []byte) (int, error) { return copy(dst, s), nil }
+
+func (dtxSample) DTX() {}
+
+func TestMediaStreamOutEmpty(t *testing.T) {
+	t.Run("regular", func(t *testing.T) {
+		var buf Buffer
+		s := NewMediaStreamOut[rawSample](NewSeqWriter(&buf).NewStream(0, 8000), 8000)
+		require.NoError(t, s.WriteSample(rawSample{1}))
+		require.NoError(t, s.WriteSample(rawSample{}))
+		require.NoError(t, s.WriteSample(rawSample{2}))
+		// Empty samples are sent as regular packets.
+		require.Len(t, buf, 3)
+		require.Empty(t, buf[1].Payload)
+		require.Equal(t, buf[0].Timestamp+160, buf[1].Timestamp)
+		require.Equal(t, buf[0].Timestamp+320, buf[2].Timestamp)
+	})
+	t.Run("dtx", func(t *testing.T) {
+		var buf Buffer
+		s := NewMediaStreamOut[dtxSample](NewSeqWriter(&buf).NewStream(18, 8000), 8000)
+		require.NoError(t, s.WriteSample(dtxSample{1}))
+		require.NoError(t, s.WriteSample(dtxSample{}))
+		require.NoError(t, s.WriteSample(dtxSample{}))
+		require.NoError(t, s.WriteSample(dtxSample{2}))
+		// Empty samples are not sent, but the timestamp advances.
+		require.Len(t, buf, 2)
+		require.Equal(t, buf[0].SequenceNumber+1, buf[1].SequenceNumber)
+		require.Equal(t, buf[0].Timestamp+3*160, buf[1].Timestamp)
+	})
+}
@@ -22,6 +22,7 @@ import (
 	"sync"
 
 	"github.com/frostbyte73/core"
+	"github.com/pion/rtcp"
 	"github.com/pion/rtp"
 
 	"github.com/livekit/protocol/logger"
@@ -38,6 +39,15 @@ type Session interface {
 	Close() error
 }
 
+// RTCPSession is a Session that can also send and receive RTCP packets.
+type RTCPSession interface {
+	Session
+	// WriteRTCP writes a compound RTCP packet to the connection.
+	WriteRTCP(pkts []rtcp.Packet) error
+	// ReadRTCP reads the next compound RTCP packet from the connection.
+	ReadRTCP() ([]rtcp.Packet, error)
+}
+
 type WriteStream interface {
 	String() string
 	// WriteRTP writes RTP packet to the connection.
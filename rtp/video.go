@@ -0,0 +1,258 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtp
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pion/rtp"
+
+	"github.com/livekit/media-sdk"
+	"github.com/livekit/media-sdk/jitter"
+)
+
+const (
+	// VideoClockRate is the RTP clock rate used by video codecs.
+	VideoClockRate = 90000
+	// DefVideoMTU is a default maximal size of video RTP payloads.
+	DefVideoMTU = 1200
+
+	videoJitterLatency = 150 * time.Millisecond
+	// keyFrameRequestInterval limits how often keyframes are requested.
+	keyFrameRequestInterval = time.Second
+)
+
+// VideoCodec is a video payload format carried over RTP.
+//
+// Video is not negotiated by the sdp package, which only offers and answers audio and T.38 sections.
+// Thus, video codecs are not registered in the codec registry. Applications must negotiate video
+// sections themselves, including payload types and format parameters, for example H.264 packetization-mode
+// and profile-level-id, or VP8 max-fr.
+type VideoCodec interface {
+	media.Codec
+	// NewDepacketizer creates a depacketizer that reassembles frames from RTP payloads.
+	// Depacketizers are stateful, a new one must be used for each stream.
+	NewDepacketizer() rtp.Depacketizer
+	// NewPayloader creates a payloader that splits frames into RTP payloads.
+	NewPayloader() rtp.Payloader
+	// IsKeyFrame checks if a reassembled frame can be decoded independently.
+	IsKeyFrame(frame []byte) bool
+}
+
+// VideoFrame is a single encoded video frame.
+type VideoFrame struct {
+	Timestamp uint32 // RTP timestamp
+	KeyFrame  bool
+	Data      []byte
+}
+
+// VideoWriter accepts encoded video frames.
+type VideoWriter interface {
+	String() string
+	WriteVideo(f VideoFrame) error
+}
+
+type videoConfig struct {
+	onKeyFrame func()
+	jitter     []jitter.Option
+}
+
+// VideoOption configures video receivers.
+type VideoOption func(c *videoConfig)
+
+// WithKeyFrameRequest sets a callback for requesting a keyframe from the remote, for example with RTCP PLI.
+// It is called when the stream starts without a keyframe and after a packet loss. Requests are rate limited.
+func WithKeyFrameRequest(fnc func()) VideoOption {
+	return func(c *videoConfig) {
+		c.onKeyFrame = fnc
+	}
+}
+
+// WithVideoJitter sets options for the jitter buffer used by the video receiver.
+func WithVideoJitter(opts ...jitter.Option) VideoOption {
+	return func(c *videoConfig) {
+		c.jitter = append(c.jitter, opts...)
+	}
+}
+
+// NewVideoReceiver creates an RTP handler that reorders video packets with a jitter buffer,
+// reassembles frames and writes them to w.
+//
+// Frames are dropped until the next keyframe after a packet loss, since they cannot be decoded.
+func NewVideoReceiver(codec VideoCodec, w VideoWriter, opts ...VideoOption) *VideoReceiver {
+	var conf videoConfig
+	for _, fnc := range opts {
+		fnc(&conf)
+	}
+	r := &VideoReceiver{
+		codec:      codec,
+		w:          w,
+		dep:        codec.NewDepacketizer(),
+		onKeyFrame: conf.onKeyFrame,
+		waitKey:    true,
+		err:        make(chan error, 1),
+	}
+	r.buf = jitter.NewBuffer(codec.NewDepacketizer(), videoJitterLatency, r.handleFrame, conf.jitter...)
+	return r
+}
+
+// VideoReceiver reassembles video frames from RTP. See NewVideoReceiver.
+type VideoReceiver struct {
+	codec      VideoCodec
+	w          VideoWriter
+	dep        rtp.Depacketizer
+	buf        *jitter.Buffer
+	onKeyFrame func()
+	err        chan error
+
+	// Fields below are only accessed from jitter buffer callbacks, which are serialized.
+	waitKey bool
+	lastReq time.Time
+	hasSN   bool
+	lastSN  uint16
+	frame   []byte
+}
+
+func (r *VideoReceiver) String() string {
+	return fmt.Sprintf("VideoReceiver(%s) -> %s", r.codec.Info().SDPName, r.w.String())
+}
+
+func (r *VideoReceiver) HandleRTP(h *rtp.Header, payload []byte) error {
+	// This may call handleFrame, possibly multiple times.
+	r.buf.Push(&rtp.Packet{Header: *h, Payload: payload})
+	select {
+	case err := <-r.err:
+		return err
+	default:
+		return nil
+	}
+}
+
+func (r *VideoReceiver) Close() {
+	r.buf.Close()
+}
+
+// Stats returns jitter buffer statistics.
+func (r *VideoReceiver) Stats() *jitter.BufferStats {
+	return r.buf.Stats()
+}
+
+func (r *VideoReceiver) requestKeyFrame() {
+	if r.onKeyFrame == nil {
+		return
+	}
+	now := time.Now()
+	if !r.lastReq.IsZero() && now.Sub(r.lastReq) < keyFrameRequestInterval {
+		return
+	}
+	r.lastReq = now
+	r.onKeyFrame()
+}
+
+func (r *VideoReceiver) handleLoss() {
+	r.waitKey = true
+	r.requestKeyFrame()
+}
+
+func (r *VideoReceiver) handleFrame(packets []jitter.ExtPacket) {
+	if len(packets) == 0 {
+		return
+	}
+	// Jitter buffer skips lost packets and incomplete frames, which shows up as a gap in sequence numbers.
+	if r.hasSN && packets[0].SequenceNumber != r.lastSN+1 {
+		r.handleLoss()
+	}
+	r.hasSN = true
+	r.lastSN = packets[len(packets)-1].SequenceNumber
+	frame := r.frame[:0]
+	for _, p := range packets {
+		data, err := r.dep.Unmarshal(p.Payload)
+		if err != nil {
+			// Corrupted frame, treat it as a loss.
+			r.frame = frame
+			r.handleLoss()
+			return
+		}
+		frame = append(frame, data...)
+	}
+	r.frame = frame
+	if len(frame) == 0 {
+		return
+	}
+	key := r.codec.IsKeyFrame(frame)
+	if r.waitKey {
+		if !key {
+			r.requestKeyFrame()
+			return
+		}
+		r.waitKey = false
+	}
+	// Frame buffer is reused, so writers must copy the data if they keep it.
+	err := r.w.WriteVideo(VideoFrame{
+		Timestamp: packets[0].Timestamp,
+		KeyFrame:  key,
+		Data:      frame,
+	})
+	if err != nil {
+		select {
+		case r.err <- err:
+			// error pushed
+		default:
+			// error channel is full, don't block
+		}
+	}
+}
+
+// NewVideoStreamOut creates a writer that splits video frames into RTP packets of the stream.
+//
+// The stream should use VideoClockRate and a separate SeqWriter from audio streams.
+// Frame timestamps are only used to calculate the delay between frames.
+func NewVideoStreamOut(s *Stream, codec VideoCodec, mtu int) *VideoStreamOut {
+	if mtu <= 0 {
+		mtu = DefVideoMTU
+	}
+	return &VideoStreamOut{s: s, codec: codec, pay: codec.NewPayloader(), mtu: uint16(mtu)}
+}
+
+// VideoStreamOut packetizes video frames to RTP. See NewVideoStreamOut.
+type VideoStreamOut struct {
+	s       *Stream
+	codec   VideoCodec
+	pay     rtp.Payloader
+	mtu     uint16
+	started bool
+	last    uint32
+}
+
+func (w *VideoStreamOut) String() string {
+	return fmt.Sprintf("RTP(%s, %d)", w.codec.Info().SDPName, w.mtu)
+}
+
+func (w *VideoStreamOut) WriteVideo(f VideoFrame) error {
+	if w.started {
+		w.s.Delay(f.Timestamp - w.last)
+	}
+	w.started = true
+	w.last = f.Timestamp
+	payloads := w.pay.Payload(w.mtu, f.Data)
+	for i, p := range payloads {
+		// Marker is set on the last packet of the frame.
+		if err := w.s.WritePayloadAtCurrent(p, i == len(payloads)-1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtp
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/media-sdk"
+)
+
+const testStartFlag = 0x80
+
+// testVideoCodec uses a one byte header with a start flag. Keyframes start with 'K'.
+type testVideoCodec struct{}
+
+func (testVideoCodec) Info() media.CodecInfo {
+	return media.CodecInfo{SDPName: "TEST/90000", SampleRate: VideoClockRate, RTPClockRate: VideoClockRate}
+}
+
+func (testVideoCodec) NewDepacketizer() rtp.Depacketizer { return testDepacketizer{} }
+
+func (testVideoCodec) NewPayloader() rtp.Payloader { return testPayloader{} }
+
+func (testVideoCodec) IsKeyFrame(frame []byte) bool { return len(frame) != 0 && frame[0] == 'K' }
+
+type testDepacketizer struct{}
+
+func (testDepacketizer) Unmarshal(payload []byte) ([]byte, error) { return payload[1:], nil }
+
+func (testDepacketizer) IsPartitionHead(payload []byte) bool {
+	return len(payload) != 0 && payload[0]&testStartFlag != 0
+}
+
+func (testDepacketizer) IsPartitionTail(marker bool, payload []byte) bool { return marker }
+
+type testPayloader struct{}
+
+func (testPayloader) Payload(mtu uint16, payload []byte) [][]byte {
+	var out [][]byte
+	for i := 0; len(payload) != 0; i++ {
+		n := min(len(payload), int(mtu)-1)
+		flags := byte(0)
+		if i == 0 {
+			flags = testStartFlag
+		}
+		out = append(out, append([]byte{flags}, payload[:n]...))
+		payload = payload[n:]
+	}
+	return out
+}
+
+type testVideoWriter struct {
+	mu     sync.Mutex
+	frames []VideoFrame
+}
+
+func (w *testVideoWriter) String() string { return "test" }
+
+func (w *testVideoWriter) WriteVideo(f VideoFrame) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f.Data = slices.Clone(f.Data)
+	w.frames = append(w.frames, f)
+	return nil
+}
+
+func (w *testVideoWriter) Frames() []VideoFrame {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return slices.Clone(w.frames)
+}
+
+func TestVideoStream(t *testing.T) {
+	var buf Buffer
+	s := NewSeqWriter(&buf).NewStream(96, VideoClockRate)
+	out := NewVideoStreamOut(s, testVideoCodec{}, 4)
+	require.NoError(t, out.WriteVideo(VideoFrame{Timestamp: 1000, KeyFrame: true, Data: []byte("Kabcde")}))
+	require.NoError(t, out.WriteVideo(VideoFrame{Timestamp: 4000, Data: []byte("Pxy")}))
+	require.Len(t, buf, 3)
+	require.Equal(t, buf[0].Timestamp, buf[1].Timestamp)
+	require.Equal(t, buf[0].Timestamp+3000, buf[2].Timestamp)
+	require.False(t, buf[0].Marker)
+	require.True(t, buf[1].Marker)
+	require.True(t, buf[2].Marker)
+
+	var requests atomic.Int32
+	var w testVideoWriter
+	r := NewVideoReceiver(testVideoCodec{}, &w, WithKeyFrameRequest(func() {
+		requests.Add(1)
+	}))
+	defer r.Close()
+	for _, p := range buf {
+		require.NoError(t, r.HandleRTP(&p.Header, p.Payload))
+	}
+	require.Equal(t, []VideoFrame{
+		{Timestamp: buf[0].Timestamp, KeyFrame: true, Data: []byte("Kabcde")},
+		{Timestamp: buf[2].Timestamp, Data: []byte("Pxy")},
+	}, w.Frames())
+	require.Zero(t, requests.Load())
+}
+
+func TestVideoKeyFrameRequest(t *testing.T) {
+	var requests atomic.Int32
+	var w testVideoWriter
+	r := NewVideoReceiver(testVideoCodec{}, &w, WithKeyFrameRequest(func() {
+		requests.Add(1)
+	}))
+	defer r.Close()
+
+	send := func(sn uint16, ts uint32, data string) {
+		h := &rtp.Header{SequenceNumber: sn, Timestamp: ts, Marker: true}
+		require.NoError(t, r.HandleRTP(h, append([]byte{testStartFlag}, data...)))
+	}
+	// Stream starts without a keyframe.
+	send(0, 0, "P0")
+	require.Empty(t, w.Frames())
+	require.EqualValues(t, 1, requests.Load())
+
+	send(1, 3000, "K1")
+	send(2, 6000, "P2")
+	require.Len(t, w.Frames(), 2)
+
+	// Packet 3 is lost. Packet 4 is released after the jitter buffer latency and must be dropped.
+	send(4, 12000, "P4")
+	require.Eventually(t, func() bool {
+		return r.Stats().PacketsLost != 0
+	}, time.Second, 10*time.Millisecond)
+	require.Len(t, w.Frames(), 2)
+
+	send(5, 15000, "K5")
+	send(6, 18000, "P6")
+	frames := w.Frames()
+	require.Len(t, frames, 4)
+	require.Equal(t, "K5", string(frames[2].Data))
+	require.Equal(t, "P6", string(frames[3].Data))
+}
@@ -18,29 +18,88 @@ import (
 	"strings"
 
 	"github.com/livekit/media-sdk"
+	"github.com/livekit/media-sdk/dtmf"
 )
 
-var (
-	codecByName = make(map[string]media.Codec)
+func CodecByName(name string) media.Codec {
+	return media.DefaultRegistry().CodecByName(name)
+}
+
+// CodecPolicy defines how a codec preference list is applied to offers and answers.
+type CodecPolicy int
+
+const (
+	// PolicyPriority orders codecs by their registry priority. The preference list is ignored.
+	PolicyPriority CodecPolicy = iota
+	// PolicyOursFirst prefers codecs from our preference list, in the list order.
+	// Other common codecs are still allowed, but have a lower preference.
+	PolicyOursFirst
+	// PolicyTheirsFirst selects the first supported codec in the remote order.
+	// The preference list only defines the codec order in our offers.
+	PolicyTheirsFirst
+	// PolicyStrict only allows codecs from our preference list, in the list order.
+	PolicyStrict
 )
 
-func init() {
-	media.OnRegister(func(c media.Codec) {
-		name := c.Info().SDPName
-		if name != "" {
-			name = strings.ToLower(name)
-			codecByName[name] = c
-			if strings.Count(name, "/") == 1 {
-				codecByName[name+"/1"] = c
-			}
+func (p CodecPolicy) String() string {
+	switch p {
+	case PolicyPriority:
+		return "priority"
+	case PolicyOursFirst:
+		return "ours-first"
+	case PolicyTheirsFirst:
+		return "theirs-first"
+	case PolicyStrict:
+		return "strict"
+	}
+	return "unknown"
+}
+
+// CodecPreference is an ordered list of codec SDP names, for example "PCMA/8000", together with a policy.
+type CodecPreference struct {
+	Policy CodecPolicy
+	Codecs []string
+}
+
+func normCodecName(name string) string {
+	name = strings.ToLower(name)
+	if strings.Count(name, "/") == 2 {
+		name = strings.TrimSuffix(name, "/1")
+	}
+	return name
+}
+
+// index returns the position of the codec in the preference list, or -1 if it's not listed.
+func (p *CodecPreference) index(c media.Codec) int {
+	name := normCodecName(c.Info().SDPName)
+	for i, n := range p.Codecs {
+		if normCodecName(n) == name {
+			return i
 		}
-	})
+	}
+	return -1
 }
 
-func CodecByName(name string) media.Codec {
-	c := codecByName[strings.ToLower(name)]
-	if !media.CodecEnabled(c) {
-		return nil
+// allowed checks if the policy allows using the codec. DTMF is always allowed.
+func (p *CodecPreference) allowed(c media.Codec) bool {
+	if p.Policy != PolicyStrict {
+		return true
+	} else if _, ok := dtmf.ParseSDPName(c.Info().SDPName); ok {
+		return true
+	}
+	return p.index(c) >= 0
+}
+
+// compareListed orders codecs from the preference list first, in the list order.
+func (p *CodecPreference) compareListed(a, b media.Codec) int {
+	ai, bi := p.index(a), p.index(b)
+	switch {
+	case ai >= 0 && bi >= 0:
+		return ai - bi
+	case ai >= 0:
+		return -1
+	case bi >= 0:
+		return 1
 	}
-	return c
+	return 0
 }
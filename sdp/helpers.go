@@ -15,9 +15,12 @@
 package sdp
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"net/netip"
+	"slices"
+	"strings"
 
 	"github.com/pion/sdp/v3"
 )
@@ -58,3 +61,67 @@ func GetAudioDest(s *sdp.SessionDescription, audio *sdp.MediaDescription) (netip
 	}
 	return netip.AddrPortFrom(ip, uint16(audio.MediaName.Port.Value)), nil
 }
+
+var (
+	// knownMedia and knownProtos list values accepted by the SDP parser in m-lines.
+	knownMedia  = []string{"audio", "video", "text", "application", "message"}
+	knownProtos = []string{
+		"UDP", "RTP", "AVP", "SAVP", "SAVPF", "TLS", "DTLS", "SCTP", "AVPF", "TCP", "MSRP", "BFCP", "UDT", "IX", "MRCPv2",
+	}
+)
+
+// Unmarshal parses the session description.
+//
+// Unlike sdp.SessionDescription.Unmarshal, it accepts m-lines with any media type and transport,
+// for example T.38 sections ("m=image 0 udptl t38"). Such sections are parsed with a placeholder
+// media type and transport, which are restored after parsing.
+func Unmarshal(data []byte, s *sdp.SessionDescription) error {
+	type mline struct {
+		media  string
+		protos []string
+	}
+	var (
+		fixes map[int]mline
+		buf   []byte
+		index int
+	)
+	for line := range bytes.Lines(data) {
+		if !bytes.HasPrefix(line, []byte("m=")) {
+			buf = append(buf, line...)
+			continue
+		}
+		body := bytes.TrimRight(line[2:], "\r\n")
+		eol := line[2+len(body):]
+		fields := strings.Fields(string(body))
+		if len(fields) >= 3 {
+			protos := strings.Split(fields[2], "/")
+			if !slices.Contains(knownMedia, fields[0]) || slices.ContainsFunc(protos, func(p string) bool {
+				return !slices.Contains(knownProtos, p)
+			}) {
+				if fixes == nil {
+					fixes = make(map[int]mline)
+				}
+				fixes[index] = mline{media: fields[0], protos: protos}
+				fields[0], fields[2] = "application", "UDP"
+				body = []byte(strings.Join(fields, " "))
+			}
+		}
+		index++
+		buf = append(buf, "m="...)
+		buf = append(buf, body...)
+		buf = append(buf, eol...)
+	}
+	if fixes == nil {
+		return s.Unmarshal(data)
+	}
+	if err := s.Unmarshal(buf); err != nil {
+		return err
+	}
+	for i, m := range s.MediaDescriptions {
+		if f, ok := fixes[i]; ok {
+			m.MediaName.Media = f.media
+			m.MediaName.Protos = f.protos
+		}
+	}
+	return nil
+}
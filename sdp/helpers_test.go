@@ -108,3 +108,28 @@ func TestGetAudioDest(t *testing.T) {
 		})
 	}
 }
+
+func TestUnmarshalUnknownMedia(t *testing.T) {
+	var s sdp.SessionDescription
+	err := Unmarshal([]byte("v=0\r\n"+
+		"o=- 1 1 IN IP4 10.0.0.1\r\n"+
+		"s=-\r\n"+
+		"c=IN IP4 10.0.0.1\r\n"+
+		"t=0 0\r\n"+
+		"m=audio 5000 RTP/AVP 0\r\n"+
+		"m=image 6000 udptl t38\r\n"+
+		"a=T38FaxVersion:0\r\n"), &s)
+	require.NoError(t, err)
+	require.Len(t, s.MediaDescriptions, 2)
+	require.Equal(t, sdp.MediaName{
+		Media:   "image",
+		Port:    sdp.RangedPort{Value: 6000},
+		Protos:  []string{"udptl"},
+		Formats: []string{"t38"},
+	}, s.MediaDescriptions[1].MediaName)
+	require.Equal(t, "audio", s.MediaDescriptions[0].MediaName.Media)
+
+	data, err := s.Marshal()
+	require.NoError(t, err)
+	require.Contains(t, string(data), "m=image 6000 udptl t38\r\n")
+}
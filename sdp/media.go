@@ -0,0 +1,283 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sdp
+
+import (
+	"net/netip"
+	"strconv"
+
+	"github.com/pion/sdp/v3"
+)
+
+// MediaSection describes a single media section (m-line) of the session description.
+type MediaSection struct {
+	Index int    // index of the m-line
+	Kind  string // media type, for example "audio" or "video"
+	Mid   string // a=mid, if set
+	Label string // a=label, if set
+	// Addr is the destination address of the section. Zero port means that the section is rejected or disabled.
+	Addr netip.AddrPort
+	// Primary is set for the main audio section, which is described by Description.MediaDesc.
+	Primary bool
+	// Audio is set for audio sections that can be negotiated.
+	Audio *MediaDesc
+	// T38 is set for T.38 fax relay sections.
+	T38 *T38Config
+	// Desc is the original media description.
+	Desc *sdp.MediaDescription
+}
+
+// Rejected checks if the section is rejected or disabled by setting the port to zero.
+func (s *MediaSection) Rejected() bool {
+	return s.Addr.Port() == 0
+}
+
+// AudioStream describes an additional audio stream. See WithAudioStreams.
+type AudioStream struct {
+	Port int
+	// Mid and Label set a=mid and a=label for offers. Answers use the values from the offer.
+	Mid   string
+	Label string
+}
+
+// primaryAudio returns the index of the main audio section. It picks the first audio section that is not disabled,
+// or the first audio section, if all of them are disabled. It returns -1 if there's no audio.
+func primaryAudio(s *sdp.SessionDescription) int {
+	first := -1
+	for i, m := range s.MediaDescriptions {
+		if m.MediaName.Media != "audio" {
+			continue
+		}
+		if m.MediaName.Port.Value != 0 {
+			return i
+		}
+		if first < 0 {
+			first = i
+		}
+	}
+	return first
+}
+
+// parseSections parses all media sections. Main audio section uses an already parsed description.
+// Other audio sections that fail to parse are treated as unsupported.
+func parseSections(s *sdp.SessionDescription, primary int, audio *MediaDesc, opts []Option) []MediaSection {
+	out := make([]MediaSection, 0, len(s.MediaDescriptions))
+	for i, m := range s.MediaDescriptions {
+		sec := MediaSection{
+			Index:   i,
+			Kind:    m.MediaName.Media,
+			Primary: i == primary,
+			Desc:    m,
+		}
+		sec.Mid, _ = m.Attribute("mid")
+		sec.Label, _ = m.Attribute("label")
+		if addr, err := GetAudioDest(s, m); err == nil {
+			sec.Addr = addr
+		}
+		switch {
+		case sec.Primary:
+			a := *audio
+			sec.Audio = &a
+		case sec.Kind == "audio" && !sec.Rejected():
+			if a, err := ParseMedia(m, opts...); err == nil {
+				sec.Audio = a
+			}
+		case IsT38(m) && !sec.Rejected():
+			if c, err := ParseT38(m); err == nil {
+				sec.T38 = c
+			}
+		}
+		out = append(out, sec)
+	}
+	return out
+}
+
+// setMediaID adds a=mid and a=label attributes to the media description.
+func setMediaID(m *sdp.MediaDescription, mid, label string) {
+	var attrs []sdp.Attribute
+	if mid != "" {
+		attrs = append(attrs, sdp.Attribute{Key: "mid", Value: mid})
+	}
+	if label != "" {
+		attrs = append(attrs, sdp.Attribute{Key: "label", Value: label})
+	}
+	m.Attributes = append(attrs, m.Attributes...)
+}
+
+// rejectMedia creates a media description that rejects the offered section by setting the port to zero.
+func rejectMedia(m *sdp.MediaDescription) *sdp.MediaDescription {
+	formats := m.MediaName.Formats
+	if len(formats) == 0 {
+		formats = []string{"0"}
+	}
+	return &sdp.MediaDescription{
+		MediaName: sdp.MediaName{
+			Media:   m.MediaName.Media,
+			Port:    sdp.RangedPort{Value: 0},
+			Protos:  m.MediaName.Protos,
+			Formats: formats,
+		},
+	}
+}
+
+// offerStreams creates media descriptions for additional audio streams and T.38 section of the offer.
+// When additional streams are present, all sections get a=mid.
+func offerStreams(publicIp netip.Addr, main *sdp.MediaDescription, mainDesc *MediaDesc, encrypted Encryption, o *options) ([]*sdp.MediaDescription, []MediaSection, error) {
+	descs := []*sdp.MediaDescription{main}
+	sections := []MediaSection{{
+		Kind:    "audio",
+		Addr:    netip.AddrPortFrom(publicIp, uint16(main.MediaName.Port.Value)),
+		Primary: true,
+		Audio:   mainDesc,
+		Desc:    main,
+	}}
+	if len(o.streams) != 0 {
+		sections[0].Mid = "0"
+		setMediaID(main, sections[0].Mid, "")
+	}
+	for i, st := range o.streams {
+		m, desc, err := offerMedia(st.Port, encrypted, o)
+		if err != nil {
+			return nil, nil, err
+		}
+		mid := st.Mid
+		if mid == "" {
+			mid = strconv.Itoa(i + 1)
+		}
+		setMediaID(desc, mid, st.Label)
+		descs = append(descs, desc)
+		sections = append(sections, MediaSection{
+			Index: i + 1,
+			Kind:  "audio",
+			Mid:   mid,
+			Label: st.Label,
+			Addr:  netip.AddrPortFrom(publicIp, uint16(st.Port)),
+			Audio: &m,
+			Desc:  desc,
+		})
+	}
+	if o.t38 != nil {
+		desc, sec := offerT38(publicIp, len(descs), o.t38)
+		if len(o.streams) != 0 {
+			sec.Mid = strconv.Itoa(sec.Index)
+			setMediaID(desc, sec.Mid, "")
+		}
+		descs = append(descs, desc)
+		sections = append(sections, sec)
+	}
+	return descs, sections, nil
+}
+
+// offerT38 creates a T.38 section of the offer.
+func offerT38(publicIp netip.Addr, index int, t *t38Option) (*sdp.MediaDescription, MediaSection) {
+	conf := t.conf
+	desc := T38Media(t.port, conf)
+	return desc, MediaSection{
+		Index: index,
+		Kind:  T38MediaType,
+		Addr:  netip.AddrPortFrom(publicIp, uint16(t.port)),
+		T38:   &conf,
+		Desc:  desc,
+	}
+}
+
+// answerSections answers all media sections of the offer in the same order. Additional audio sections are accepted
+// if there are audio streams configured for them, and the first T.38 section is accepted if T.38 is enabled.
+// All other sections are rejected.
+func (d *Offer) answerSections(publicIp netip.Addr, main *sdp.MediaDescription, mainDesc *MediaDesc, conf *MediaConfig, enc Encryption, o *options) ([]*sdp.MediaDescription, []MediaSection) {
+	streams := o.streams
+	descs := make([]*sdp.MediaDescription, 0, len(d.Media))
+	sections := make([]MediaSection, 0, len(d.Media))
+	for _, sec := range d.Media {
+		out := MediaSection{
+			Index: sec.Index,
+			Kind:  sec.Kind,
+			Mid:   sec.Mid,
+			Label: sec.Label,
+		}
+		var m *sdp.MediaDescription
+		switch {
+		case sec.Primary:
+			m = main
+			out.Primary = true
+			out.Addr = conf.Local
+			out.Audio = mainDesc
+			conf.Mid, conf.Label = sec.Mid, sec.Label
+		case sec.Audio != nil && !sec.Rejected() && len(streams) != 0:
+			local := netip.AddrPortFrom(publicIp, uint16(streams[0].Port))
+			c, desc, audio, err := answerAudio(sec.Audio, sec.Addr, local, enc, o)
+			if err != nil {
+				break // rejected below
+			}
+			streams = streams[1:]
+			c.Mid, c.Label = sec.Mid, sec.Label
+			conf.Streams = append(conf.Streams, c)
+			m = desc
+			out.Addr = local
+			out.Audio = audio
+		case sec.T38 != nil && !sec.Rejected() && o.t38 != nil && conf.T38 == nil:
+			local := netip.AddrPortFrom(publicIp, uint16(o.t38.port))
+			t := answerT38(*sec.T38, o.t38.conf)
+			conf.T38 = &T38MediaConfig{
+				Local:             local,
+				Remote:            sec.Addr,
+				Config:            t,
+				RemoteMaxDatagram: sec.T38.MaxDatagram,
+			}
+			m = T38Media(o.t38.port, t)
+			out.Addr = local
+			out.T38 = &t
+		}
+		if m == nil {
+			m = rejectMedia(sec.Desc)
+		}
+		setMediaID(m, sec.Mid, sec.Label)
+		out.Desc = m
+		descs = append(descs, m)
+		sections = append(sections, out)
+	}
+	return descs, sections
+}
+
+// applyStreams negotiates additional audio streams and T.38 section accepted in the answer.
+func (d *Answer) applyStreams(offer *Offer, conf *MediaConfig, enc Encryption, o *options) {
+	for _, sec := range d.Media {
+		if sec.Primary || sec.Rejected() || sec.Index >= len(offer.Media) {
+			continue
+		}
+		osec := offer.Media[sec.Index]
+		if osec.Kind != sec.Kind {
+			continue
+		}
+		if sec.T38 != nil && osec.T38 != nil && conf.T38 == nil {
+			conf.T38 = &T38MediaConfig{
+				Local:             osec.Addr,
+				Remote:            sec.Addr,
+				Config:            applyT38(*osec.T38, *sec.T38),
+				RemoteMaxDatagram: sec.T38.MaxDatagram,
+			}
+			continue
+		}
+		if sec.Audio == nil || osec.Audio == nil {
+			continue
+		}
+		c, err := applyAudio(sec.Audio, osec.Audio, osec.Addr, sec.Addr, enc, o)
+		if err != nil {
+			continue
+		}
+		c.Mid, c.Label = osec.Mid, osec.Label
+		conf.Streams = append(conf.Streams, c)
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"math/bits"
 	"math/rand/v2"
 	"net/netip"
 	"slices"
@@ -37,6 +38,7 @@ import (
 var (
 	ErrNoCommonMedia  = errors.New("common audio codec not found")
 	ErrNoCommonCrypto = errors.New("no common encryption profiles")
+	ErrNoT38          = errors.New("no T.38 media in sdp")
 )
 
 type Encryption int
@@ -50,12 +52,49 @@ const (
 type CodecInfo struct {
 	Type  byte
 	Codec media.Codec
+	// FMTP contains format parameters (a=fmtp) for this payload type, if any.
+	FMTP string
 }
 
-func OfferCodecs() []CodecInfo {
+func codecFMTP(c media.Codec) string {
+	if fc, ok := c.(rtp.FMTPCodec); ok {
+		return fc.FMTP()
+	}
+	return ""
+}
+
+func OfferCodecs(opts ...Option) []CodecInfo {
+	o := newOptions(opts)
+	return offerCodecs(&o)
+}
+
+func offerCodecs(o *options) []CodecInfo {
 	const dynamicType = 101
-	codecs := media.EnabledCodecs()
+	codecs := o.registry.EnabledCodecs()
+	codecs = slices.DeleteFunc(codecs, func(c media.Codec) bool {
+		return !o.pref.allowed(c)
+	})
+	// Only offer telephone events for clock rates used by audio codecs.
+	rates := make(map[int]struct{})
+	for _, c := range codecs {
+		if _, ok := dtmf.ParseSDPName(c.Info().SDPName); !ok {
+			rates[c.Info().RTPClockRate] = struct{}{}
+		}
+	}
+	codecs = slices.DeleteFunc(codecs, func(c media.Codec) bool {
+		rate, ok := dtmf.ParseSDPName(c.Info().SDPName)
+		if !ok {
+			return false
+		}
+		_, used := rates[rate]
+		return !used
+	})
 	slices.SortFunc(codecs, func(a, b media.Codec) int {
+		if o.pref.Policy != PolicyPriority {
+			if v := o.pref.compareListed(a, b); v != 0 {
+				return v
+			}
+		}
 		ai, bi := a.Info(), b.Info()
 		if ai.RTPIsStatic != bi.RTPIsStatic {
 			if ai.RTPIsStatic {
@@ -64,7 +103,10 @@ func OfferCodecs() []CodecInfo {
 				return 1
 			}
 		}
-		return bi.Priority - ai.Priority
+		if v := o.registry.Priority(b) - o.registry.Priority(a); v != 0 {
+			return v
+		}
+		return ai.RTPClockRate - bi.RTPClockRate
 	})
 	infos := make([]CodecInfo, 0, len(codecs))
 	nextType := byte(dynamicType)
@@ -72,6 +114,7 @@ func OfferCodecs() []CodecInfo {
 		cinfo := c.Info()
 		info := CodecInfo{
 			Codec: c,
+			FMTP:  codecFMTP(c),
 		}
 		if cinfo.RTPIsStatic {
 			info.Type = cinfo.RTPDefType
@@ -89,6 +132,9 @@ type MediaDesc struct {
 	Codecs         []CodecInfo
 	DTMFType       byte // set to 0 if there's no DTMF
 	CryptoProfiles []srtp.Profile
+	// DTMFTypes maps RTP clock rates of telephone events to their payload types.
+	// DTMFType is set to the type for 8000 Hz, if present.
+	DTMFTypes map[int]byte
 }
 
 func appendCryptoProfiles(attrs []sdp.Attribute, profiles []srtp.Profile) []sdp.Attribute {
@@ -98,6 +144,12 @@ func appendCryptoProfiles(attrs []sdp.Attribute, profiles []srtp.Profile) []sdp.
 		buf = append(buf, p.Key...)
 		buf = append(buf, p.Salt...)
 		skey := base64.StdEncoding.WithPadding(base64.StdPadding).EncodeToString(buf)
+		if p.Lifetime != 0 {
+			skey += "|" + formatLifetime(p.Lifetime)
+		}
+		if len(p.MKI) != 0 {
+			skey += "|" + formatMKI(p.MKI)
+		}
 		attrs = append(attrs, sdp.Attribute{
 			Key:   "crypto",
 			Value: fmt.Sprintf("%d %s inline:%s", p.Index, p.Profile, skey),
@@ -106,17 +158,35 @@ func appendCryptoProfiles(attrs []sdp.Attribute, profiles []srtp.Profile) []sdp.
 	return attrs
 }
 
-func OfferMedia(rtpListenerPort int, encrypted Encryption) (MediaDesc, *sdp.MediaDescription, error) {
+func OfferMedia(rtpListenerPort int, encrypted Encryption, opts ...Option) (MediaDesc, *sdp.MediaDescription, error) {
+	o := newOptions(opts)
+	return offerMedia(rtpListenerPort, encrypted, &o)
+}
+
+func offerMedia(rtpListenerPort int, encrypted Encryption, o *options) (MediaDesc, *sdp.MediaDescription, error) {
 	// Static compiler check for frame duration hardcoded below.
 	var _ = [1]struct{}{}[20*time.Millisecond-rtp.DefFrameDur]
 
-	codecs := OfferCodecs()
+	codecs := offerCodecs(o)
 	attrs := make([]sdp.Attribute, 0, len(codecs)+4)
 	formats := make([]string, 0, len(codecs))
-	dtmfType := byte(0)
+	var (
+		dtmfType  byte
+		dtmfTypes map[int]byte
+		dtmfFMTP  []sdp.Attribute
+	)
 	for _, codec := range codecs {
-		if codec.Codec.Info().SDPName == dtmf.SDPName {
-			dtmfType = codec.Type
+		if rate, ok := dtmf.ParseSDPName(codec.Codec.Info().SDPName); ok {
+			if dtmfTypes == nil {
+				dtmfTypes = make(map[int]byte)
+			}
+			dtmfTypes[rate] = codec.Type
+			if dtmfType == 0 || rate == dtmf.SampleRate {
+				dtmfType = codec.Type
+			}
+			dtmfFMTP = append(dtmfFMTP, sdp.Attribute{
+				Key: "fmtp", Value: fmt.Sprintf("%d 0-16", codec.Type),
+			})
 		}
 		styp := strconv.Itoa(int(codec.Type))
 		formats = append(formats, styp)
@@ -124,12 +194,13 @@ func OfferMedia(rtpListenerPort int, encrypted Encryption) (MediaDesc, *sdp.Medi
 			Key:   "rtpmap",
 			Value: styp + " " + codec.Codec.Info().SDPName,
 		})
+		if codec.FMTP != "" {
+			attrs = append(attrs, sdp.Attribute{
+				Key: "fmtp", Value: styp + " " + codec.FMTP,
+			})
+		}
 	}
-	if dtmfType > 0 {
-		attrs = append(attrs, sdp.Attribute{
-			Key: "fmtp", Value: fmt.Sprintf("%d 0-16", dtmfType),
-		})
-	}
+	attrs = append(attrs, dtmfFMTP...)
 	var cryptoProfiles []srtp.Profile
 	if encrypted != EncryptionNone {
 		var err error
@@ -153,6 +224,7 @@ func OfferMedia(rtpListenerPort int, encrypted Encryption) (MediaDesc, *sdp.Medi
 	return MediaDesc{
 			Codecs:         codecs,
 			DTMFType:       dtmfType,
+			DTMFTypes:      dtmfTypes,
 			CryptoProfiles: cryptoProfiles,
 		}, &sdp.MediaDescription{
 			MediaName: sdp.MediaName{
@@ -165,20 +237,30 @@ func OfferMedia(rtpListenerPort int, encrypted Encryption) (MediaDesc, *sdp.Medi
 		}, nil
 }
 
-func AnswerMedia(rtpListenerPort int, audio *AudioConfig, crypt *srtp.Profile) *sdp.MediaDescription {
+// AnswerMedia creates an audio media description for the answer. Selected audio codec is listed first,
+// followed by other common codecs, if any.
+func AnswerMedia(rtpListenerPort int, audio *AudioConfig, crypt *srtp.Profile, others ...rtp.PayloadCodec) *sdp.MediaDescription {
 	// Static compiler check for frame duration hardcoded below.
 	var _ = [1]struct{}{}[20*time.Millisecond-rtp.DefFrameDur]
 
-	attrs := make([]sdp.Attribute, 0, 6)
-	attrs = append(attrs, sdp.Attribute{
-		Key: "rtpmap", Value: fmt.Sprintf("%d %s", audio.Type, audio.Codec.Info().SDPName),
-	})
-	formats := make([]string, 0, 2)
-	formats = append(formats, strconv.Itoa(int(audio.Type)))
+	attrs := make([]sdp.Attribute, 0, 6+2*len(others))
+	formats := make([]string, 0, 2+len(others))
+	codecs := append([]rtp.PayloadCodec{{Type: audio.Type, Codec: audio.Codec}}, others...)
+	for _, c := range codecs {
+		formats = append(formats, strconv.Itoa(int(c.Type)))
+		attrs = append(attrs, sdp.Attribute{
+			Key: "rtpmap", Value: fmt.Sprintf("%d %s", c.Type, c.Codec.Info().SDPName),
+		})
+		if fmtp := codecFMTP(c.Codec); fmtp != "" {
+			attrs = append(attrs, sdp.Attribute{
+				Key: "fmtp", Value: fmt.Sprintf("%d %s", c.Type, fmtp),
+			})
+		}
+	}
 	if audio.DTMFType != 0 {
 		formats = append(formats, strconv.Itoa(int(audio.DTMFType)))
 		attrs = append(attrs, []sdp.Attribute{
-			{Key: "rtpmap", Value: fmt.Sprintf("%d %s", audio.DTMFType, dtmf.SDPName)},
+			{Key: "rtpmap", Value: fmt.Sprintf("%d %s", audio.DTMFType, dtmf.SDPNameWithRate(audio.dtmfClockRate()))},
 			{Key: "fmtp", Value: fmt.Sprintf("%d 0-16", audio.DTMFType)},
 		}...)
 	}
@@ -206,25 +288,29 @@ type Description struct {
 	SDP  sdp.SessionDescription
 	Addr netip.AddrPort
 	MediaDesc
+	// Media lists all media sections in the m-line order, including the main audio section.
+	Media []MediaSection
 }
 
 type Offer Description
 
 type Answer Description
 
-func NewOffer(publicIp netip.Addr, rtpListenerPort int, encrypted Encryption) (*Offer, error) {
-	sessId := rand.Uint64() // TODO: do we need to track these?
+// hasAudio checks if the description has the main audio section.
+func (d *Description) hasAudio() bool {
+	// Descriptions created manually may not list media sections.
+	return len(d.Media) == 0 || slices.ContainsFunc(d.Media, func(s MediaSection) bool {
+		return s.Primary
+	})
+}
 
-	m, mediaDesc, err := OfferMedia(rtpListenerPort, encrypted)
-	if err != nil {
-		return nil, err
-	}
-	offer := sdp.SessionDescription{
+func newSession(publicIp netip.Addr, sessId, version uint64, descs []*sdp.MediaDescription) sdp.SessionDescription {
+	return sdp.SessionDescription{
 		Version: 0,
 		Origin: sdp.Origin{
 			Username:       "-",
 			SessionID:      sessId,
-			SessionVersion: sessId,
+			SessionVersion: version,
 			NetworkType:    "IN",
 			AddressType:    "IP4",
 			UnicastAddress: publicIp.String(),
@@ -243,92 +329,171 @@ func NewOffer(publicIp netip.Addr, rtpListenerPort int, encrypted Encryption) (*
 				},
 			},
 		},
-		MediaDescriptions: []*sdp.MediaDescription{mediaDesc},
+		MediaDescriptions: descs,
+	}
+}
+
+func NewOffer(publicIp netip.Addr, rtpListenerPort int, encrypted Encryption, opts ...Option) (*Offer, error) {
+	sessId := rand.Uint64() // TODO: do we need to track these?
+
+	o := newOptions(opts)
+	m, mediaDesc, err := offerMedia(rtpListenerPort, encrypted, &o)
+	if err != nil {
+		return nil, err
+	}
+	descs, sections, err := offerStreams(publicIp, mediaDesc, &m, encrypted, &o)
+	if err != nil {
+		return nil, err
 	}
+	offer := newSession(publicIp, sessId, sessId, descs)
 	return &Offer{
 		SDP:       offer,
 		Addr:      netip.AddrPortFrom(publicIp, uint16(rtpListenerPort)),
 		MediaDesc: m,
+		Media:     sections,
 	}, nil
 }
 
-func (d *Offer) Answer(publicIp netip.Addr, rtpListenerPort int, enc Encryption) (*Answer, *MediaConfig, error) {
-	audio, err := SelectAudio(d.MediaDesc, false)
+// NewT38Offer creates an offer with only a T.38 fax relay section, for example to switch an existing call to fax.
+func NewT38Offer(publicIp netip.Addr, port int, conf T38Config) *Offer {
+	sessId := rand.Uint64()
+	desc, sec := offerT38(publicIp, 0, &t38Option{port: port, conf: conf})
+	return &Offer{
+		SDP:   newSession(publicIp, sessId, sessId, []*sdp.MediaDescription{desc}),
+		Addr:  sec.Addr,
+		Media: []MediaSection{sec},
+	}
+}
+
+func (d *Offer) Answer(publicIp netip.Addr, rtpListenerPort int, enc Encryption, opts ...Option) (*Answer, *MediaConfig, error) {
+	o := newOptions(opts)
+	src := netip.AddrPortFrom(publicIp, uint16(rtpListenerPort))
+	if !(*Description)(d).hasAudio() {
+		return d.answerT38Only(publicIp, src, enc, &o)
+	}
+	conf, mediaDesc, m, err := answerAudio(&d.MediaDesc, d.Addr, src, enc, &o)
 	if err != nil {
 		return nil, nil, err
 	}
+	descs := []*sdp.MediaDescription{mediaDesc}
+	var sections []MediaSection
+	if len(d.Media) != 0 {
+		descs, sections = d.answerSections(publicIp, mediaDesc, m, conf, enc, &o)
+	}
+	answer := newSession(publicIp, d.SDP.Origin.SessionID, d.SDP.Origin.SessionID+2, descs)
+	return &Answer{
+		SDP:       answer,
+		Addr:      src,
+		MediaDesc: *m,
+		Media:     sections,
+	}, conf, nil
+}
+
+// answerAudio negotiates a single audio section of the offer.
+// It returns media config, media description for the answer, and a description of the accepted media.
+func answerAudio(offer *MediaDesc, remote, local netip.AddrPort, enc Encryption, o *options) (*MediaConfig, *sdp.MediaDescription, *MediaDesc, error) {
+	audio, codecs, reason, err := selectAudio(*offer, false, o)
+	if err != nil {
+		return nil, nil, nil, err
+	}
 
 	var (
 		sconf *srtp.Config
 		sprof *srtp.Profile
+		rprof *srtp.Profile
 	)
-	if len(d.CryptoProfiles) != 0 && enc != EncryptionNone {
+	if len(offer.CryptoProfiles) != 0 && enc != EncryptionNone {
 		answer, err := srtp.DefaultProfiles()
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, nil, err
 		}
-		sconf, sprof, err = SelectCrypto(d.CryptoProfiles, answer, true)
+		sconf, sprof, rprof, err = selectCrypto(offer.CryptoProfiles, answer, true)
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, nil, err
 		}
 	}
 	if sprof == nil && enc == EncryptionRequire {
-		return nil, nil, ErrNoCommonCrypto
+		return nil, nil, nil, ErrNoCommonCrypto
 	}
 
-	mediaDesc := AnswerMedia(rtpListenerPort, audio, sprof)
-	answer := sdp.SessionDescription{
-		Version: 0,
-		Origin: sdp.Origin{
-			Username:       "-",
-			SessionID:      d.SDP.Origin.SessionID,
-			SessionVersion: d.SDP.Origin.SessionID + 2,
-			NetworkType:    "IN",
-			AddressType:    "IP4",
-			UnicastAddress: publicIp.String(),
-		},
-		SessionName: "LiveKit",
-		ConnectionInformation: &sdp.ConnectionInformation{
-			NetworkType: "IN",
-			AddressType: "IP4",
-			Address:     &sdp.Address{Address: publicIp.String()},
-		},
-		TimeDescriptions: []sdp.TimeDescription{
-			{
-				Timing: sdp.Timing{
-					StartTime: 0,
-					StopTime:  0,
-				},
-			},
-		},
-		MediaDescriptions: []*sdp.MediaDescription{mediaDesc},
+	mediaDesc := AnswerMedia(int(local.Port()), audio, sprof, codecs[1:]...)
+	answerCodecs := make([]CodecInfo, 0, len(codecs))
+	for _, c := range codecs {
+		answerCodecs = append(answerCodecs, CodecInfo{Type: c.Type, Codec: c.Codec, FMTP: codecFMTP(c.Codec)})
+	}
+	var cryptoProfiles []srtp.Profile
+	if sprof != nil {
+		cryptoProfiles = []srtp.Profile{*sprof}
+	}
+	return &MediaConfig{
+		Local:        local,
+		Remote:       remote,
+		Audio:        *audio,
+		AudioCodecs:  codecs,
+		AudioReason:  reason,
+		Crypto:       sconf,
+		LocalCrypto:  sprof,
+		RemoteCrypto: rprof,
+	}, mediaDesc, &MediaDesc{
+		Codecs:         answerCodecs,
+		DTMFType:       audio.DTMFType,
+		DTMFTypes:      audio.dtmfTypes(),
+		CryptoProfiles: cryptoProfiles,
+	}, nil
+}
+
+// answerT38Only answers an offer without audio, which must have a T.38 section.
+func (d *Offer) answerT38Only(publicIp netip.Addr, src netip.AddrPort, enc Encryption, o *options) (*Answer, *MediaConfig, error) {
+	if o.t38 == nil {
+		return nil, nil, errors.New("no audio in sdp")
+	}
+	conf := &MediaConfig{}
+	descs, sections := d.answerSections(publicIp, nil, nil, conf, enc, o)
+	if conf.T38 == nil {
+		return nil, nil, ErrNoT38
 	}
-	src := netip.AddrPortFrom(publicIp, uint16(rtpListenerPort))
 	return &Answer{
-			SDP:  answer,
-			Addr: src,
-			MediaDesc: MediaDesc{
-				Codecs: []CodecInfo{
-					{Type: audio.Type, Codec: audio.Codec},
-				},
-				DTMFType: audio.DTMFType,
-			},
-		}, &MediaConfig{
-			Local:  src,
-			Remote: d.Addr,
-			Audio:  *audio,
-			Crypto: sconf,
-		}, nil
+		SDP:   newSession(publicIp, d.SDP.Origin.SessionID, d.SDP.Origin.SessionID+2, descs),
+		Addr:  conf.T38.Local,
+		Media: sections,
+	}, conf, nil
 }
 
-func (d *Answer) Apply(offer *Offer, enc Encryption) (*MediaConfig, error) {
-	audio, err := SelectAudio(d.MediaDesc, true)
+func (d *Answer) Apply(offer *Offer, enc Encryption, opts ...Option) (*MediaConfig, error) {
+	o := newOptions(opts)
+	if !(*Description)(d).hasAudio() {
+		conf := &MediaConfig{}
+		d.applyStreams(offer, conf, enc, &o)
+		if conf.T38 == nil {
+			return nil, ErrNoT38
+		}
+		return conf, nil
+	}
+	conf, err := applyAudio(&d.MediaDesc, &offer.MediaDesc, offer.Addr, d.Addr, enc, &o)
 	if err != nil {
 		return nil, err
 	}
-	var sconf *srtp.Config
-	if len(d.CryptoProfiles) != 0 && enc != EncryptionNone {
-		sconf, _, err = SelectCrypto(offer.CryptoProfiles, d.CryptoProfiles, false)
+	for _, sec := range offer.Media {
+		if sec.Primary {
+			conf.Mid, conf.Label = sec.Mid, sec.Label
+		}
+	}
+	d.applyStreams(offer, conf, enc, &o)
+	return conf, nil
+}
+
+// applyAudio negotiates a single audio section, given the answer and our offer.
+func applyAudio(answer, offer *MediaDesc, local, remote netip.AddrPort, enc Encryption, o *options) (*MediaConfig, error) {
+	audio, codecs, reason, err := selectAudio(*answer, true, o)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		sconf        *srtp.Config
+		sprof, rprof *srtp.Profile
+	)
+	if len(answer.CryptoProfiles) != 0 && enc != EncryptionNone {
+		sconf, sprof, rprof, err = selectCrypto(offer.CryptoProfiles, answer.CryptoProfiles, false)
 		if err != nil {
 			return nil, err
 		}
@@ -337,22 +502,39 @@ func (d *Answer) Apply(offer *Offer, enc Encryption) (*MediaConfig, error) {
 		return nil, ErrNoCommonCrypto
 	}
 	return &MediaConfig{
-		Local:  offer.Addr,
-		Remote: d.Addr,
-		Audio:  *audio,
-		Crypto: sconf,
+		Local:        local,
+		Remote:       remote,
+		Audio:        *audio,
+		AudioCodecs:  codecs,
+		AudioReason:  reason,
+		Crypto:       sconf,
+		LocalCrypto:  sprof,
+		RemoteCrypto: rprof,
 	}, nil
 }
 
-func Parse(data []byte) (*Description, error) {
+func Parse(data []byte, opts ...Option) (*Description, error) {
 	offer := new(Description)
-	if err := offer.SDP.Unmarshal(data); err != nil {
+	if err := Unmarshal(data, &offer.SDP); err != nil {
 		return nil, err
 	}
-	audio := GetAudio(&offer.SDP)
-	if audio == nil {
+	primary := primaryAudio(&offer.SDP)
+	if primary >= 0 && offer.SDP.MediaDescriptions[primary].MediaName.Port.Value == 0 && hasT38(&offer.SDP) {
+		// Audio is disabled when the call switches to fax.
+		primary = -1
+	}
+	if primary < 0 {
+		// Offers that switch the call to fax may only have a T.38 section.
+		offer.Media = parseSections(&offer.SDP, -1, nil, opts)
+		for _, sec := range offer.Media {
+			if sec.T38 != nil {
+				offer.Addr = sec.Addr
+				return offer, nil
+			}
+		}
 		return nil, errors.New("no audio in sdp")
 	}
+	audio := offer.SDP.MediaDescriptions[primary]
 	var err error
 	offer.Addr, err = GetAudioDest(&offer.SDP, audio)
 	if err != nil {
@@ -360,24 +542,25 @@ func Parse(data []byte) (*Description, error) {
 	} else if !offer.Addr.IsValid() || offer.Addr.Port() == 0 {
 		return nil, fmt.Errorf("invalid audio address %q", offer.Addr)
 	}
-	m, err := ParseMedia(audio)
+	m, err := ParseMedia(audio, opts...)
 	if err != nil {
 		return nil, err
 	}
 	offer.MediaDesc = *m
+	offer.Media = parseSections(&offer.SDP, primary, m, opts)
 	return offer, nil
 }
 
-func ParseOffer(data []byte) (*Offer, error) {
-	d, err := Parse(data)
+func ParseOffer(data []byte, opts ...Option) (*Offer, error) {
+	d, err := Parse(data, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return (*Offer)(d), nil
 }
 
-func ParseAnswer(data []byte) (*Answer, error) {
-	d, err := Parse(data)
+func ParseAnswer(data []byte, opts ...Option) (*Answer, error) {
+	d, err := Parse(data, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -412,6 +595,23 @@ func parseLifetime(s string) (uint64, error) {
 	return val, nil
 }
 
+// formatLifetime formats the key lifetime as a power of two, if possible.
+func formatLifetime(v uint64) string {
+	if v&(v-1) == 0 {
+		return "2^" + strconv.Itoa(bits.TrailingZeros64(v))
+	}
+	return strconv.FormatUint(v, 10)
+}
+
+// formatMKI formats MKI encoded in big-endian as "value:length".
+func formatMKI(mki []byte) string {
+	var v uint64
+	for _, b := range mki {
+		v = v<<8 | uint64(b)
+	}
+	return strconv.FormatUint(v, 10) + ":" + strconv.Itoa(len(mki))
+}
+
 // Returns a slice of <= 8 bytes with the MKI value encoded in big-endian.
 func parseMKI(s string) ([]byte, error) {
 	// See RFC4568, section 6.1
@@ -475,20 +675,25 @@ func parseSRTPProfile(val string) (*srtp.Profile, error) {
 		}
 	}
 
-	// Parse optional lifetime parameter (if present)
-	lifetime := uint64(0)
-	if len(parts) > 1 && parts[1] != "" {
-		lifetime, err = parseLifetime(parts[1])
-		if err != nil {
-			return nil, fmt.Errorf("invalid lifetime parameter %q: %v", parts[1], err)
+	// Optional lifetime and MKI parameters. Either can be omitted, but MKI always contains a colon.
+	var (
+		lifetime uint64
+		mki      []byte
+	)
+	for _, part := range parts[1:] {
+		if part == "" {
+			continue
 		}
-	}
-
-	var mki []byte
-	if len(parts) > 2 && parts[2] != "" {
-		mki, err = parseMKI(parts[2])
-		if err != nil {
-			return nil, fmt.Errorf("invalid MKI parameter %q: %v", parts[2], err)
+		if strings.Contains(part, ":") {
+			mki, err = parseMKI(part)
+			if err != nil {
+				return nil, fmt.Errorf("invalid MKI parameter %q: %v", part, err)
+			}
+		} else {
+			lifetime, err = parseLifetime(part)
+			if err != nil {
+				return nil, fmt.Errorf("invalid lifetime parameter %q: %v", part, err)
+			}
 		}
 	}
 
@@ -498,6 +703,13 @@ func parseSRTPProfile(val string) (*srtp.Profile, error) {
 		if err != nil {
 			return nil, err
 		}
+		saltLen, err := sp.SaltLen()
+		if err != nil {
+			return nil, err
+		}
+		if len(keys) != keyLen+saltLen {
+			return nil, fmt.Errorf("invalid key length for %s: expected %d, got %d", prof, keyLen+saltLen, len(keys))
+		}
 		keys, salt = keys[:keyLen], keys[keyLen:]
 	}
 	return &srtp.Profile{
@@ -510,10 +722,24 @@ func parseSRTPProfile(val string) (*srtp.Profile, error) {
 	}, nil
 }
 
-func ParseMedia(d *sdp.MediaDescription) (*MediaDesc, error) {
+func ParseMedia(d *sdp.MediaDescription, opts ...Option) (*MediaDesc, error) {
+	o := newOptions(opts)
 	var out MediaDesc
+	fmtps := make(map[byte]string)
+	rtpmap := make(map[byte]rtp.AudioCodec)
+	var rtpmapTypes []byte
 	for _, m := range d.Attributes {
 		switch m.Key {
+		case "fmtp":
+			sub := strings.SplitN(m.Value, " ", 2)
+			if len(sub) != 2 {
+				continue
+			}
+			typ, err := strconv.Atoi(sub[0])
+			if err != nil {
+				continue
+			}
+			fmtps[byte(typ)] = strings.TrimSpace(sub[1])
 		case "rtpmap":
 			sub := strings.SplitN(m.Value, " ", 2)
 			if len(sub) != 2 {
@@ -524,15 +750,19 @@ func ParseMedia(d *sdp.MediaDescription) (*MediaDesc, error) {
 				continue
 			}
 			name := sub[1]
-			if name == dtmf.SDPName || name == dtmf.SDPName+"/1" {
-				out.DTMFType = byte(typ)
+			if rate, ok := dtmf.ParseSDPName(name); ok {
+				if out.DTMFTypes == nil {
+					out.DTMFTypes = make(map[int]byte)
+				}
+				out.DTMFTypes[rate] = byte(typ)
+				if out.DTMFType == 0 || rate == dtmf.SampleRate {
+					out.DTMFType = byte(typ)
+				}
 				continue
 			}
-			codec, _ := CodecByName(name).(rtp.AudioCodec)
-			out.Codecs = append(out.Codecs, CodecInfo{
-				Type:  byte(typ),
-				Codec: codec,
-			})
+			codec, _ := o.registry.CodecByName(name).(rtp.AudioCodec)
+			rtpmap[byte(typ)] = codec
+			rtpmapTypes = append(rtpmapTypes, byte(typ))
 		case "crypto":
 			p, err := parseSRTPProfile(m.Value)
 			if err != nil {
@@ -543,17 +773,37 @@ func ParseMedia(d *sdp.MediaDescription) (*MediaDesc, error) {
 			out.CryptoProfiles = append(out.CryptoProfiles, *p)
 		}
 	}
+	// Codecs are listed in the order of preference from the media line.
+	listed := make(map[byte]struct{}, len(d.MediaName.Formats))
 	for _, f := range d.MediaName.Formats {
 		typ, err := strconv.Atoi(f)
-		if err != nil {
+		if err != nil || out.isDTMF(byte(typ)) {
 			continue
+		} else if _, ok := listed[byte(typ)]; ok {
+			continue
+		}
+		listed[byte(typ)] = struct{}{}
+		codec := rtpmap[byte(typ)]
+		if codec == nil {
+			if c := o.registry.CodecByPayloadType(byte(typ)); o.registry.Enabled(c) {
+				codec, _ = c.(rtp.AudioCodec)
+			}
 		}
-		codec, _ := rtp.CodecByPayloadType(byte(typ)).(rtp.AudioCodec)
 		out.Codecs = append(out.Codecs, CodecInfo{
 			Type:  byte(typ),
 			Codec: codec,
 		})
 	}
+	// Tolerate rtpmap entries that are missing from the media line.
+	for _, typ := range rtpmapTypes {
+		if _, ok := listed[typ]; !ok {
+			listed[typ] = struct{}{}
+			out.Codecs = append(out.Codecs, CodecInfo{Type: typ, Codec: rtpmap[typ]})
+		}
+	}
+	for i := range out.Codecs {
+		out.Codecs[i].FMTP = fmtps[out.Codecs[i].Type]
+	}
 	return &out, nil
 }
 
@@ -561,48 +811,173 @@ type MediaConfig struct {
 	Local  netip.AddrPort
 	Remote netip.AddrPort
 	Audio  AudioConfig
-	Crypto *srtp.Config
+	// AudioCodecs lists all negotiated audio codecs, starting with the selected one.
+	// The remote may switch between them without renegotiation, see rtp.NewAudioDecoder.
+	AudioCodecs []rtp.PayloadCodec
+	// Mid and Label are set from a=mid and a=label of the media section, if present.
+	Mid   string
+	Label string
+	// Streams lists additional audio streams negotiated in other media sections. See WithAudioStreams.
+	Streams []*MediaConfig
+	// AudioReason explains why the audio codec was selected, according to the codec policy.
+	AudioReason string
+	Crypto      *srtp.Config
+	// LocalCrypto and RemoteCrypto describe master keys used in Crypto, including MKI and key lifetime.
+	// They can be passed to srtp.WithProfiles, or installed into an existing session after a re-offer.
+	LocalCrypto  *srtp.Profile
+	RemoteCrypto *srtp.Profile
+	// T38 is set if a T.38 fax relay section is negotiated. Audio fields are empty if the session has no audio.
+	T38 *T38MediaConfig
 }
 
 type AudioConfig struct {
 	Codec    rtp.AudioCodec
 	Type     byte
 	DTMFType byte
+	// DTMFClockRate is the RTP clock rate of telephone events. It matches the audio codec clock rate, if the remote supports it.
+	DTMFClockRate int
+}
+
+func (c *AudioConfig) dtmfClockRate() int {
+	if c.DTMFClockRate == 0 {
+		return dtmf.SampleRate
+	}
+	return c.DTMFClockRate
+}
+
+func (c *AudioConfig) dtmfTypes() map[int]byte {
+	if c.DTMFType == 0 {
+		return nil
+	}
+	return map[int]byte{c.dtmfClockRate(): c.DTMFType}
+}
+
+func (d *MediaDesc) isDTMF(typ byte) bool {
+	if d.DTMFType != 0 && typ == d.DTMFType {
+		return true
+	}
+	for _, t := range d.DTMFTypes {
+		if t == typ {
+			return true
+		}
+	}
+	return false
 }
 
-func SelectAudio(desc MediaDesc, answer bool) (*AudioConfig, error) {
+// selectDTMF selects telephone events with a given clock rate.
+// If there's no match, it falls back to the default DTMF type.
+func (d *MediaDesc) selectDTMF(rate int) (byte, int) {
+	if typ, ok := d.DTMFTypes[rate]; ok {
+		return typ, rate
+	}
+	if d.DTMFType == 0 {
+		return 0, 0
+	}
+	for r, typ := range d.DTMFTypes {
+		if typ == d.DTMFType {
+			return typ, r
+		}
+	}
+	return d.DTMFType, dtmf.SampleRate
+}
+
+func SelectAudio(desc MediaDesc, answer bool, opts ...Option) (*AudioConfig, error) {
+	o := newOptions(opts)
+	audio, _, _, err := selectAudio(desc, answer, &o)
+	return audio, err
+}
+
+// selectAudio selects an audio codec according to the codec policy. It also returns the reason for the selection.
+//
+// All common codecs are returned as well, with the selected codec first and others in the remote order.
+func selectAudio(desc MediaDesc, answer bool, o *options) (*AudioConfig, []rtp.PayloadCodec, string, error) {
+	pref := &o.pref
 	var (
 		priority   int
 		audioCodec rtp.AudioCodec
 		audioType  byte
+		selected   bool
+		codecs     []rtp.PayloadCodec
 	)
 	for _, c := range desc.Codecs {
 		codec, ok := c.Codec.(rtp.AudioCodec)
-		if !ok {
+		if !ok || !pref.allowed(codec) {
 			continue
 		}
-		if audioCodec == nil || codec.Info().Priority > priority {
+		if fc, ok := codec.(rtp.FMTPCodec); ok {
+			var err error
+			codec, err = fc.WithFMTP(c.FMTP)
+			if err != nil {
+				continue // incompatible parameters
+			}
+		}
+		codecs = append(codecs, rtp.PayloadCodec{Type: c.Type, Codec: codec})
+		if selected {
+			continue
+		}
+		prio := o.registry.Priority(codec)
+		better := audioCodec == nil
+		if !better {
+			v := 0
+			if pref.Policy != PolicyPriority {
+				v = pref.compareListed(codec, audioCodec)
+			}
+			// Codecs that are not in the preference list fall back to priority, unless we follow the answer order.
+			better = v < 0 || (v == 0 && !answer && prio > priority)
+		}
+		if better {
 			audioType = c.Type
 			audioCodec = codec
-			priority = codec.Info().Priority
+			priority = prio
 		}
-		if answer {
-			break
+		if pref.Policy == PolicyTheirsFirst || (answer && pref.Policy == PolicyPriority) {
+			selected = true
 		}
 	}
 	if audioCodec == nil {
-		return nil, ErrNoCommonMedia
+		return nil, nil, "", ErrNoCommonMedia
+	}
+	if i := slices.IndexFunc(codecs, func(c rtp.PayloadCodec) bool {
+		return c.Type == audioType
+	}); i > 0 {
+		sel := codecs[i]
+		codecs = slices.Delete(codecs, i, i+1)
+		codecs = slices.Insert(codecs, 0, sel)
 	}
+	remote := "offer"
+	if answer {
+		remote = "answer"
+	}
+	var reason string
+	switch i := pref.index(audioCodec); {
+	case pref.Policy == PolicyTheirsFirst:
+		reason = "first supported codec in the " + remote
+	case pref.Policy != PolicyPriority && i >= 0:
+		reason = fmt.Sprintf("position %d in the preference list", i+1)
+	case answer:
+		reason = "first codec in the answer"
+	default:
+		reason = "highest priority in the offer"
+	}
+	reason = fmt.Sprintf("%s selected by %s policy: %s", audioCodec.Info().SDPName, pref.Policy, reason)
+	dtmfType, dtmfRate := desc.selectDTMF(audioCodec.Info().RTPClockRate)
 	return &AudioConfig{
-		Codec:    audioCodec,
-		Type:     audioType,
-		DTMFType: desc.DTMFType,
-	}, nil
+		Codec:         audioCodec,
+		Type:          audioType,
+		DTMFType:      dtmfType,
+		DTMFClockRate: dtmfRate,
+	}, codecs, reason, nil
 }
 
 func SelectCrypto(offer, answer []srtp.Profile, swap bool) (*srtp.Config, *srtp.Profile, error) {
+	c, local, _, err := selectCrypto(offer, answer, swap)
+	return c, local, err
+}
+
+// selectCrypto selects a common crypto profile. Returned profiles describe local and remote master keys.
+func selectCrypto(offer, answer []srtp.Profile, swap bool) (*srtp.Config, *srtp.Profile, *srtp.Profile, error) {
 	if len(offer) == 0 {
-		return nil, nil, nil
+		return nil, nil, nil, nil
 	}
 	for _, ans := range answer {
 		sp, err := ans.Profile.Parse()
@@ -641,14 +1016,14 @@ func SelectCrypto(offer, answer []srtp.Profile, swap bool) (*srtp.Config, *srtp.
 				c.RemoteOptions = append(c.RemoteOptions, srtp.MasterKeyIndicator(remoteMKI))
 			}
 
-			prof := &off
+			local, remote := &off, &ans
 			if swap {
-				prof = &ans
+				local, remote = &ans, &off
 				// Echo the cipher suite tag of the offer, in the answer
-				prof.Index = off.Index
+				local.Index = off.Index
 			}
-			return c, prof, nil
+			return c, local, remote, nil
 		}
 	}
-	return nil, nil, nil
+	return nil, nil, nil, nil
 }
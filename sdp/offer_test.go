@@ -15,6 +15,7 @@
 package sdp_test
 
 import (
+	"bytes"
 	"net"
 	"net/netip"
 	"slices"
@@ -27,6 +28,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/livekit/media-sdk"
+	"github.com/livekit/media-sdk/dtmf"
 	"github.com/livekit/media-sdk/g711"
 	"github.com/livekit/media-sdk/g722"
 	"github.com/livekit/media-sdk/rtp"
@@ -89,6 +91,8 @@ func TestSDPMediaOffer(t *testing.T) {
 			{Key: "crypto", Value: "2 AES_CM_128_HMAC_SHA1_32 inline:" + getInline(offer.Attributes[i+1].Value)},
 			{Key: "crypto", Value: "3 AES_256_CM_HMAC_SHA1_80 inline:" + getInline(offer.Attributes[i+2].Value)},
 			{Key: "crypto", Value: "4 AES_256_CM_HMAC_SHA1_32 inline:" + getInline(offer.Attributes[i+3].Value)},
+			{Key: "crypto", Value: "5 AEAD_AES_128_GCM inline:" + getInline(offer.Attributes[i+4].Value)},
+			{Key: "crypto", Value: "6 AEAD_AES_256_GCM inline:" + getInline(offer.Attributes[i+5].Value)},
 			{Key: "ptime", Value: "20"},
 			{Key: "sendrecv"},
 		},
@@ -141,9 +145,10 @@ func TestSDPMediaAnswer(t *testing.T) {
 				},
 			},
 			exp: &AudioConfig{
-				Codec:    getCodec(g722.SDPName),
-				Type:     9,
-				DTMFType: 101,
+				Codec:         getCodec(g722.SDPName),
+				Type:          9,
+				DTMFType:      101,
+				DTMFClockRate: 8000,
 			},
 		},
 		{
@@ -159,9 +164,10 @@ func TestSDPMediaAnswer(t *testing.T) {
 				},
 			},
 			exp: &AudioConfig{
-				Codec:    getCodec(g722.SDPName),
-				Type:     9,
-				DTMFType: 101,
+				Codec:         getCodec(g722.SDPName),
+				Type:          9,
+				DTMFType:      101,
+				DTMFClockRate: 8000,
 			},
 		},
 		{
@@ -193,9 +199,10 @@ func TestSDPMediaAnswer(t *testing.T) {
 				},
 			},
 			exp: &AudioConfig{
-				Codec:    getCodec(g722.SDPName),
-				Type:     9,
-				DTMFType: 103,
+				Codec:         getCodec(g722.SDPName),
+				Type:          9,
+				DTMFType:      103,
+				DTMFClockRate: 8000,
 			},
 		},
 		{
@@ -210,9 +217,10 @@ func TestSDPMediaAnswer(t *testing.T) {
 				},
 			},
 			exp: &AudioConfig{
-				Codec:    getCodec(g711.ULawSDPName),
-				Type:     0,
-				DTMFType: 101,
+				Codec:         getCodec(g711.ULawSDPName),
+				Type:          0,
+				DTMFType:      101,
+				DTMFClockRate: 8000,
 			},
 		},
 		{
@@ -227,9 +235,10 @@ func TestSDPMediaAnswer(t *testing.T) {
 				},
 			},
 			exp: &AudioConfig{
-				Codec:    getCodec(g722.SDPName),
-				Type:     9,
-				DTMFType: 101,
+				Codec:         getCodec(g722.SDPName),
+				Type:          9,
+				DTMFType:      101,
+				DTMFClockRate: 8000,
 			},
 		},
 		{
@@ -256,9 +265,10 @@ func TestSDPMediaAnswer(t *testing.T) {
 				},
 			},
 			exp: &AudioConfig{
-				Codec:    getCodec(g711.ULawSDPName),
-				Type:     0,
-				DTMFType: 101,
+				Codec:         getCodec(g711.ULawSDPName),
+				Type:          0,
+				DTMFType:      101,
+				DTMFClockRate: 8000,
 			},
 		},
 		{
@@ -270,9 +280,10 @@ func TestSDPMediaAnswer(t *testing.T) {
 				},
 			},
 			exp: &AudioConfig{
-				Codec:    getCodec(g711.ULawSDPName),
-				Type:     0,
-				DTMFType: 101,
+				Codec:         getCodec(g711.ULawSDPName),
+				Type:          0,
+				DTMFType:      101,
+				DTMFClockRate: 8000,
 			},
 		},
 		{
@@ -291,6 +302,43 @@ func TestSDPMediaAnswer(t *testing.T) {
 				Type:  0,
 			},
 		},
+		{
+			name: "wideband dtmf",
+			offer: sdp.MediaDescription{
+				MediaName: sdp.MediaName{
+					Formats: []string{"9", "102", "101"},
+				},
+				Attributes: []sdp.Attribute{
+					{Key: "rtpmap", Value: "9 G722/8000"},
+					{Key: "rtpmap", Value: "102 telephone-event/16000"},
+					{Key: "rtpmap", Value: "101 telephone-event/8000"},
+				},
+			},
+			exp: &AudioConfig{
+				Codec:         getCodec(g722.SDPName),
+				Type:          9,
+				DTMFType:      101,
+				DTMFClockRate: 8000,
+			},
+		},
+		{
+			name: "wideband dtmf only",
+			offer: sdp.MediaDescription{
+				MediaName: sdp.MediaName{
+					Formats: []string{"9", "102"},
+				},
+				Attributes: []sdp.Attribute{
+					{Key: "rtpmap", Value: "9 G722/8000"},
+					{Key: "rtpmap", Value: "102 telephone-event/16000/1"},
+				},
+			},
+			exp: &AudioConfig{
+				Codec:         getCodec(g722.SDPName),
+				Type:          9,
+				DTMFType:      102,
+				DTMFClockRate: 16000,
+			},
+		},
 		{
 			name: "changed order g711",
 			offer: sdp.MediaDescription{
@@ -344,6 +392,328 @@ func TestSDPMediaAnswer(t *testing.T) {
 	}, offer)
 }
 
+func TestParseMediaFMTP(t *testing.T) {
+	m, err := ParseMedia(&sdp.MediaDescription{
+		MediaName: sdp.MediaName{
+			Formats: []string{"9", "101"},
+		},
+		Attributes: []sdp.Attribute{
+			{Key: "fmtp", Value: "9 foo=bar; baz"},
+			{Key: "rtpmap", Value: "9 G722/8000"},
+			{Key: "rtpmap", Value: "101 telephone-event/8000"},
+			{Key: "fmtp", Value: "101 0-16"},
+		},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, m.Codecs)
+	for _, c := range m.Codecs {
+		if c.Type == 9 {
+			require.Equal(t, "foo=bar; baz", c.FMTP)
+		}
+	}
+}
+
+func TestRegistryOption(t *testing.T) {
+	reg := media.DefaultRegistry().Clone()
+	reg.SetEnabled(g722.SDPName, false)
+	reg.SetPriority(g711.ALawSDPName, 10)
+	opt := WithRegistry(reg)
+
+	codecs := OfferCodecs(opt)
+	names := make([]string, 0, len(codecs))
+	for _, c := range codecs {
+		names = append(names, c.Codec.Info().SDPName)
+	}
+	require.NotContains(t, names, g722.SDPName)
+	require.Equal(t, g711.ALawSDPName, names[0])
+	require.Equal(t, g722.SDPName, OfferCodecs()[0].Codec.Info().SDPName)
+
+	desc := &sdp.MediaDescription{
+		MediaName: sdp.MediaName{
+			Formats: []string{"9", "0", "8", "101"},
+		},
+		Attributes: []sdp.Attribute{
+			{Key: "rtpmap", Value: "101 telephone-event/8000"},
+		},
+	}
+	m, err := ParseMedia(desc, opt)
+	require.NoError(t, err)
+	audio, err := SelectAudio(*m, false, opt)
+	require.NoError(t, err)
+	require.Equal(t, byte(8), audio.Type)
+
+	m, err = ParseMedia(desc)
+	require.NoError(t, err)
+	audio, err = SelectAudio(*m, false)
+	require.NoError(t, err)
+	require.Equal(t, byte(9), audio.Type)
+}
+
+func TestCodecPreference(t *testing.T) {
+	offerNames := func(opts ...Option) []string {
+		var names []string
+		for _, c := range OfferCodecs(opts...) {
+			names = append(names, c.Codec.Info().SDPName)
+		}
+		return names
+	}
+	def := offerNames()
+	require.Equal(t, g722.SDPName, def[0])
+
+	names := offerNames(WithCodecPreference(PolicyOursFirst, "pcma/8000", "PCMU/8000/1"))
+	require.Equal(t, []string{g711.ALawSDPName, g711.ULawSDPName, g722.SDPName}, names[:3])
+	require.Len(t, names, len(def))
+
+	names = offerNames(WithCodecPreference(PolicyStrict, g711.ALawSDPName, g711.ULawSDPName))
+	require.Equal(t, []string{g711.ALawSDPName, g711.ULawSDPName, dtmf.SDPName}, names)
+
+	offer := MediaDesc{
+		Codecs: []CodecInfo{
+			{Type: 0, Codec: getCodec(g711.ULawSDPName)},
+			{Type: 9, Codec: getCodec(g722.SDPName)},
+			{Type: 8, Codec: getCodec(g711.ALawSDPName)},
+		},
+		DTMFType: 101,
+	}
+	cases := []struct {
+		name   string
+		opts   []Option
+		answer bool
+		exp    byte
+		reason string
+		err    bool
+	}{
+		{name: "priority", exp: 9, reason: "G722/8000 selected by priority policy: highest priority in the offer"},
+		{name: "priority answer", answer: true, exp: 0, reason: "PCMU/8000 selected by priority policy: first codec in the answer"},
+		{
+			name:   "ours first",
+			opts:   []Option{WithCodecPreference(PolicyOursFirst, "opus/48000/2", g711.ALawSDPName, g711.ULawSDPName)},
+			exp:    8,
+			reason: "PCMA/8000 selected by ours-first policy: position 2 in the preference list",
+		},
+		{
+			name:   "ours first answer",
+			opts:   []Option{WithCodecPreference(PolicyOursFirst, g711.ALawSDPName)},
+			answer: true,
+			exp:    8,
+			reason: "PCMA/8000 selected by ours-first policy: position 1 in the preference list",
+		},
+		{
+			name:   "ours first unlisted",
+			opts:   []Option{WithCodecPreference(PolicyOursFirst, "opus/48000/2")},
+			exp:    9,
+			reason: "G722/8000 selected by ours-first policy: highest priority in the offer",
+		},
+		{
+			name:   "theirs first",
+			opts:   []Option{WithCodecPreference(PolicyTheirsFirst, g711.ALawSDPName)},
+			exp:    0,
+			reason: "PCMU/8000 selected by theirs-first policy: first supported codec in the offer",
+		},
+		{
+			name:   "strict",
+			opts:   []Option{WithCodecPreference(PolicyStrict, g711.ALawSDPName, g722.SDPName)},
+			answer: true,
+			exp:    8,
+			reason: "PCMA/8000 selected by strict policy: position 1 in the preference list",
+		},
+		{
+			name: "strict no match",
+			opts: []Option{WithCodecPreference(PolicyStrict, "opus/48000/2")},
+			err:  true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			audio, err := SelectAudio(offer, c.answer, c.opts...)
+			if c.err {
+				require.ErrorIs(t, err, ErrNoCommonMedia)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.exp, audio.Type)
+			require.Equal(t, byte(101), audio.DTMFType)
+
+			var d Description
+			d.MediaDesc = offer
+			var conf *MediaConfig
+			if c.answer {
+				conf, err = (*Answer)(&d).Apply(&Offer{}, EncryptionNone, c.opts...)
+			} else {
+				_, conf, err = (*Offer)(&d).Answer(netip.MustParseAddr("1.1.1.1"), 10000, EncryptionNone, c.opts...)
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.exp, conf.Audio.Type)
+			require.Equal(t, c.reason, conf.AudioReason)
+		})
+	}
+}
+
+func TestAnswerMultipleCodecs(t *testing.T) {
+	types := func(codecs []rtp.PayloadCodec) []byte {
+		var out []byte
+		for _, c := range codecs {
+			out = append(out, c.Type)
+		}
+		return out
+	}
+	offer, err := NewOffer(netip.MustParseAddr("127.0.0.1"), 5000, EncryptionNone)
+	require.NoError(t, err)
+
+	answer, conf, err := offer.Answer(netip.MustParseAddr("127.0.0.1"), 5001, EncryptionNone,
+		WithCodecPreference(PolicyOursFirst, g711.ULawSDPName))
+	require.NoError(t, err)
+	require.Equal(t, byte(0), conf.Audio.Type)
+	require.Equal(t, []byte{0, 9, 8}, types(conf.AudioCodecs))
+	require.Equal(t, []string{"0", "9", "8", "101"}, answer.SDP.MediaDescriptions[0].MediaName.Formats)
+
+	data, err := answer.SDP.Marshal()
+	require.NoError(t, err)
+	panswer, err := ParseAnswer(data)
+	require.NoError(t, err)
+	conf, err = panswer.Apply(offer, EncryptionNone)
+	require.NoError(t, err)
+	require.Equal(t, byte(0), conf.Audio.Type)
+	require.Equal(t, byte(101), conf.Audio.DTMFType)
+	require.Equal(t, []byte{0, 9, 8}, types(conf.AudioCodecs))
+
+	_, conf, err = offer.Answer(netip.MustParseAddr("127.0.0.1"), 5001, EncryptionNone,
+		WithCodecPreference(PolicyStrict, g711.ALawSDPName))
+	require.NoError(t, err)
+	require.Equal(t, []byte{8}, types(conf.AudioCodecs))
+}
+
+func TestAnswerWidebandDTMF(t *testing.T) {
+	d, err := Parse([]byte(`v=0
+o=- 1 1 IN IP4 127.0.0.1
+s=-
+c=IN IP4 127.0.0.1
+t=0 0
+m=audio 5000 RTP/AVP 9 102
+a=rtpmap:9 G722/8000
+a=rtpmap:102 telephone-event/16000
+a=fmtp:102 0-16
+`))
+	require.NoError(t, err)
+	require.Equal(t, map[int]byte{16000: 102}, d.DTMFTypes)
+	answer, conf, err := (*Offer)(d).Answer(netip.MustParseAddr("127.0.0.1"), 5001, EncryptionNone)
+	require.NoError(t, err)
+	require.Equal(t, byte(102), conf.Audio.DTMFType)
+	require.Equal(t, 16000, conf.Audio.DTMFClockRate)
+	require.Contains(t, answer.SDP.MediaDescriptions[0].Attributes, sdp.Attribute{Key: "rtpmap", Value: "102 telephone-event/16000"})
+	require.Equal(t, map[int]byte{16000: 102}, answer.DTMFTypes)
+}
+
+func TestMultipleMediaSections(t *testing.T) {
+	offer, err := ParseOffer([]byte("v=0\r\n" +
+		"o=- 1 1 IN IP4 10.0.0.1\r\n" +
+		"s=-\r\n" +
+		"c=IN IP4 10.0.0.1\r\n" +
+		"t=0 0\r\n" +
+		"m=video 6000 RTP/AVP 96\r\n" +
+		"a=rtpmap:96 H264/90000\r\n" +
+		"a=mid:v\r\n" +
+		"m=audio 0 RTP/AVP 0\r\n" +
+		"a=mid:off\r\n" +
+		"m=audio 5000 RTP/AVP 0 101\r\n" +
+		"a=rtpmap:0 PCMU/8000\r\n" +
+		"a=rtpmap:101 telephone-event/8000\r\n" +
+		"a=mid:1\r\n" +
+		"a=label:caller\r\n" +
+		"m=audio 5002 RTP/AVP 8\r\n" +
+		"a=rtpmap:8 PCMA/8000\r\n" +
+		"a=mid:2\r\n" +
+		"a=label:callee\r\n" +
+		"m=image 7000 udptl t38\r\n" +
+		"m=audio 5004 RTP/AVP 9\r\n" +
+		"a=mid:3\r\n"))
+	require.NoError(t, err)
+	require.Equal(t, netip.MustParseAddrPort("10.0.0.1:5000"), offer.Addr)
+	require.Len(t, offer.Media, 6)
+	kinds := make([]string, 0, len(offer.Media))
+	for _, sec := range offer.Media {
+		kinds = append(kinds, sec.Kind)
+	}
+	require.Equal(t, []string{"video", "audio", "audio", "audio", "image", "audio"}, kinds)
+	require.True(t, offer.Media[1].Rejected())
+	require.True(t, offer.Media[2].Primary)
+	require.Equal(t, "caller", offer.Media[2].Label)
+	require.NotNil(t, offer.Media[3].Audio)
+	require.Nil(t, offer.Media[4].Audio)
+
+	ip := netip.MustParseAddr("10.0.0.2")
+	answer, conf, err := offer.Answer(ip, 8000, EncryptionNone, WithAudioStreams(AudioStream{Port: 8002}))
+	require.NoError(t, err)
+	require.Equal(t, "1", conf.Mid)
+	require.Equal(t, "caller", conf.Label)
+	require.Equal(t, byte(0), conf.Audio.Type)
+	require.Len(t, conf.Streams, 1)
+	require.Equal(t, "2", conf.Streams[0].Mid)
+	require.Equal(t, "callee", conf.Streams[0].Label)
+	require.Equal(t, byte(8), conf.Streams[0].Audio.Type)
+	require.Equal(t, netip.MustParseAddrPort("10.0.0.2:8002"), conf.Streams[0].Local)
+	require.Equal(t, netip.MustParseAddrPort("10.0.0.1:5002"), conf.Streams[0].Remote)
+
+	descs := answer.SDP.MediaDescriptions
+	require.Len(t, descs, 6)
+	ports := make([]int, 0, len(descs))
+	for i, m := range descs {
+		require.Equal(t, kinds[i], m.MediaName.Media)
+		ports = append(ports, m.MediaName.Port.Value)
+	}
+	// Last audio section is rejected, since there's no stream left for it.
+	require.Equal(t, []int{0, 0, 8000, 8002, 0, 0}, ports)
+	mid, _ := descs[0].Attribute("mid")
+	require.Equal(t, "v", mid)
+	label, _ := descs[3].Attribute("label")
+	require.Equal(t, "callee", label)
+	require.Equal(t, []string{"t38"}, descs[4].MediaName.Formats)
+
+	data, err := answer.SDP.Marshal()
+	require.NoError(t, err)
+	panswer, err := ParseAnswer(data)
+	require.NoError(t, err)
+	require.Equal(t, netip.MustParseAddrPort("10.0.0.2:8000"), panswer.Addr)
+	require.Len(t, panswer.Media, 6)
+}
+
+func TestOfferAudioStreams(t *testing.T) {
+	ip := netip.MustParseAddr("10.0.0.1")
+	offer, err := NewOffer(ip, 5000, EncryptionNone, WithAudioStreams(AudioStream{Port: 5002, Label: "callee"}))
+	require.NoError(t, err)
+	descs := offer.SDP.MediaDescriptions
+	require.Len(t, descs, 2)
+	mid, _ := descs[0].Attribute("mid")
+	require.Equal(t, "0", mid)
+	mid, _ = descs[1].Attribute("mid")
+	require.Equal(t, "1", mid)
+	label, _ := descs[1].Attribute("label")
+	require.Equal(t, "callee", label)
+	require.Equal(t, 5002, descs[1].MediaName.Port.Value)
+
+	data, err := offer.SDP.Marshal()
+	require.NoError(t, err)
+	poffer, err := ParseOffer(data)
+	require.NoError(t, err)
+	answer, conf, err := poffer.Answer(netip.MustParseAddr("10.0.0.2"), 6000, EncryptionNone, WithAudioStreams(AudioStream{Port: 6002}))
+	require.NoError(t, err)
+	require.Len(t, conf.Streams, 1)
+
+	data, err = answer.SDP.Marshal()
+	require.NoError(t, err)
+	panswer, err := ParseAnswer(data)
+	require.NoError(t, err)
+	conf, err = panswer.Apply(offer, EncryptionNone)
+	require.NoError(t, err)
+	require.Equal(t, "0", conf.Mid)
+	require.Equal(t, netip.MustParseAddrPort("10.0.0.2:6000"), conf.Remote)
+	require.Len(t, conf.Streams, 1)
+	require.Equal(t, "1", conf.Streams[0].Mid)
+	require.Equal(t, "callee", conf.Streams[0].Label)
+	require.Equal(t, netip.MustParseAddrPort("10.0.0.1:5002"), conf.Streams[0].Local)
+	require.Equal(t, netip.MustParseAddrPort("10.0.0.2:6002"), conf.Streams[0].Remote)
+}
+
 func TestParseOffer(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -445,12 +815,14 @@ func TestParseOfferSRTP(t *testing.T) {
 		{
 			Index:   5,
 			Profile: "AEAD_AES_128_GCM",
-			Key:     []uint8{0x80, 0x67, 0xad, 0x12, 0x44, 0x20, 0x1a, 0x4e, 0xd, 0x64, 0x8a, 0xa, 0x8f, 0xf7, 0x1b, 0x16, 0x94, 0x64, 0x1d, 0xda, 0x1c, 0x98, 0xa9, 0x4f, 0xd2, 0xed, 0xd5, 0x33},
+			Key:     []uint8{0x80, 0x67, 0xad, 0x12, 0x44, 0x20, 0x1a, 0x4e, 0xd, 0x64, 0x8a, 0xa, 0x8f, 0xf7, 0x1b, 0x16},
+			Salt:    []uint8{0x94, 0x64, 0x1d, 0xda, 0x1c, 0x98, 0xa9, 0x4f, 0xd2, 0xed, 0xd5, 0x33},
 		},
 		{
 			Index:   6,
 			Profile: "AEAD_AES_256_GCM",
-			Key:     []uint8{0x10, 0x51, 0x73, 0x4b, 0x61, 0x4c, 0xc8, 0xda, 0x18, 0x71, 0x57, 0x1a, 0x1, 0x15, 0x3e, 0x9e, 0xf9, 0x3e, 0x26, 0x11, 0xe6, 0x55, 0xbb, 0xdd, 0x16, 0xd4, 0x71, 0x66, 0xe4, 0x62, 0xf6, 0xb0, 0xe6, 0x2d, 0x8a, 0x4b, 0x9a, 0xce, 0x72, 0x4a, 0xff, 0x77, 0x8b, 0x2d},
+			Key:     []uint8{0x10, 0x51, 0x73, 0x4b, 0x61, 0x4c, 0xc8, 0xda, 0x18, 0x71, 0x57, 0x1a, 0x1, 0x15, 0x3e, 0x9e, 0xf9, 0x3e, 0x26, 0x11, 0xe6, 0x55, 0xbb, 0xdd, 0x16, 0xd4, 0x71, 0x66, 0xe4, 0x62, 0xf6, 0xb0},
+			Salt:    []uint8{0xe6, 0x2d, 0x8a, 0x4b, 0x9a, 0xce, 0x72, 0x4a, 0xff, 0x77, 0x8b, 0x2d},
 		},
 	}
 
@@ -548,6 +920,71 @@ a=crypto:1 AES_CM_128_HMAC_SHA1_80 inline:pMIPxjzYIG5TQuIWfkjTnaACVrzohhFfOGhSMg
 	require.Equal(t, 4, len(profile.MKI), "MKI length should be 4 bytes")
 }
 
+// TestParseOfferBadCryptoKey verifies that crypto attributes with a key length not matching the profile are rejected.
+func TestParseOfferBadCryptoKey(t *testing.T) {
+	const sdpData = `v=0 
+o=Test 1 1 IN IP4 127.0.0.1 
+s=Stream1 
+t=0 0 
+m=audio 5000 RTP/SAVP 0 101 
+c=IN IP4 127.0.0.1 
+a=rtpmap:0 PCMU/8000 
+a=rtpmap:101 telephone-event/8000 
+a=sendrecv 
+a=ptime:20 
+a=crypto:1 AES_256_CM_HMAC_SHA1_80 inline:pMIPxjzYIG5TQuIWfkjTnaACVrzohhFfOGhSMgV1 
+`
+
+	_, err := ParseOffer([]byte(sdpData))
+	require.ErrorContains(t, err, "invalid key length")
+}
+
+func TestCryptoLifetimeAndMKI(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 16)
+	salt := bytes.Repeat([]byte{2}, 14)
+	for _, c := range []struct {
+		name     string
+		lifetime uint64
+		mki      []byte
+		exp      string
+	}{
+		{name: "none", exp: ""},
+		{name: "lifetime", lifetime: 1 << 31, exp: "|2^31"},
+		{name: "lifetime decimal", lifetime: 1000, exp: "|1000"},
+		{name: "mki", mki: []byte{0, 5}, exp: "|5:2"},
+		{name: "both", lifetime: 1 << 20, mki: []byte{1, 0, 2}, exp: "|2^20|65538:3"},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			p := srtp.Profile{Index: 1, Profile: "AES_CM_128_HMAC_SHA1_80", Key: key, Salt: salt, MKI: c.mki, Lifetime: c.lifetime}
+			m := AnswerMedia(5000, &AudioConfig{Codec: getCodec(g711.ULawSDPName), DTMFType: 101}, &p)
+			i := slices.IndexFunc(m.Attributes, func(a sdp.Attribute) bool {
+				return a.Key == "crypto"
+			})
+			require.True(t, i >= 0)
+			require.True(t, strings.HasSuffix(m.Attributes[i].Value, c.exp), m.Attributes[i].Value)
+			got, err := ParseMedia(m)
+			require.NoError(t, err)
+			require.Equal(t, []srtp.Profile{p}, got.CryptoProfiles)
+		})
+	}
+
+	offer, err := NewOffer(netip.MustParseAddr("127.0.0.1"), 5000, EncryptionRequire)
+	require.NoError(t, err)
+	for i := range offer.CryptoProfiles {
+		offer.CryptoProfiles[i].MKI = []byte{1}
+		offer.CryptoProfiles[i].Lifetime = 1 << 31
+	}
+	_, conf, err := offer.Answer(netip.MustParseAddr("127.0.0.1"), 5001, EncryptionRequire)
+	require.NoError(t, err)
+	require.NotNil(t, conf.LocalCrypto)
+	require.NotNil(t, conf.RemoteCrypto)
+	require.Equal(t, conf.Crypto.Keys.LocalMasterKey, conf.LocalCrypto.Key)
+	require.Equal(t, conf.Crypto.Keys.RemoteMasterKey, conf.RemoteCrypto.Key)
+	require.Equal(t, []byte{1}, conf.RemoteCrypto.MKI)
+	require.EqualValues(t, 1<<31, conf.RemoteCrypto.Lifetime)
+	require.Nil(t, conf.LocalCrypto.MKI)
+}
+
 // TestSelectCryptoSuiteTag ensures that when selecting a crypto suite from an offer/answer pair,
 // the answer uses the same crypto suite tag as the offer, per RFC 4568 section 5.1.2 and 5.1.3.
 func TestSelectCryptoSuiteTag(t *testing.T) {
@@ -0,0 +1,77 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sdp
+
+import (
+	"github.com/livekit/media-sdk"
+)
+
+type options struct {
+	registry *media.Registry
+	pref     CodecPreference
+	streams  []AudioStream
+	t38      *t38Option
+}
+
+type t38Option struct {
+	port int
+	conf T38Config
+}
+
+// Option configures codec selection for SDP offers and answers.
+type Option func(o *options)
+
+// WithRegistry sets a codec registry used to offer, parse and select codecs. Default registry is used if not set.
+func WithRegistry(r *media.Registry) Option {
+	return func(o *options) {
+		o.registry = r
+	}
+}
+
+// WithCodecPreference sets the codec preference list and a policy for applying it.
+// Codecs are identified by SDP names, for example "PCMA/8000".
+func WithCodecPreference(policy CodecPolicy, codecs ...string) Option {
+	return func(o *options) {
+		o.pref = CodecPreference{Policy: policy, Codecs: codecs}
+	}
+}
+
+// WithAudioStreams adds audio streams in addition to the main one.
+// Offers include a separate audio section for each stream. Answers use streams for additional audio sections
+// of the offer, in order. Audio sections without a stream are rejected.
+func WithAudioStreams(streams ...AudioStream) Option {
+	return func(o *options) {
+		o.streams = streams
+	}
+}
+
+// WithT38 enables T.38 fax relay on a given UDPTL port. Offers include a T.38 section after audio sections.
+// Answers accept the first T.38 section of the offer, see MediaConfig.T38.
+func WithT38(port int, conf T38Config) Option {
+	return func(o *options) {
+		o.t38 = &t38Option{port: port, conf: conf}
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{registry: media.DefaultRegistry()}
+	for _, fnc := range opts {
+		fnc(&o)
+	}
+	if o.registry == nil {
+		o.registry = media.DefaultRegistry()
+	}
+	return o
+}
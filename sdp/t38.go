@@ -0,0 +1,258 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sdp
+
+import (
+	"fmt"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pion/sdp/v3"
+
+	"github.com/livekit/media-sdk/udptl"
+)
+
+const (
+	// T38MediaType, T38Proto and T38Format identify T.38 fax relay sections: "m=image <port> udptl t38".
+	T38MediaType = "image"
+	T38Proto     = "udptl"
+	T38Format    = "t38"
+)
+
+// T38RateManagement is a method of managing the TCF (Training Check Frame).
+type T38RateManagement string
+
+const (
+	T38LocalTCF       T38RateManagement = "localTCF"
+	T38TransferredTCF T38RateManagement = "transferredTCF"
+)
+
+// SDP attributes of T.38 sections (ITU-T T.38 Annex D).
+const (
+	attrT38Version         = "T38FaxVersion"
+	attrT38MaxBitRate      = "T38MaxBitRate"
+	attrT38RateManagement  = "T38FaxRateManagement"
+	attrT38MaxBuffer       = "T38FaxMaxBuffer"
+	attrT38MaxDatagram     = "T38FaxMaxDatagram"
+	attrT38ErrorCorrection = "T38FaxUdpEC"
+	attrT38FillBitRemoval  = "T38FaxFillBitRemoval"
+	attrT38TranscodingMMR  = "T38FaxTranscodingMMR"
+	attrT38TranscodingJBIG = "T38FaxTranscodingJBIG"
+)
+
+var t38ErrorCorrection = map[udptl.ErrorCorrection]string{
+	udptl.ErrorCorrectionNone:       "t38UDPNoEC",
+	udptl.ErrorCorrectionRedundancy: "t38UDPRedundancy",
+	udptl.ErrorCorrectionFEC:        "t38UDPFEC",
+}
+
+// T38Config contains parameters of a T.38 fax relay section.
+type T38Config struct {
+	Version        int // T38FaxVersion
+	MaxBitRate     int // T38MaxBitRate, bits per second
+	RateManagement T38RateManagement
+	// MaxBuffer and MaxDatagram declare the receive limits of the side that sends the description.
+	// Zero means that the value is not set.
+	MaxBuffer       int
+	MaxDatagram     int
+	ErrorCorrection udptl.ErrorCorrection
+	FillBitRemoval  bool
+	TranscodingMMR  bool
+	TranscodingJBIG bool
+}
+
+// DefaultT38Config returns T.38 parameters used by default.
+func DefaultT38Config() T38Config {
+	return T38Config{
+		Version:         0,
+		MaxBitRate:      14400,
+		RateManagement:  T38TransferredTCF,
+		MaxDatagram:     400,
+		ErrorCorrection: udptl.ErrorCorrectionRedundancy,
+	}
+}
+
+// T38MediaConfig is a negotiated T.38 fax relay session.
+type T38MediaConfig struct {
+	Local  netip.AddrPort
+	Remote netip.AddrPort
+	// Config contains negotiated parameters. MaxBuffer and MaxDatagram are local limits.
+	Config T38Config
+	// RemoteMaxDatagram is the maximal UDPTL datagram size accepted by the remote. Zero means no limit.
+	RemoteMaxDatagram int
+}
+
+// UDPTL returns UDPTL sender configuration for the session.
+func (c *T38MediaConfig) UDPTL() udptl.Config {
+	return udptl.Config{
+		ErrorCorrection: c.Config.ErrorCorrection,
+		MaxDatagram:     c.RemoteMaxDatagram,
+	}
+}
+
+// IsT38 checks if the media description is a T.38 fax relay section.
+func IsT38(m *sdp.MediaDescription) bool {
+	return m != nil && m.MediaName.Media == T38MediaType &&
+		len(m.MediaName.Protos) == 1 && strings.EqualFold(m.MediaName.Protos[0], T38Proto) &&
+		slices.ContainsFunc(m.MediaName.Formats, func(f string) bool {
+			return strings.EqualFold(f, T38Format)
+		})
+}
+
+// hasT38 checks if the session has an enabled T.38 section.
+func hasT38(s *sdp.SessionDescription) bool {
+	return slices.ContainsFunc(s.MediaDescriptions, func(m *sdp.MediaDescription) bool {
+		return IsT38(m) && m.MediaName.Port.Value != 0
+	})
+}
+
+// ParseT38 parses parameters of a T.38 fax relay section. Attribute names are case-insensitive,
+// since many implementations do not follow the case used in the specification.
+func ParseT38(m *sdp.MediaDescription) (*T38Config, error) {
+	if !IsT38(m) {
+		return nil, fmt.Errorf("not a T.38 media section: %q", m.MediaName.String())
+	}
+	// Defaults for missing attributes, as defined in T.38 Annex D.
+	c := &T38Config{RateManagement: T38TransferredTCF}
+	for _, a := range m.Attributes {
+		var err error
+		switch {
+		case strings.EqualFold(a.Key, attrT38Version):
+			c.Version, err = strconv.Atoi(a.Value)
+		case strings.EqualFold(a.Key, attrT38MaxBitRate):
+			c.MaxBitRate, err = strconv.Atoi(a.Value)
+		case strings.EqualFold(a.Key, attrT38MaxBuffer):
+			c.MaxBuffer, err = strconv.Atoi(a.Value)
+		case strings.EqualFold(a.Key, attrT38MaxDatagram):
+			c.MaxDatagram, err = strconv.Atoi(a.Value)
+		case strings.EqualFold(a.Key, attrT38RateManagement):
+			switch {
+			case strings.EqualFold(a.Value, string(T38LocalTCF)):
+				c.RateManagement = T38LocalTCF
+			case strings.EqualFold(a.Value, string(T38TransferredTCF)):
+				c.RateManagement = T38TransferredTCF
+			default:
+				err = fmt.Errorf("unsupported rate management")
+			}
+		case strings.EqualFold(a.Key, attrT38ErrorCorrection):
+			found := false
+			for ec, name := range t38ErrorCorrection {
+				if strings.EqualFold(a.Value, name) {
+					c.ErrorCorrection, found = ec, true
+				}
+			}
+			if !found {
+				err = fmt.Errorf("unsupported error correction")
+			}
+		case strings.EqualFold(a.Key, attrT38FillBitRemoval):
+			c.FillBitRemoval, err = parseT38Flag(a.Value)
+		case strings.EqualFold(a.Key, attrT38TranscodingMMR):
+			c.TranscodingMMR, err = parseT38Flag(a.Value)
+		case strings.EqualFold(a.Key, attrT38TranscodingJBIG):
+			c.TranscodingJBIG, err = parseT38Flag(a.Value)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid T.38 attribute %s:%s: %w", a.Key, a.Value, err)
+		}
+	}
+	return c, nil
+}
+
+// parseT38Flag parses boolean attributes. They are usually sent without a value, but some implementations use 0 or 1.
+func parseT38Flag(v string) (bool, error) {
+	switch v {
+	case "", "1":
+		return true, nil
+	case "0":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid flag value")
+}
+
+// T38Media creates a T.38 fax relay section.
+func T38Media(port int, conf T38Config) *sdp.MediaDescription {
+	attrs := []sdp.Attribute{
+		{Key: attrT38Version, Value: strconv.Itoa(conf.Version)},
+	}
+	if conf.MaxBitRate > 0 {
+		attrs = append(attrs, sdp.Attribute{Key: attrT38MaxBitRate, Value: strconv.Itoa(conf.MaxBitRate)})
+	}
+	for _, flag := range []struct {
+		key string
+		set bool
+	}{
+		{attrT38FillBitRemoval, conf.FillBitRemoval},
+		{attrT38TranscodingMMR, conf.TranscodingMMR},
+		{attrT38TranscodingJBIG, conf.TranscodingJBIG},
+	} {
+		if flag.set {
+			attrs = append(attrs, sdp.Attribute{Key: flag.key})
+		}
+	}
+	if conf.RateManagement != "" {
+		attrs = append(attrs, sdp.Attribute{Key: attrT38RateManagement, Value: string(conf.RateManagement)})
+	}
+	if conf.MaxBuffer > 0 {
+		attrs = append(attrs, sdp.Attribute{Key: attrT38MaxBuffer, Value: strconv.Itoa(conf.MaxBuffer)})
+	}
+	if conf.MaxDatagram > 0 {
+		attrs = append(attrs, sdp.Attribute{Key: attrT38MaxDatagram, Value: strconv.Itoa(conf.MaxDatagram)})
+	}
+	if ec, ok := t38ErrorCorrection[conf.ErrorCorrection]; ok {
+		attrs = append(attrs, sdp.Attribute{Key: attrT38ErrorCorrection, Value: ec})
+	}
+	return &sdp.MediaDescription{
+		MediaName: sdp.MediaName{
+			Media:   T38MediaType,
+			Port:    sdp.RangedPort{Value: port},
+			Protos:  []string{T38Proto},
+			Formats: []string{T38Format},
+		},
+		Attributes: attrs,
+	}
+}
+
+// answerT38 negotiates T.38 parameters for the answer. The answer may only lower the offered version,
+// bit rate and error correction, and must use the offered rate management. Buffer limits are declared by each side.
+func answerT38(offer, local T38Config) T38Config {
+	out := T38Config{
+		Version:         min(offer.Version, local.Version),
+		MaxBitRate:      offer.MaxBitRate,
+		RateManagement:  offer.RateManagement,
+		MaxBuffer:       local.MaxBuffer,
+		MaxDatagram:     local.MaxDatagram,
+		ErrorCorrection: min(offer.ErrorCorrection, local.ErrorCorrection),
+		FillBitRemoval:  offer.FillBitRemoval && local.FillBitRemoval,
+		TranscodingMMR:  offer.TranscodingMMR && local.TranscodingMMR,
+		TranscodingJBIG: offer.TranscodingJBIG && local.TranscodingJBIG,
+	}
+	if out.MaxBitRate == 0 || (local.MaxBitRate > 0 && local.MaxBitRate < out.MaxBitRate) {
+		out.MaxBitRate = local.MaxBitRate
+	}
+	if out.RateManagement == "" {
+		out.RateManagement = local.RateManagement
+	}
+	return out
+}
+
+// applyT38 returns negotiated T.38 parameters, given our offer and the answer.
+func applyT38(offer, answer T38Config) T38Config {
+	out := answer
+	out.MaxBuffer = offer.MaxBuffer
+	out.MaxDatagram = offer.MaxDatagram
+	return out
+}
@@ -0,0 +1,185 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sdp
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/pion/sdp/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/media-sdk/udptl"
+)
+
+const t38ReInvite = "v=0\r\n" +
+	"o=- 1 2 IN IP4 10.0.0.1\r\n" +
+	"s=-\r\n" +
+	"c=IN IP4 10.0.0.1\r\n" +
+	"t=0 0\r\n" +
+	"m=audio 0 RTP/AVP 0\r\n" +
+	"m=image 7000 udptl t38\r\n" +
+	"a=T38FaxVersion:0\r\n" +
+	"a=T38MaxBitRate:9600\r\n" +
+	"a=T38FaxFillBitRemoval:0\r\n" +
+	"a=t38FaxTranscodingMMR\r\n" +
+	"a=T38FaxRateManagement:transferredTCF\r\n" +
+	"a=T38FaxMaxBuffer:1800\r\n" +
+	"a=T38FaxMaxDatagram:176\r\n" +
+	"a=T38FaxUdpEC:t38UDPFEC\r\n"
+
+func TestParseT38(t *testing.T) {
+	var s sdp.SessionDescription
+	require.NoError(t, Unmarshal([]byte(t38ReInvite), &s))
+	require.False(t, IsT38(s.MediaDescriptions[0]))
+	require.True(t, IsT38(s.MediaDescriptions[1]))
+
+	conf, err := ParseT38(s.MediaDescriptions[1])
+	require.NoError(t, err)
+	exp := T38Config{
+		Version:         0,
+		MaxBitRate:      9600,
+		RateManagement:  T38TransferredTCF,
+		MaxBuffer:       1800,
+		MaxDatagram:     176,
+		ErrorCorrection: udptl.ErrorCorrectionFEC,
+		TranscodingMMR:  true,
+	}
+	require.Equal(t, exp, *conf)
+
+	// Generated section is parsed back with the same parameters.
+	m := T38Media(7000, exp)
+	conf, err = ParseT38(m)
+	require.NoError(t, err)
+	require.Equal(t, exp, *conf)
+
+	_, err = ParseT38(&sdp.MediaDescription{
+		MediaName:  m.MediaName,
+		Attributes: []sdp.Attribute{{Key: "T38FaxUdpEC", Value: "unknown"}},
+	})
+	require.Error(t, err)
+	_, err = ParseT38(s.MediaDescriptions[0])
+	require.Error(t, err)
+}
+
+func TestAnswerT38(t *testing.T) {
+	offer, err := ParseOffer([]byte(t38ReInvite))
+	require.NoError(t, err)
+	require.Equal(t, netip.MustParseAddrPort("10.0.0.1:7000"), offer.Addr)
+	require.Len(t, offer.Media, 2)
+	require.NotNil(t, offer.Media[1].T38)
+
+	ip := netip.MustParseAddr("10.0.0.2")
+	_, _, err = offer.Answer(ip, 8000, EncryptionNone)
+	require.Error(t, err)
+
+	local := DefaultT38Config()
+	answer, conf, err := offer.Answer(ip, 8000, EncryptionNone, WithT38(9000, local))
+	require.NoError(t, err)
+	require.Equal(t, &T38MediaConfig{
+		Local:  netip.MustParseAddrPort("10.0.0.2:9000"),
+		Remote: netip.MustParseAddrPort("10.0.0.1:7000"),
+		Config: T38Config{
+			Version:         0,
+			MaxBitRate:      9600,
+			RateManagement:  T38TransferredTCF,
+			MaxDatagram:     local.MaxDatagram,
+			ErrorCorrection: udptl.ErrorCorrectionRedundancy,
+		},
+		RemoteMaxDatagram: 176,
+	}, conf.T38)
+	require.Equal(t, udptl.Config{ErrorCorrection: udptl.ErrorCorrectionRedundancy, MaxDatagram: 176}, conf.T38.UDPTL())
+	require.Nil(t, conf.Audio.Codec)
+
+	descs := answer.SDP.MediaDescriptions
+	require.Len(t, descs, 2)
+	require.Equal(t, 0, descs[0].MediaName.Port.Value)
+	require.Equal(t, 9000, descs[1].MediaName.Port.Value)
+
+	// Apply the answer on the offerer side.
+	data, err := answer.SDP.Marshal()
+	require.NoError(t, err)
+	panswer, err := ParseAnswer(data)
+	require.NoError(t, err)
+	conf, err = panswer.Apply(offer, EncryptionNone)
+	require.NoError(t, err)
+	require.Equal(t, netip.MustParseAddrPort("10.0.0.2:9000"), conf.T38.Remote)
+	require.Equal(t, udptl.ErrorCorrectionRedundancy, conf.T38.Config.ErrorCorrection)
+	require.Equal(t, 176, conf.T38.Config.MaxDatagram)
+	require.Equal(t, local.MaxDatagram, conf.T38.RemoteMaxDatagram)
+}
+
+func TestOfferT38(t *testing.T) {
+	ip := netip.MustParseAddr("10.0.0.1")
+	conf := DefaultT38Config()
+	conf.ErrorCorrection = udptl.ErrorCorrectionFEC
+
+	t.Run("fax only", func(t *testing.T) {
+		offer := NewT38Offer(ip, 7000, conf)
+		data, err := offer.SDP.Marshal()
+		require.NoError(t, err)
+		require.Contains(t, string(data), "m=image 7000 udptl t38\r\n")
+		require.Contains(t, string(data), "a=T38FaxUdpEC:t38UDPFEC\r\n")
+
+		poffer, err := ParseOffer(data)
+		require.NoError(t, err)
+		answer, aconf, err := poffer.Answer(netip.MustParseAddr("10.0.0.2"), 0, EncryptionNone, WithT38(9000, DefaultT38Config()))
+		require.NoError(t, err)
+		require.Equal(t, udptl.ErrorCorrectionRedundancy, aconf.T38.Config.ErrorCorrection)
+
+		data, err = answer.SDP.Marshal()
+		require.NoError(t, err)
+		panswer, err := ParseAnswer(data)
+		require.NoError(t, err)
+		oconf, err := panswer.Apply(offer, EncryptionNone)
+		require.NoError(t, err)
+		require.Equal(t, netip.MustParseAddrPort("10.0.0.1:7000"), oconf.T38.Local)
+		require.Equal(t, netip.MustParseAddrPort("10.0.0.2:9000"), oconf.T38.Remote)
+		require.Equal(t, udptl.ErrorCorrectionRedundancy, oconf.T38.Config.ErrorCorrection)
+	})
+
+	t.Run("audio and fax", func(t *testing.T) {
+		offer, err := NewOffer(ip, 5000, EncryptionNone, WithT38(7000, conf))
+		require.NoError(t, err)
+		descs := offer.SDP.MediaDescriptions
+		require.Len(t, descs, 2)
+		require.True(t, IsT38(descs[1]))
+
+		data, err := offer.SDP.Marshal()
+		require.NoError(t, err)
+		poffer, err := ParseOffer(data)
+		require.NoError(t, err)
+
+		// Fax is rejected if not enabled.
+		answer, aconf, err := poffer.Answer(netip.MustParseAddr("10.0.0.2"), 6000, EncryptionNone)
+		require.NoError(t, err)
+		require.Nil(t, aconf.T38)
+		require.Equal(t, 0, answer.SDP.MediaDescriptions[1].MediaName.Port.Value)
+
+		answer, aconf, err = poffer.Answer(netip.MustParseAddr("10.0.0.2"), 6000, EncryptionNone, WithT38(9000, conf))
+		require.NoError(t, err)
+		require.NotNil(t, aconf.Audio.Codec)
+		require.Equal(t, udptl.ErrorCorrectionFEC, aconf.T38.Config.ErrorCorrection)
+
+		data, err = answer.SDP.Marshal()
+		require.NoError(t, err)
+		panswer, err := ParseAnswer(data)
+		require.NoError(t, err)
+		oconf, err := panswer.Apply(offer, EncryptionNone)
+		require.NoError(t, err)
+		require.NotNil(t, oconf.Audio.Codec)
+		require.Equal(t, netip.MustParseAddrPort("10.0.0.2:9000"), oconf.T38.Remote)
+	})
+}
@@ -0,0 +1,265 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package srtp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/pion/srtp/v3"
+)
+
+// ErrKeyExpired is returned when the lifetime of the master key is reached.
+var ErrKeyExpired = errors.New("srtp: master key lifetime expired")
+
+// keyUsage tracks the number of packets protected with a master key.
+type keyUsage struct {
+	lifetime uint64 // zero means no limit, other than the one of the profile
+	packets  uint64
+	signaled bool
+}
+
+func (k *keyUsage) expired() bool {
+	return k.lifetime != 0 && k.packets >= k.lifetime
+}
+
+// cryptoContext is SRTP and SRTCP context for one direction of the session.
+//
+// It tracks the usage of master keys and allows replacing them without resetting the state of the streams.
+type cryptoContext struct {
+	mu      sync.Mutex
+	local   bool
+	profile srtp.ProtectionProfile
+	opts    []ContextOption
+	ctx     *srtp.Context
+	mki     []byte               // MKI of the current key, nil if MKI is not used
+	keys    map[string]*keyUsage // by MKI
+	rtpTag  int                  // length of SRTP authentication tag
+	rtcpTag int                  // length of SRTCP authentication tag
+
+	// SSRCs of the streams that were processed by this context. Used to carry over the state when replacing the context.
+	rtpSSRCs  map[uint32]struct{}
+	rtcpSSRCs map[uint32]struct{}
+
+	margin     uint64
+	onExpiring func()
+}
+
+func newCryptoContext(local bool, key, salt []byte, profile srtp.ProtectionProfile, opts []ContextOption) (*cryptoContext, error) {
+	ctx, err := srtp.CreateContext(key, salt, profile, opts...)
+	if err != nil {
+		return nil, err
+	}
+	rtpTag, err := profile.AuthTagRTPLen()
+	if err != nil {
+		return nil, err
+	}
+	rtcpTag, err := profile.AuthTagRTCPLen()
+	if err != nil {
+		return nil, err
+	}
+	return &cryptoContext{
+		local:     local,
+		profile:   profile,
+		opts:      opts,
+		ctx:       ctx,
+		keys:      map[string]*keyUsage{"": {}},
+		rtpTag:    rtpTag,
+		rtcpTag:   rtcpTag,
+		rtpSSRCs:  make(map[uint32]struct{}),
+		rtcpSSRCs: make(map[uint32]struct{}),
+	}, nil
+}
+
+// setInitial sets MKI and lifetime of the key the context was created with.
+func (c *cryptoContext) setInitial(mki []byte, lifetime uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.mki = mki
+	c.keys = map[string]*keyUsage{string(mki): {lifetime: lifetime}}
+}
+
+// usage returns the usage of a key for a given MKI.
+func (c *cryptoContext) usage(mki []byte) *keyUsage {
+	k := c.keys[string(mki)]
+	if k == nil {
+		// Unknown MKI, the context will reject the packet.
+		k = &keyUsage{}
+	}
+	return k
+}
+
+// packetMKI returns MKI of a protected packet. It's located before the authentication tag.
+func (c *cryptoContext) packetMKI(buf []byte, tag int) []byte {
+	if len(c.mki) == 0 || len(buf) < len(c.mki)+tag {
+		return nil
+	}
+	end := len(buf) - tag
+	return buf[end-len(c.mki) : end]
+}
+
+// use records that a packet was protected with a key. It must be called with the lock held.
+func (c *cryptoContext) use(k *keyUsage) {
+	k.packets++
+	if c.onExpiring == nil || k.signaled || k.lifetime == 0 {
+		return
+	}
+	if k.lifetime-k.packets <= c.margin {
+		k.signaled = true
+		go c.onExpiring()
+	}
+}
+
+func (c *cryptoContext) encryptRTP(dst, pkt []byte) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	k := c.usage(c.mki)
+	if k.expired() {
+		return nil, ErrKeyExpired
+	}
+	out, err := c.ctx.EncryptRTP(dst, pkt, nil)
+	if err != nil {
+		return nil, err
+	}
+	c.rtpSSRCs[binary.BigEndian.Uint32(pkt[8:])] = struct{}{}
+	c.use(k)
+	return out, nil
+}
+
+func (c *cryptoContext) decryptRTP(dst, pkt []byte) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	k := c.usage(c.packetMKI(pkt, c.rtpTag))
+	if k.expired() {
+		return nil, ErrKeyExpired
+	}
+	out, err := c.ctx.DecryptRTP(dst, pkt, nil)
+	if err != nil {
+		return nil, err
+	}
+	c.rtpSSRCs[binary.BigEndian.Uint32(pkt[8:])] = struct{}{}
+	c.use(k)
+	return out, nil
+}
+
+func (c *cryptoContext) encryptRTCP(dst, pkt []byte) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	k := c.usage(c.mki)
+	if k.expired() {
+		return nil, ErrKeyExpired
+	}
+	out, err := c.ctx.EncryptRTCP(dst, pkt, nil)
+	if err != nil {
+		return nil, err
+	}
+	c.rtcpSSRCs[binary.BigEndian.Uint32(pkt[4:])] = struct{}{}
+	c.use(k)
+	return out, nil
+}
+
+func (c *cryptoContext) decryptRTCP(dst, pkt []byte) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	k := c.usage(c.packetMKI(pkt, c.rtcpTag))
+	if k.expired() {
+		return nil, ErrKeyExpired
+	}
+	out, err := c.ctx.DecryptRTCP(dst, pkt, nil)
+	if err != nil {
+		return nil, err
+	}
+	c.rtcpSSRCs[binary.BigEndian.Uint32(pkt[4:])] = struct{}{}
+	c.use(k)
+	return out, nil
+}
+
+// setKey installs a new master key.
+//
+// If both keys use MKI of the same length, the key is added to the existing context. For the local side,
+// the new key is used for all following packets and the previous one is removed. For the remote side,
+// packets are accepted with both keys until the old one is removed with removeKey.
+//
+// Otherwise, the context is replaced, keeping rollover counters and SRTCP indexes of existing streams.
+func (c *cryptoContext) setKey(p Profile) error {
+	if p.Profile != "" {
+		sp, err := p.Profile.Parse()
+		if err != nil {
+			return err
+		} else if sp != c.profile {
+			return fmt.Errorf("srtp: cannot change profile from %s to %s", c.profile, sp)
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.keys[string(p.MKI)]; ok && len(p.MKI) != 0 {
+		return fmt.Errorf("srtp: MKI %x is already in use", p.MKI)
+	}
+	if len(p.MKI) != 0 && len(p.MKI) == len(c.mki) {
+		if err := c.ctx.AddCipherForMKI(p.MKI, p.Key, p.Salt); err != nil {
+			return err
+		}
+		// Remote context only uses this MKI to allow removing the previous one.
+		if err := c.ctx.SetSendMKI(p.MKI); err != nil {
+			return err
+		}
+		if c.local {
+			_ = c.ctx.RemoveMKI(c.mki)
+			delete(c.keys, string(c.mki))
+		}
+		c.mki = p.MKI
+		c.keys[string(p.MKI)] = &keyUsage{lifetime: p.Lifetime}
+		return nil
+	}
+	opts := c.opts
+	if len(p.MKI) != 0 {
+		opts = append(opts[:len(opts):len(opts)], srtp.MasterKeyIndicator(p.MKI))
+	}
+	ctx, err := srtp.CreateContext(p.Key, p.Salt, c.profile, opts...)
+	if err != nil {
+		return err
+	}
+	for ssrc := range c.rtpSSRCs {
+		if roc, ok := c.ctx.ROC(ssrc); ok {
+			ctx.SetROC(ssrc, roc)
+		}
+	}
+	for ssrc := range c.rtcpSSRCs {
+		if index, ok := c.ctx.Index(ssrc); ok {
+			ctx.SetIndex(ssrc, index)
+		}
+	}
+	c.ctx = ctx
+	c.mki = p.MKI
+	c.keys = map[string]*keyUsage{string(p.MKI): {lifetime: p.Lifetime}}
+	return nil
+}
+
+// removeKey removes a key with a given MKI. The current key cannot be removed.
+func (c *cryptoContext) removeKey(mki []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if bytes.Equal(mki, c.mki) {
+		return fmt.Errorf("srtp: cannot remove the current key")
+	}
+	if err := c.ctx.RemoveMKI(mki); err != nil {
+		return err
+	}
+	delete(c.keys, string(mki))
+	return nil
+}
@@ -0,0 +1,147 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package srtp
+
+import (
+	"io"
+	"net"
+	"slices"
+	"sync"
+
+	"github.com/frostbyte73/core"
+	"github.com/pion/rtcp"
+
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/media-sdk/rtp"
+)
+
+// IsRTCP checks if the packet is RTCP when it's multiplexed with RTP on the same port (RFC 5761, section 4).
+func IsRTCP(buf []byte) bool {
+	if len(buf) < 8 {
+		return false
+	}
+	return buf[1] >= 192 && buf[1] <= 223
+}
+
+// muxConn is a connection that carries both SRTP and SRTCP.
+// Reads return only SRTP packets, while SRTCP packets are handed over to readRTCP.
+type muxConn struct {
+	net.Conn
+	rtcp   chan []byte
+	closed core.Fuse
+}
+
+func newMuxConn(conn net.Conn) *muxConn {
+	return &muxConn{
+		Conn: conn,
+		rtcp: make(chan []byte, 10),
+	}
+}
+
+func (c *muxConn) Read(b []byte) (int, error) {
+	for {
+		n, err := c.Conn.Read(b)
+		if err != nil {
+			c.closed.Break()
+			return n, err
+		}
+		if !IsRTCP(b[:n]) {
+			return n, nil
+		}
+		select {
+		case c.rtcp <- slices.Clone(b[:n]):
+		default: // nobody reads RTCP, drop it
+		}
+	}
+}
+
+func (c *muxConn) readRTCP(b []byte) (int, error) {
+	select {
+	case buf := <-c.rtcp:
+		return copy(b, buf), nil
+	case <-c.closed.Watch():
+		return 0, io.EOF
+	}
+}
+
+func (c *muxConn) Close() error {
+	c.closed.Break()
+	return c.Conn.Close()
+}
+
+type srtcpConn struct {
+	log    logger.Logger
+	conn   net.Conn
+	read   func(b []byte) (int, error)
+	local  *cryptoContext
+	remote *cryptoContext
+
+	wmu  sync.Mutex
+	wbuf []byte
+
+	rmu  sync.Mutex
+	rbuf []byte
+}
+
+func newSRTCP(log logger.Logger, conn net.Conn, read func(b []byte) (int, error), local, remote *cryptoContext) *srtcpConn {
+	return &srtcpConn{
+		log:    log,
+		conn:   conn,
+		read:   read,
+		local:  local,
+		remote: remote,
+		rbuf:   make([]byte, rtp.MTUSize+1),
+	}
+}
+
+func (c *srtcpConn) WriteRTCP(pkts []rtcp.Packet) error {
+	data, err := rtcp.Marshal(pkts)
+	if err != nil {
+		return err
+	}
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	c.wbuf, err = c.local.encryptRTCP(c.wbuf, data)
+	if err != nil {
+		return err
+	}
+	_, err = c.conn.Write(c.wbuf)
+	return err
+}
+
+func (c *srtcpConn) ReadRTCP() ([]rtcp.Packet, error) {
+	c.rmu.Lock()
+	defer c.rmu.Unlock()
+	for {
+		n, err := c.read(c.rbuf)
+		if err != nil {
+			return nil, err
+		}
+		if n > rtp.MTUSize {
+			continue // ignore partial messages
+		}
+		data, err := c.remote.decryptRTCP(nil, c.rbuf[:n])
+		if err != nil {
+			c.log.Debugw("cannot decrypt SRTCP packet", "error", err)
+			continue
+		}
+		pkts, err := rtcp.Unmarshal(data)
+		if err != nil {
+			continue // ignore
+		}
+		return pkts, nil
+	}
+}
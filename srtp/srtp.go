@@ -18,8 +18,9 @@ import (
 	"crypto/rand"
 	"fmt"
 	"net"
+	"sync"
 
-	prtp "github.com/pion/rtp"
+	"github.com/pion/rtcp"
 	"github.com/pion/srtp/v3"
 
 	"github.com/livekit/protocol/logger"
@@ -32,6 +33,8 @@ var defaultProfiles = []ProtectionProfile{
 	"AES_CM_128_HMAC_SHA1_32",
 	"AES_256_CM_HMAC_SHA1_80",
 	"AES_256_CM_HMAC_SHA1_32",
+	"AEAD_AES_128_GCM",
+	"AEAD_AES_256_GCM",
 }
 
 func DefaultProfiles() ([]Profile, error) {
@@ -83,6 +86,10 @@ func (p ProtectionProfile) Parse() (srtp.ProtectionProfile, error) {
 		return srtp.ProtectionProfileAes256CmHmacSha1_80, nil
 	case "AES_256_CM_HMAC_SHA1_32":
 		return srtp.ProtectionProfileAes256CmHmacSha1_32, nil
+	case "AEAD_AES_128_GCM":
+		return srtp.ProtectionProfileAeadAes128Gcm, nil
+	case "AEAD_AES_256_GCM":
+		return srtp.ProtectionProfileAeadAes256Gcm, nil
 	default:
 		return 0, fmt.Errorf("unsupported profile %q", p)
 	}
@@ -94,7 +101,7 @@ type Profile struct {
 	Key      []byte
 	Salt     []byte
 	MKI      []byte // Master Key Identifier, nil if not present
-	Lifetime uint64 // TODO: This is just a placeholder for future use
+	Lifetime uint64 // Lifetime of the master key in packets, zero if not set
 }
 
 type Config = srtp.Config
@@ -107,66 +114,178 @@ func MasterKeyIndicator(mki []byte) ContextOption {
 	return srtp.MasterKeyIndicator(mki)
 }
 
-func NewSession(log logger.Logger, conn net.Conn, conf *Config) (rtp.Session, error) {
-	s, err := srtp.NewSessionSRTP(conn, conf)
-	if err != nil {
-		return nil, err
+// Same as the default in pion/srtp sessions.
+const replayWindow = 64
+
+// Session is an SRTP session that also carries SRTCP, and allows replacing master keys mid-call.
+type Session interface {
+	rtp.RTCPSession
+	// SetLocalKey replaces the master key used for outgoing packets. Key lifetime is reset.
+	SetLocalKey(p Profile) error
+	// AddRemoteKey installs a master key for incoming packets.
+	//
+	// If MKI is used by both the current and the new key, packets with either key are accepted until the old one
+	// is removed with RemoveRemoteKey. Otherwise, the new key replaces the current one.
+	AddRemoteKey(p Profile) error
+	// RemoveRemoteKey removes a master key for incoming packets with a given MKI.
+	RemoveRemoteKey(mki []byte) error
+}
+
+type sessionConfig struct {
+	local, remote *Profile
+	margin        uint64
+	onExpiring    func()
+}
+
+// SessionOption configures SRTP session.
+type SessionOption func(c *sessionConfig)
+
+// WithProfiles sets MKI and lifetime of initial local and remote master keys, as negotiated in SDP.
+// Keys and MKIs must match the ones in the session Config.
+func WithProfiles(local, remote Profile) SessionOption {
+	return func(c *sessionConfig) {
+		c.local, c.remote = &local, &remote
 	}
-	return &session{log: log, s: s}, nil
 }
 
-type session struct {
-	log logger.Logger
-	s   *srtp.SessionSRTP
+// OnKeyExpiring sets a callback that is called when the local master key can only protect a given number of packets
+// before its lifetime expires. The callback is called once for each key, and a new key must be installed with
+// SetLocalKey, otherwise sending fails with ErrKeyExpired.
+func OnKeyExpiring(remaining uint64, fn func()) SessionOption {
+	return func(c *sessionConfig) {
+		c.margin, c.onExpiring = remaining, fn
+	}
 }
 
-func (s *session) OpenWriteStream() (rtp.WriteStream, error) {
-	w, err := s.s.OpenWriteStream()
+// NewSession creates SRTP session on a given connection. SRTCP is multiplexed on the same connection (RFC 5761).
+//
+// Returned session implements Session.
+func NewSession(log logger.Logger, conn net.Conn, conf *Config, opts ...SessionOption) (rtp.Session, error) {
+	mux := newMuxConn(conn)
+	return newSession(log, mux, conn, mux.readRTCP, conf, opts)
+}
+
+// NewSessionWithRTCP creates SRTP session on a given connection, with SRTCP sent and received on a separate one.
+func NewSessionWithRTCP(log logger.Logger, conn, rtcpConn net.Conn, conf *Config, opts ...SessionOption) (Session, error) {
+	s, err := newSession(log, conn, rtcpConn, rtcpConn.Read, conf, opts)
 	if err != nil {
 		return nil, err
 	}
-	return writeStream{w: w}, nil
+	s.rtcpConn = rtcpConn
+	return s, nil
 }
 
-func (s *session) AcceptStream() (rtp.ReadStream, uint32, error) {
-	r, ssrc, err := s.s.AcceptStream()
+func newSession(log logger.Logger, conn, rtcpConn net.Conn, readRTCP func(b []byte) (int, error), conf *Config, opts []SessionOption) (*session, error) {
+	var sc sessionConfig
+	for _, o := range opts {
+		o(&sc)
+	}
+	local, err := newCryptoContext(true, conf.Keys.LocalMasterKey, conf.Keys.LocalMasterSalt, conf.Profile, conf.LocalOptions)
+	if err != nil {
+		return nil, err
+	}
+	remoteOpts := append([]ContextOption{
+		srtp.SRTPReplayProtection(replayWindow),
+		srtp.SRTCPReplayProtection(replayWindow),
+	}, conf.RemoteOptions...)
+	remote, err := newCryptoContext(false, conf.Keys.RemoteMasterKey, conf.Keys.RemoteMasterSalt, conf.Profile, remoteOpts)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
+	}
+	if sc.local != nil {
+		local.setInitial(sc.local.MKI, sc.local.Lifetime)
 	}
-	return readStream{r: r}, ssrc, nil
+	if sc.remote != nil {
+		remote.setInitial(sc.remote.MKI, sc.remote.Lifetime)
+	}
+	local.margin, local.onExpiring = sc.margin, sc.onExpiring
+	return &session{
+		Session: rtp.NewSession(log, &srtpConn{Conn: conn, log: log, local: local, remote: remote}),
+		local:   local,
+		remote:  remote,
+		rtcp:    newSRTCP(log, rtcpConn, readRTCP, local, remote),
+	}, nil
 }
 
-func (s *session) Close() error {
-	return s.s.Close()
+type session struct {
+	rtp.Session // plain RTP session over srtpConn
+	local       *cryptoContext
+	remote      *cryptoContext
+	rtcp        *srtcpConn
+	rtcpConn    net.Conn // only set if RTCP is not multiplexed
+}
+
+var _ Session = (*session)(nil)
+
+func (s *session) SetLocalKey(p Profile) error {
+	return s.local.setKey(p)
+}
+
+func (s *session) AddRemoteKey(p Profile) error {
+	return s.remote.setKey(p)
+}
+
+func (s *session) RemoveRemoteKey(mki []byte) error {
+	return s.remote.removeKey(mki)
+}
+
+func (s *session) WriteRTCP(pkts []rtcp.Packet) error {
+	return s.rtcp.WriteRTCP(pkts)
 }
 
-type writeStream struct {
-	w *srtp.WriteStreamSRTP
+func (s *session) ReadRTCP() ([]rtcp.Packet, error) {
+	return s.rtcp.ReadRTCP()
 }
 
-func (w writeStream) String() string {
-	return "SRTPWriteStream"
+func (s *session) Close() error {
+	err := s.Session.Close()
+	if s.rtcpConn != nil {
+		if err2 := s.rtcpConn.Close(); err == nil {
+			err = err2
+		}
+	}
+	return err
 }
 
-func (w writeStream) WriteRTP(h *prtp.Header, payload []byte) (int, error) {
-	return w.w.WriteRTP(h, payload)
+// srtpConn protects and unprotects RTP packets on the connection.
+type srtpConn struct {
+	net.Conn
+	log    logger.Logger
+	local  *cryptoContext
+	remote *cryptoContext
+
+	wmu  sync.Mutex
+	wbuf []byte
 }
 
-type readStream struct {
-	r *srtp.ReadStreamSRTP
+func (c *srtpConn) Read(b []byte) (int, error) {
+	for {
+		n, err := c.Conn.Read(b)
+		if err != nil {
+			return n, err
+		}
+		if n > rtp.MTUSize {
+			return n, nil // let the session handle it
+		}
+		out, err := c.remote.decryptRTP(b, b[:n])
+		if err != nil {
+			c.log.Debugw("cannot decrypt SRTP packet", "error", err)
+			continue
+		}
+		return copy(b, out), nil
+	}
 }
 
-func (r readStream) ReadRTP(h *prtp.Header, payload []byte) (int, error) {
-	buf := payload
-	n, err := r.r.Read(buf)
+func (c *srtpConn) Write(b []byte) (int, error) {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	var err error
+	c.wbuf, err = c.local.encryptRTP(c.wbuf, b)
 	if err != nil {
 		return 0, err
 	}
-	var p rtp.Packet
-	if err = p.Unmarshal(buf[:n]); err != nil {
+	if _, err = c.Conn.Write(c.wbuf); err != nil {
 		return 0, err
 	}
-	*h = p.Header
-	n = copy(payload, p.Payload)
-	return n, nil
+	return len(b), nil
 }
@@ -0,0 +1,349 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package srtp
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/pion/rtcp"
+	prtp "github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/media-sdk/rtp"
+)
+
+// newProfiles generates a pair of master keys with a given profile.
+func newProfiles(t testing.TB, profile ProtectionProfile) (Profile, Profile) {
+	var keys [2]Profile
+	for i := range keys {
+		profiles, err := DefaultProfiles()
+		require.NoError(t, err)
+		for _, p := range profiles {
+			if p.Profile == profile {
+				keys[i] = p
+			}
+		}
+		require.NotEmpty(t, keys[i].Key)
+	}
+	return keys[0], keys[1]
+}
+
+// newConfigs returns SRTP configs for both sides of the session.
+func newConfigs(t testing.TB, a, b Profile) (*Config, *Config) {
+	sp, err := a.Profile.Parse()
+	require.NoError(t, err)
+	confA := &Config{
+		Profile: sp,
+		Keys:    SessionKeys{LocalMasterKey: a.Key, LocalMasterSalt: a.Salt, RemoteMasterKey: b.Key, RemoteMasterSalt: b.Salt},
+	}
+	confB := &Config{
+		Profile: sp,
+		Keys:    SessionKeys{LocalMasterKey: b.Key, LocalMasterSalt: b.Salt, RemoteMasterKey: a.Key, RemoteMasterSalt: a.Salt},
+	}
+	if len(a.MKI) != 0 {
+		confA.LocalOptions = append(confA.LocalOptions, MasterKeyIndicator(a.MKI))
+		confB.RemoteOptions = append(confB.RemoteOptions, MasterKeyIndicator(a.MKI))
+	}
+	if len(b.MKI) != 0 {
+		confB.LocalOptions = append(confB.LocalOptions, MasterKeyIndicator(b.MKI))
+		confA.RemoteOptions = append(confA.RemoteOptions, MasterKeyIndicator(b.MKI))
+	}
+	return confA, confB
+}
+
+// acceptAll keeps reading packets from the session, which is required to receive RTP and multiplexed RTCP.
+func acceptAll(s rtp.Session) {
+	go func() {
+		for {
+			if _, _, err := s.AcceptStream(); err != nil {
+				return
+			}
+		}
+	}()
+}
+
+func TestProfiles(t *testing.T) {
+	profiles, err := DefaultProfiles()
+	require.NoError(t, err)
+	lens := make(map[ProtectionProfile][2]int)
+	for _, p := range profiles {
+		lens[p.Profile] = [2]int{len(p.Key), len(p.Salt)}
+	}
+	require.Equal(t, map[ProtectionProfile][2]int{
+		"AES_CM_128_HMAC_SHA1_80": {16, 14},
+		"AES_CM_128_HMAC_SHA1_32": {16, 14},
+		"AES_256_CM_HMAC_SHA1_80": {32, 14},
+		"AES_256_CM_HMAC_SHA1_32": {32, 14},
+		"AEAD_AES_128_GCM":        {16, 12},
+		"AEAD_AES_256_GCM":        {32, 12},
+	}, lens)
+}
+
+type rtpPipe struct {
+	w   rtp.WriteStream
+	r   rtp.ReadStream
+	seq uint16
+}
+
+// newRTPPipe opens a stream from a to b. It starts reading all packets on b.
+func newRTPPipe(t *testing.T, a, b rtp.Session) *rtpPipe {
+	w, err := a.OpenWriteStream()
+	require.NoError(t, err)
+	p := &rtpPipe{w: w}
+	errc := make(chan error, 1)
+	go func() {
+		errc <- p.write([]byte{1})
+	}()
+	r, ssrc, err := b.AcceptStream()
+	require.NoError(t, err)
+	require.NoError(t, <-errc)
+	require.EqualValues(t, 42, ssrc)
+	acceptAll(b)
+	p.r = r
+	require.Equal(t, []byte{1}, p.read(t))
+	return p
+}
+
+func (p *rtpPipe) write(payload []byte) error {
+	p.seq++
+	_, err := p.w.WriteRTP(&prtp.Header{Version: 2, PayloadType: 0, SequenceNumber: p.seq, SSRC: 42}, payload)
+	return err
+}
+
+func (p *rtpPipe) read(t testing.TB) []byte {
+	var h prtp.Header
+	buf := make([]byte, rtp.MTUSize)
+	n, err := p.r.ReadRTP(&h, buf)
+	require.NoError(t, err)
+	require.Equal(t, p.seq, h.SequenceNumber)
+	return buf[:n]
+}
+
+func (p *rtpPipe) check(t testing.TB, payload []byte) {
+	require.NoError(t, p.write(payload))
+	require.Equal(t, payload, p.read(t))
+}
+
+func testRTCP(t *testing.T, a, b rtp.RTCPSession) {
+	exp := []rtcp.Packet{
+		&rtcp.ReceiverReport{SSRC: 42, Reports: []rtcp.ReceptionReport{{SSRC: 43, LastSequenceNumber: 100}}, ProfileExtensions: []byte{}},
+		&rtcp.SourceDescription{Chunks: []rtcp.SourceDescriptionChunk{{Source: 42, Items: []rtcp.SourceDescriptionItem{{Type: rtcp.SDESCNAME, Text: "test"}}}}},
+	}
+	errc := make(chan error, 1)
+	go func() {
+		errc <- a.WriteRTCP(exp)
+	}()
+	got, err := b.ReadRTCP()
+	require.NoError(t, err)
+	require.NoError(t, <-errc)
+	require.Equal(t, exp, got)
+}
+
+func newSessionPair(t *testing.T, confA, confB *Config, optsA, optsB []SessionOption) (Session, Session) {
+	c1, c2 := net.Pipe()
+	r1, r2 := net.Pipe()
+	a, err := NewSessionWithRTCP(logger.GetLogger(), c1, r1, confA, optsA...)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = a.Close() })
+	b, err := NewSessionWithRTCP(logger.GetLogger(), c2, r2, confB, optsB...)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = b.Close() })
+	return a, b
+}
+
+func TestSession(t *testing.T) {
+	for _, profile := range defaultProfiles {
+		t.Run(string(profile), func(t *testing.T) {
+			pa, pb := newProfiles(t, profile)
+			confA, confB := newConfigs(t, pa, pb)
+
+			t.Run("mux", func(t *testing.T) {
+				c1, c2 := net.Pipe()
+				a, err := NewSession(logger.GetLogger(), c1, confA)
+				require.NoError(t, err)
+				defer a.Close()
+				b, err := NewSession(logger.GetLogger(), c2, confB)
+				require.NoError(t, err)
+				defer b.Close()
+
+				p := newRTPPipe(t, a, b)
+				p.check(t, []byte{1, 2, 3})
+				acceptAll(a)
+				testRTCP(t, a.(rtp.RTCPSession), b.(rtp.RTCPSession))
+				testRTCP(t, b.(rtp.RTCPSession), a.(rtp.RTCPSession))
+				p.check(t, []byte{4, 5})
+			})
+			t.Run("separate", func(t *testing.T) {
+				a, b := newSessionPair(t, confA, confB, nil, nil)
+				p := newRTPPipe(t, a, b)
+				p.check(t, []byte{1, 2, 3})
+				testRTCP(t, a, b)
+				testRTCP(t, b, a)
+			})
+		})
+	}
+}
+
+func TestSessionWrongKey(t *testing.T) {
+	pa, pb := newProfiles(t, "AEAD_AES_128_GCM")
+	confA, _ := newConfigs(t, pa, pb)
+	pa, pb = newProfiles(t, "AEAD_AES_128_GCM")
+	_, confB := newConfigs(t, pa, pb)
+	a, b := newSessionPair(t, confA, confB, nil, nil)
+
+	go func() {
+		_ = a.WriteRTCP([]rtcp.Packet{&rtcp.PictureLossIndication{SenderSSRC: 1, MediaSSRC: 2}})
+		_ = a.Close()
+	}()
+	// Packets that fail authentication are dropped.
+	_, err := b.ReadRTCP()
+	require.Error(t, err)
+}
+
+func TestRekey(t *testing.T) {
+	for _, profile := range []ProtectionProfile{"AES_CM_128_HMAC_SHA1_80", "AEAD_AES_256_GCM"} {
+		t.Run(string(profile), func(t *testing.T) {
+			t.Run("replace", func(t *testing.T) {
+				pa, pb := newProfiles(t, profile)
+				confA, confB := newConfigs(t, pa, pb)
+				a, b := newSessionPair(t, confA, confB, nil, nil)
+				p := newRTPPipe(t, a, b)
+				testRTCP(t, a, b)
+
+				key, _ := newProfiles(t, profile)
+				require.NoError(t, a.SetLocalKey(key))
+				require.NoError(t, b.AddRemoteKey(key))
+				p.check(t, []byte{2})
+				testRTCP(t, a, b)
+			})
+			t.Run("mki", func(t *testing.T) {
+				pa, pb := newProfiles(t, profile)
+				pa.MKI, pb.MKI = []byte{0, 1}, []byte{0, 2}
+				confA, confB := newConfigs(t, pa, pb)
+				a, b := newSessionPair(t, confA, confB,
+					[]SessionOption{WithProfiles(pa, pb)},
+					[]SessionOption{WithProfiles(pb, pa)},
+				)
+				p := newRTPPipe(t, a, b)
+				testRTCP(t, a, b)
+
+				key, _ := newProfiles(t, profile)
+				key.MKI = []byte{0, 3}
+				require.NoError(t, b.AddRemoteKey(key))
+				// Both keys are accepted until the old one is removed.
+				p.check(t, []byte{2})
+				require.NoError(t, a.SetLocalKey(key))
+				p.check(t, []byte{3})
+				testRTCP(t, a, b)
+				require.Error(t, b.RemoveRemoteKey(key.MKI))
+				require.NoError(t, b.RemoveRemoteKey(pa.MKI))
+				p.check(t, []byte{4})
+
+				other, _ := newProfiles(t, "AES_256_CM_HMAC_SHA1_32")
+				require.Error(t, a.SetLocalKey(other))
+			})
+		})
+	}
+}
+
+func rtpPacket(t testing.TB, seq uint16) []byte {
+	p := &prtp.Packet{Header: prtp.Header{Version: 2, SequenceNumber: seq, SSRC: 42}, Payload: []byte{1, 2, 3}}
+	data, err := p.Marshal()
+	require.NoError(t, err)
+	return data
+}
+
+func TestKeyLifetime(t *testing.T) {
+	key, key2 := newProfiles(t, "AES_CM_128_HMAC_SHA1_80")
+	sp, err := key.Profile.Parse()
+	require.NoError(t, err)
+	local, err := newCryptoContext(true, key.Key, key.Salt, sp, nil)
+	require.NoError(t, err)
+	remote, err := newCryptoContext(false, key.Key, key.Salt, sp, nil)
+	require.NoError(t, err)
+	local.setInitial(nil, 3)
+	remote.setInitial(nil, 3)
+	expiring := make(chan struct{}, 10)
+	local.margin, local.onExpiring = 1, func() { expiring <- struct{}{} }
+
+	for seq := uint16(1); seq <= 3; seq++ {
+		pkt, err := local.encryptRTP(nil, rtpPacket(t, seq))
+		require.NoError(t, err)
+		_, err = remote.decryptRTP(nil, pkt)
+		require.NoError(t, err)
+		if seq == 2 {
+			select {
+			case <-expiring:
+			case <-time.After(time.Second):
+				t.Fatal("expected key expiration")
+			}
+		}
+	}
+	_, err = local.encryptRTP(nil, rtpPacket(t, 4))
+	require.ErrorIs(t, err, ErrKeyExpired)
+
+	// Remote side doesn't accept more packets with the expired key either.
+	other, err := newCryptoContext(true, key.Key, key.Salt, sp, nil)
+	require.NoError(t, err)
+	pkt, err := other.encryptRTP(nil, rtpPacket(t, 4))
+	require.NoError(t, err)
+	_, err = remote.decryptRTP(nil, pkt)
+	require.ErrorIs(t, err, ErrKeyExpired)
+
+	require.NoError(t, local.setKey(key2))
+	require.NoError(t, remote.setKey(key2))
+	for seq := uint16(5); seq <= 6; seq++ {
+		pkt, err := local.encryptRTP(nil, rtpPacket(t, seq))
+		require.NoError(t, err)
+		_, err = remote.decryptRTP(nil, pkt)
+		require.NoError(t, err)
+	}
+	require.Len(t, expiring, 0)
+}
+
+func TestRemoveKey(t *testing.T) {
+	key, key2 := newProfiles(t, "AEAD_AES_128_GCM")
+	key.MKI, key2.MKI = []byte{1}, []byte{2}
+	sp, err := key.Profile.Parse()
+	require.NoError(t, err)
+	old, err := newCryptoContext(true, key.Key, key.Salt, sp, []ContextOption{MasterKeyIndicator(key.MKI)})
+	require.NoError(t, err)
+	old.setInitial(key.MKI, 0)
+	remote, err := newCryptoContext(false, key.Key, key.Salt, sp, []ContextOption{MasterKeyIndicator(key.MKI)})
+	require.NoError(t, err)
+	remote.setInitial(key.MKI, 0)
+
+	pkt, err := old.encryptRTP(nil, rtpPacket(t, 1))
+	require.NoError(t, err)
+	_, err = remote.decryptRTP(nil, pkt)
+	require.NoError(t, err)
+
+	require.Error(t, remote.setKey(key))
+	require.NoError(t, remote.setKey(key2))
+	pkt, err = old.encryptRTP(nil, rtpPacket(t, 2))
+	require.NoError(t, err)
+	_, err = remote.decryptRTP(nil, pkt)
+	require.NoError(t, err)
+
+	require.NoError(t, remote.removeKey(key.MKI))
+	pkt, err = old.encryptRTP(nil, rtpPacket(t, 3))
+	require.NoError(t, err)
+	_, err = remote.decryptRTP(nil, pkt)
+	require.Error(t, err)
+}
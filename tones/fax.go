@@ -0,0 +1,364 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tones
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/livekit/media-sdk"
+)
+
+// FaxTone is a fax or modem tone detected by FaxDetector.
+type FaxTone int
+
+const (
+	// ToneCNG is a fax calling tone: 1100 Hz, 0.5 s on, 3 s off (T.30).
+	ToneCNG FaxTone = iota + 1
+	// ToneANS is an answer tone (CED): 2100 Hz (V.25).
+	ToneANS
+	// ToneANSPR is an answer tone with phase reversals every 450 ms (/ANS). It disables echo cancellers (G.165).
+	ToneANSPR
+	// ToneANSam is an answer tone amplitude modulated by 15 Hz (V.8).
+	ToneANSam
+	// ToneANSamPR is an amplitude modulated answer tone with phase reversals (/ANSam).
+	ToneANSamPR
+)
+
+func (t FaxTone) String() string {
+	switch t {
+	case ToneCNG:
+		return "CNG"
+	case ToneANS:
+		return "ANS"
+	case ToneANSPR:
+		return "/ANS"
+	case ToneANSam:
+		return "ANSam"
+	case ToneANSamPR:
+		return "/ANSam"
+	}
+	return fmt.Sprintf("FaxTone(%d)", int(t))
+}
+
+// AnswerTone checks if the tone is one of 2100 Hz answer tones.
+func (t FaxTone) AnswerTone() bool {
+	return t >= ToneANS && t <= ToneANSamPR
+}
+
+const (
+	CNGFreq Hz = 1100
+	ANSFreq Hz = 2100
+	// ANSamFreq is the amplitude modulation frequency of ANSam.
+	ANSamFreq Hz = 15
+
+	faxBlockDur = 10 * time.Millisecond
+	// Tones must be present for this long to be detected.
+	faxMinDur = 400 * time.Millisecond
+	// Tones may drop out for this long, for example during phase reversals.
+	faxMaxDropout = 20 * time.Millisecond
+	// faxAMWindow must contain a whole number of modulation periods.
+	faxAMWindow = time.Second
+	// Phase reversals of answer tones are 450 ± 25 ms apart.
+	faxMinReversal = 400 * time.Millisecond
+	faxMaxReversal = 500 * time.Millisecond
+
+	// faxMinPower is a minimal mean square level of a tone, around -50 dBFS.
+	faxMinPower = 100 * 100
+	// faxMinRatio is a minimal part of the signal power that must be at the tone frequency.
+	faxMinRatio = 0.7
+	// faxMinDepth is a minimal amplitude modulation depth of ANSam, which is nominally 20%.
+	faxMinDepth = 0.1
+)
+
+const (
+	faxMinBlocks      = int(faxMinDur / faxBlockDur)
+	faxDropoutBlocks  = int(faxMaxDropout / faxBlockDur)
+	faxAMBlocks       = int(faxAMWindow / faxBlockDur)
+	faxMinRevBlocks   = int(faxMinReversal / faxBlockDur)
+	faxMaxRevBlocks   = int(faxMaxReversal / faxBlockDur)
+	faxBlocksPerSec   = int(time.Second / faxBlockDur)
+	faxMinPhaseChange = math.Pi / 2
+)
+
+// faxOscillator correlates the signal with a complex sinusoid. Phase of the sinusoid is continuous between blocks,
+// which allows tracking the phase of the tone.
+type faxOscillator struct {
+	c, s   float64 // current phase
+	dc, ds float64 // phase increment per sample
+	re, im float64 // correlation in the current block
+}
+
+func newFaxOscillator(freq Hz, sampleRate int) faxOscillator {
+	w := 2 * math.Pi * float64(freq) / float64(sampleRate)
+	return faxOscillator{c: 1, dc: math.Cos(w), ds: math.Sin(w)}
+}
+
+func (o *faxOscillator) add(v float64) {
+	o.re += v * o.c
+	o.im -= v * o.s
+	o.c, o.s = o.c*o.dc-o.s*o.ds, o.s*o.dc+o.c*o.ds
+}
+
+// take returns the correlation for the block and resets it.
+func (o *faxOscillator) take() (re, im float64) {
+	re, im = o.re, o.im
+	o.re, o.im = 0, 0
+	// Avoid accumulating rounding errors.
+	n := math.Hypot(o.c, o.s)
+	o.c, o.s = o.c/n, o.s/n
+	return re, im
+}
+
+func wrapPhase(p float64) float64 {
+	p = math.Mod(p+math.Pi, 2*math.Pi)
+	if p < 0 {
+		p += 2 * math.Pi
+	}
+	return p - math.Pi
+}
+
+// NewFaxDetector creates a detector of fax and modem tones for a given sample rate. See FaxTone.
+//
+// The callback is called once for each tone. Answer tones are classified as more of the tone is received,
+// so the callback may be called again with a more specific tone, for example ToneANS followed by ToneANSam.
+// Each tone is reported again after it stops and starts again.
+func NewFaxDetector(sampleRate int, fnc func(tone FaxTone)) *FaxDetector {
+	d := &FaxDetector{
+		fnc:   fnc,
+		block: max(1, sampleRate*int(faxBlockDur/time.Millisecond)/1000),
+		cng:   newFaxOscillator(CNGFreq, sampleRate),
+		ans:   newFaxOscillator(ANSFreq, sampleRate),
+		env:   make([]float64, faxAMBlocks),
+		amCos: make([]float64, faxAMBlocks),
+		amSin: make([]float64, faxAMBlocks),
+	}
+	for i := range faxAMBlocks {
+		w := 2 * math.Pi * float64(ANSamFreq) * float64(i) / float64(faxBlocksPerSec)
+		d.amCos[i], d.amSin[i] = math.Cos(w), math.Sin(w)
+	}
+	return d
+}
+
+// FaxDetector detects fax and modem tones in PCM audio. See NewFaxDetector.
+type FaxDetector struct {
+	fnc   func(tone FaxTone)
+	block int // samples per block
+	n     int // samples in the current block
+	power float64
+	cng   faxOscillator
+	ans   faxOscillator
+
+	cngBlocks  int
+	cngDropout int
+
+	ansBlocks  int // blocks with the answer tone
+	ansTime    int // blocks since the answer tone started, including dropouts
+	ansDropout int
+	ansTone    FaxTone // last reported answer tone
+	am         bool
+	pr         bool
+
+	// Envelope of the answer tone, used to detect amplitude modulation.
+	env          []float64
+	envN         int
+	amCos, amSin []float64
+
+	// Phase tracking of the answer tone, used to detect phase reversals.
+	havePhase   bool
+	lastPhase   float64
+	phaseOffset float64
+	phaseGap    int
+	drift       float64
+	lastRev     int
+}
+
+// Detect processes audio samples.
+func (d *FaxDetector) Detect(sample media.PCM16Sample) {
+	for _, v := range sample {
+		f := float64(v)
+		d.power += f * f
+		d.cng.add(f)
+		d.ans.add(f)
+		d.n++
+		if d.n == d.block {
+			d.n = 0
+			d.processBlock()
+		}
+	}
+}
+
+func (d *FaxDetector) processBlock() {
+	n := float64(d.block)
+	power := d.power
+	d.power = 0
+	cre, cim := d.cng.take()
+	are, aim := d.ans.take()
+
+	// Ratio of tone power to signal power is 1 for a pure tone.
+	loud := power/n >= faxMinPower
+	ratio := func(re, im float64) float64 {
+		return 2 * (re*re + im*im) / (n * power)
+	}
+	d.handleCNG(loud && ratio(cre, cim) >= faxMinRatio)
+	d.handleANS(loud && ratio(are, aim) >= faxMinRatio, 2*math.Hypot(are, aim)/n, math.Atan2(aim, are))
+}
+
+func (d *FaxDetector) handleCNG(on bool) {
+	if !on {
+		d.cngDropout++
+		if d.cngDropout > faxDropoutBlocks {
+			d.cngBlocks = 0
+		}
+		return
+	}
+	d.cngDropout = 0
+	d.cngBlocks++
+	if d.cngBlocks == faxMinBlocks {
+		d.fnc(ToneCNG)
+	}
+}
+
+func (d *FaxDetector) resetANS() {
+	d.ansBlocks, d.ansTime, d.ansDropout = 0, 0, 0
+	d.ansTone = 0
+	d.am, d.pr = false, false
+	d.envN = 0
+	d.havePhase = false
+	d.phaseOffset, d.drift = 0, 0
+	d.lastRev = -1
+}
+
+func (d *FaxDetector) handleANS(on bool, mag, phase float64) {
+	if !on {
+		if d.ansTime == 0 {
+			return
+		}
+		d.ansDropout++
+		if d.ansDropout > faxDropoutBlocks {
+			d.resetANS()
+			return
+		}
+		// Keep the envelope and phase timing regular during short dropouts.
+		d.ansTime++
+		d.trackEnvelope(mag)
+		d.phaseGap++
+		return
+	}
+	if d.ansTime == 0 {
+		d.resetANS()
+	}
+	d.ansDropout = 0
+	d.ansBlocks++
+	d.ansTime++
+	d.trackEnvelope(mag)
+	d.trackPhase(phase)
+	if d.ansBlocks < faxMinBlocks {
+		return
+	}
+	tone := ToneANS
+	if d.am {
+		tone = ToneANSam
+	}
+	if d.pr {
+		tone++ // phase reversal variant
+	}
+	if tone > d.ansTone {
+		d.ansTone = tone
+		d.fnc(tone)
+	}
+}
+
+// trackEnvelope detects 15 Hz amplitude modulation of the answer tone in a sliding window.
+func (d *FaxDetector) trackEnvelope(mag float64) {
+	// Window contains a whole number of modulation periods, so the ring buffer can be used directly.
+	d.env[d.envN%len(d.env)] = mag
+	d.envN++
+	if d.am || d.envN < len(d.env) {
+		return
+	}
+	var sum, re, im float64
+	for i, v := range d.env {
+		sum += v
+		re += v * d.amCos[i]
+		im += v * d.amSin[i]
+	}
+	if sum > 0 && 2*math.Hypot(re, im)/sum >= faxMinDepth {
+		d.am = true
+	}
+}
+
+// trackPhase detects phase reversals of the answer tone.
+//
+// The phase drifts linearly if the tone frequency differs from the nominal one. A reversal is detected when
+// the phase differs from the expected one by more than 90 degrees. A block with a reversal in the middle has
+// a phase of either side, so the reversal is always visible as a jump of 180 degrees.
+func (d *FaxDetector) trackPhase(phase float64) {
+	p := wrapPhase(phase + d.phaseOffset)
+	if d.havePhase {
+		gap := float64(d.phaseGap)
+		diff := wrapPhase(p - d.lastPhase)
+		if math.Abs(wrapPhase(diff-d.drift*gap)) > faxMinPhaseChange {
+			d.phaseOffset = wrapPhase(d.phaseOffset + math.Pi)
+			p = wrapPhase(p + math.Pi)
+			d.handleReversal()
+		} else {
+			d.drift = diff / gap
+		}
+	}
+	d.havePhase = true
+	d.lastPhase = p
+	d.phaseGap = 1
+}
+
+func (d *FaxDetector) handleReversal() {
+	if d.lastRev >= 0 {
+		if dt := d.ansTime - d.lastRev; dt >= faxMinRevBlocks && dt <= faxMaxRevBlocks {
+			d.pr = true
+		}
+	}
+	d.lastRev = d.ansTime
+}
+
+// FaxToneProcessor creates an audio processor that detects fax and modem tones. Audio is passed through unchanged.
+// See NewFaxDetector.
+func FaxToneProcessor(fnc func(tone FaxTone)) media.PCM16Processor {
+	return func(w media.WriteCloser[media.PCM16Sample]) media.WriteCloser[media.PCM16Sample] {
+		return &faxWriter{w: w, d: NewFaxDetector(w.SampleRate(), fnc)}
+	}
+}
+
+type faxWriter struct {
+	w media.WriteCloser[media.PCM16Sample]
+	d *FaxDetector
+}
+
+func (w *faxWriter) String() string {
+	return fmt.Sprintf("FaxDetector -> %s", w.w.String())
+}
+
+func (w *faxWriter) SampleRate() int {
+	return w.w.SampleRate()
+}
+
+func (w *faxWriter) WriteSample(sample media.PCM16Sample) error {
+	w.d.Detect(sample)
+	return w.w.WriteSample(sample)
+}
+
+func (w *faxWriter) Close() error {
+	return w.w.Close()
+}
@@ -0,0 +1,143 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tones
+
+import (
+	"math"
+	"math/rand/v2"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/media-sdk"
+)
+
+type faxSignal struct {
+	freq     float64
+	on, off  time.Duration // zero off means continuous tone
+	am       bool
+	reversal time.Duration // phase reversal interval
+}
+
+func (s faxSignal) generate(sampleRate int, dur time.Duration) media.PCM16Sample {
+	out := make(media.PCM16Sample, int(dur.Seconds()*float64(sampleRate)))
+	for i := range out {
+		t := float64(i) / float64(sampleRate)
+		if s.off > 0 && math.Mod(t, (s.on+s.off).Seconds()) >= s.on.Seconds() {
+			continue
+		}
+		amp := 8000.0
+		if s.am {
+			amp *= 1 + 0.2*math.Sin(2*math.Pi*float64(ANSamFreq)*t)
+		}
+		phase := 2 * math.Pi * s.freq * t
+		if s.reversal > 0 && int(t/s.reversal.Seconds())%2 == 1 {
+			phase += math.Pi
+		}
+		out[i] = int16(amp * math.Sin(phase))
+	}
+	return out
+}
+
+func detectFax(sampleRate int, audio media.PCM16Sample) []FaxTone {
+	var got []FaxTone
+	d := NewFaxDetector(sampleRate, func(tone FaxTone) {
+		got = append(got, tone)
+	})
+	// Use frames of a typical size.
+	frame := sampleRate / 50
+	for len(audio) > 0 {
+		n := min(frame, len(audio))
+		d.Detect(audio[:n])
+		audio = audio[n:]
+	}
+	return got
+}
+
+func TestFaxDetector(t *testing.T) {
+	const rev = 450 * time.Millisecond
+	cases := []struct {
+		name string
+		sig  faxSignal
+		dur  time.Duration
+		exp  []FaxTone
+	}{
+		{name: "CNG", sig: faxSignal{freq: 1100, on: time.Second / 2, off: 3 * time.Second}, dur: 7 * time.Second,
+			exp: []FaxTone{ToneCNG, ToneCNG}},
+		{name: "CNG short", sig: faxSignal{freq: 1100, on: 200 * time.Millisecond, off: time.Second}, dur: 3 * time.Second},
+		{name: "ANS", sig: faxSignal{freq: 2100}, dur: 3 * time.Second,
+			exp: []FaxTone{ToneANS}},
+		{name: "ANS offset", sig: faxSignal{freq: 2112}, dur: 3 * time.Second,
+			exp: []FaxTone{ToneANS}},
+		{name: "ANS PR", sig: faxSignal{freq: 2100, reversal: rev}, dur: 3 * time.Second,
+			exp: []FaxTone{ToneANS, ToneANSPR}},
+		{name: "ANS PR offset", sig: faxSignal{freq: 2088, reversal: rev}, dur: 3 * time.Second,
+			exp: []FaxTone{ToneANS, ToneANSPR}},
+		{name: "ANSam", sig: faxSignal{freq: 2100, am: true}, dur: 3 * time.Second,
+			exp: []FaxTone{ToneANS, ToneANSam}},
+		{name: "ANSam PR", sig: faxSignal{freq: 2100, am: true, reversal: rev}, dur: 3 * time.Second,
+			exp: []FaxTone{ToneANS, ToneANSPR, ToneANSamPR}},
+		{name: "dial tone", sig: faxSignal{freq: 425}, dur: 3 * time.Second},
+		{name: "DTMF range", sig: faxSignal{freq: 1209}, dur: 3 * time.Second},
+		{name: "silence", dur: 3 * time.Second},
+	}
+	for _, sampleRate := range []int{8000, 16000, 48000} {
+		t.Run(strconv.Itoa(sampleRate), func(t *testing.T) {
+			for _, c := range cases {
+				t.Run(c.name, func(t *testing.T) {
+					got := detectFax(sampleRate, c.sig.generate(sampleRate, c.dur))
+					require.Equal(t, c.exp, got)
+				})
+			}
+		})
+	}
+}
+
+func TestFaxDetectorNoise(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(1, 2))
+	audio := make(media.PCM16Sample, 5*8000)
+	for i := range audio {
+		audio[i] = int16(rnd.NormFloat64() * 3000)
+	}
+	require.Empty(t, detectFax(8000, audio))
+
+	// Tone is still detected with some noise.
+	tone := faxSignal{freq: 2100, am: true}.generate(8000, 3*time.Second)
+	for i := range tone {
+		tone[i] += int16(rnd.NormFloat64() * 500)
+	}
+	require.Equal(t, []FaxTone{ToneANS, ToneANSam}, detectFax(8000, tone))
+}
+
+func TestFaxToneProcessor(t *testing.T) {
+	var (
+		got []FaxTone
+		buf media.PCM16Sample
+	)
+	w := FaxToneProcessor(func(tone FaxTone) {
+		got = append(got, tone)
+	})(media.NopCloser(media.NewPCM16BufferWriter(&buf, 16000)))
+	require.Equal(t, 16000, w.SampleRate())
+
+	audio := faxSignal{freq: 1100}.generate(16000, time.Second)
+	require.NoError(t, w.WriteSample(audio))
+	require.NoError(t, w.Close())
+	require.Equal(t, audio, buf)
+	require.Equal(t, []FaxTone{ToneCNG}, got)
+	require.True(t, ToneANSamPR.AnswerTone())
+	require.False(t, ToneCNG.AnswerTone())
+}
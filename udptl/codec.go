@@ -0,0 +1,278 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udptl
+
+import (
+	"bytes"
+	"slices"
+)
+
+const (
+	// DefRedundancy is a default number of redundant IFP packets.
+	DefRedundancy = 3
+	// DefFECEntries and DefFECSpan are default FEC parameters.
+	DefFECEntries = 3
+	DefFECSpan    = 3
+
+	// historySize limits the number of packets kept for error recovery.
+	historySize = 32
+)
+
+// Config configures UDPTL error recovery of the sender.
+type Config struct {
+	ErrorCorrection ErrorCorrection
+	// Redundancy is the number of previous IFP packets sent with each packet. Used with ErrorCorrectionRedundancy.
+	Redundancy int
+	// FECEntries is the number of FEC entries in each packet and FECSpan is the number of packets covered by each entry.
+	// Used with ErrorCorrectionFEC.
+	FECEntries int
+	FECSpan    int
+	// MaxDatagram is the maximal datagram size accepted by the remote. Redundant packets are dropped to fit it.
+	// Zero means no limit.
+	MaxDatagram int
+}
+
+func (c *Config) setDefaults() {
+	if c.Redundancy <= 0 {
+		c.Redundancy = DefRedundancy
+	}
+	if c.FECEntries <= 0 {
+		c.FECEntries = DefFECEntries
+	}
+	if c.FECSpan <= 0 {
+		c.FECSpan = DefFECSpan
+	}
+	c.Redundancy = min(c.Redundancy, historySize-1)
+	if c.FECEntries*c.FECSpan >= historySize {
+		c.FECEntries, c.FECSpan = DefFECEntries, DefFECSpan
+	}
+}
+
+// history is a ring buffer of recent IFP packets, indexed by sequence number.
+type history struct {
+	seq  [historySize]uint16
+	set  [historySize]bool
+	data [historySize][]byte
+}
+
+func (h *history) put(seq uint16, data []byte) {
+	i := seq % historySize
+	h.seq[i] = seq
+	h.set[i] = true
+	h.data[i] = append(h.data[i][:0], data...)
+}
+
+func (h *history) get(seq uint16) ([]byte, bool) {
+	i := seq % historySize
+	if !h.set[i] || h.seq[i] != seq {
+		return nil, false
+	}
+	return h.data[i], true
+}
+
+// xorInto XORs src into dst, extending dst if necessary.
+func xorInto(dst, src []byte) []byte {
+	for len(dst) < len(src) {
+		dst = append(dst, 0)
+	}
+	for i, v := range src {
+		dst[i] ^= v
+	}
+	return dst
+}
+
+// NewEncoder creates an encoder that wraps IFP packets into UDPTL datagrams with error recovery data.
+func NewEncoder(conf Config) *Encoder {
+	conf.setDefaults()
+	return &Encoder{conf: conf}
+}
+
+// Encoder wraps IFP packets into UDPTL datagrams. See NewEncoder.
+type Encoder struct {
+	conf Config
+	seq  uint16
+	sent int
+	hist history
+}
+
+// Encode assigns the next sequence number to the IFP packet and returns an encoded UDPTL datagram.
+func (e *Encoder) Encode(ifp []byte) ([]byte, error) {
+	p := Packet{Seq: e.seq, Primary: ifp}
+	switch e.conf.ErrorCorrection {
+	case ErrorCorrectionRedundancy:
+		n := min(e.conf.Redundancy, e.sent)
+		p.Secondary = make([][]byte, 0, n)
+		for i := range n {
+			data, _ := e.hist.get(e.seq - 1 - uint16(i))
+			p.Secondary = append(p.Secondary, data)
+		}
+	case ErrorCorrectionFEC:
+		p.FEC = e.fec()
+	}
+	buf, err := p.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	// Drop the oldest redundant packets until the datagram fits.
+	for e.conf.MaxDatagram > 0 && len(buf) > e.conf.MaxDatagram && len(p.Secondary) != 0 {
+		p.Secondary = p.Secondary[:len(p.Secondary)-1]
+		if buf, err = p.Marshal(); err != nil {
+			return nil, err
+		}
+	}
+	e.hist.put(e.seq, ifp)
+	e.seq++
+	e.sent++
+	return buf, nil
+}
+
+func (e *Encoder) fec() *FEC {
+	span, entries := e.conf.FECSpan, e.conf.FECEntries
+	if e.sent < span*entries {
+		// Not enough packets sent yet, reduce the number of entries.
+		entries = e.sent / span
+		if entries == 0 {
+			return &FEC{}
+		}
+	}
+	f := &FEC{Span: span, Entries: make([][]byte, entries)}
+	for i := range entries {
+		var out []byte
+		for _, seq := range fecCovered(e.seq, span, entries, i) {
+			data, _ := e.hist.get(seq)
+			out = xorInto(out, data)
+		}
+		f.Entries[i] = out
+	}
+	return f
+}
+
+// fecCovered returns sequence numbers of packets covered by the FEC entry.
+func fecCovered(seq uint16, span, entries, entry int) []uint16 {
+	out := make([]uint16, 0, span)
+	first := seq + uint16(entry) - uint16(span*entries)
+	for k := range span {
+		out = append(out, first+uint16(k*entries))
+	}
+	return out
+}
+
+// IFP is an IFP packet received over UDPTL.
+type IFP struct {
+	Seq  uint16
+	Data []byte
+	// Recovered is set if the packet was lost and then recovered from redundant or FEC data.
+	Recovered bool
+}
+
+// DecoderStats contains UDPTL receiver statistics.
+type DecoderStats struct {
+	Packets   uint64 // valid datagrams received
+	Duplicate uint64 // duplicate or late datagrams
+	Recovered uint64 // IFP packets recovered from error recovery data
+	Lost      uint64 // IFP packets that cannot be recovered
+}
+
+// NewDecoder creates a decoder that unwraps IFP packets from UDPTL datagrams, recovering lost packets if possible.
+func NewDecoder() *Decoder {
+	return &Decoder{}
+}
+
+// Decoder unwraps IFP packets from UDPTL datagrams. See NewDecoder.
+type Decoder struct {
+	started bool
+	next    uint16
+	hist    history
+	stats   DecoderStats
+}
+
+// Stats returns receiver statistics.
+func (d *Decoder) Stats() DecoderStats {
+	return d.stats
+}
+
+// Decode parses the datagram and returns IFP packets in sequence order, including recovered ones.
+// Duplicate and late datagrams return no packets. Returned data is only valid until the next call.
+func (d *Decoder) Decode(data []byte) ([]IFP, error) {
+	var p Packet
+	if err := p.Unmarshal(data); err != nil {
+		return nil, err
+	}
+	d.stats.Packets++
+	if !d.started {
+		d.started = true
+		d.next = p.Seq
+	}
+	if diff := int16(p.Seq - d.next); diff < 0 {
+		d.stats.Duplicate++
+		return nil, nil
+	}
+	first := d.next
+	if gap := p.Seq - d.next; gap > historySize {
+		// Error recovery data never covers that many packets.
+		d.stats.Lost += uint64(gap - historySize)
+		first = p.Seq - historySize
+	}
+	var out []IFP
+	for seq := first; seq != p.Seq; seq++ {
+		ifp, ok := d.recover(&p, seq)
+		if !ok {
+			d.stats.Lost++
+			continue
+		}
+		d.stats.Recovered++
+		d.hist.put(seq, ifp)
+		out = append(out, IFP{Seq: seq, Recovered: true})
+	}
+	d.hist.put(p.Seq, p.Primary)
+	out = append(out, IFP{Seq: p.Seq})
+	for i := range out {
+		out[i].Data, _ = d.hist.get(out[i].Seq)
+	}
+	d.next = p.Seq + 1
+	return out, nil
+}
+
+// recover attempts to recover a lost IFP packet from redundant or FEC data of the packet.
+func (d *Decoder) recover(p *Packet, seq uint16) ([]byte, bool) {
+	if i := int(p.Seq - seq - 1); i < len(p.Secondary) {
+		return p.Secondary[i], true
+	}
+	if p.FEC == nil || p.FEC.Span <= 0 {
+		return nil, false
+	}
+	entries := len(p.FEC.Entries)
+	for i, fec := range p.FEC.Entries {
+		covered := fecCovered(p.Seq, p.FEC.Span, entries, i)
+		if !slices.Contains(covered, seq) {
+			continue
+		}
+		// Recovery is possible only if all other packets covered by the entry are present.
+		out := bytes.Clone(fec)
+		for _, s := range covered {
+			if s == seq {
+				continue
+			}
+			data, ok := d.hist.get(s)
+			if !ok {
+				return nil, false
+			}
+			out = xorInto(out, data)
+		}
+		// Recovered packet is padded to the length of the longest covered packet.
+		return out, true
+	}
+	return nil, false
+}
@@ -0,0 +1,218 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package udptl implements UDPTL transport for T.38 fax relay (ITU-T T.38 Annex A).
+//
+// Each UDPTL packet carries a primary IFP (Internet Facsimile Protocol) packet and error recovery data:
+// either redundant copies of previous IFP packets, or forward error correction (FEC) data.
+package udptl
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrorCorrection is an error recovery mode of UDPTL.
+type ErrorCorrection int
+
+const (
+	ErrorCorrectionNone ErrorCorrection = iota
+	ErrorCorrectionRedundancy
+	ErrorCorrectionFEC
+)
+
+func (e ErrorCorrection) String() string {
+	switch e {
+	case ErrorCorrectionNone:
+		return "none"
+	case ErrorCorrectionRedundancy:
+		return "redundancy"
+	case ErrorCorrectionFEC:
+		return "fec"
+	}
+	return fmt.Sprintf("ErrorCorrection(%d)", int(e))
+}
+
+const (
+	// maxLength is the maximal length that can be encoded without fragmentation.
+	maxLength = 0x3fff
+	// fecFlag selects FEC in the error recovery field.
+	fecFlag = 0x80
+)
+
+var (
+	ErrShortPacket = errors.New("udptl: packet too short")
+	ErrFragmented  = errors.New("udptl: fragmented lengths are not supported")
+	ErrTooLarge    = errors.New("udptl: field too large")
+)
+
+// FEC contains forward error correction data of a packet.
+//
+// Each entry is an XOR of Span previous IFP packets. Entry i covers packets with sequence numbers
+// Seq+i-Span*len(Entries)+k*len(Entries), for k in [0, Span).
+type FEC struct {
+	Span    int
+	Entries [][]byte
+}
+
+// Packet is a single UDPTL datagram.
+type Packet struct {
+	Seq     uint16
+	Primary []byte // primary IFP packet
+	// Secondary contains redundant copies of previous IFP packets, most recent first (Seq-1, Seq-2, ...).
+	Secondary [][]byte
+	// FEC is set if the packet carries forward error correction data instead of redundant packets.
+	FEC *FEC
+}
+
+func appendLength(b []byte, n int) ([]byte, error) {
+	switch {
+	case n < 0x80:
+		return append(b, byte(n)), nil
+	case n <= maxLength:
+		return append(b, 0x80|byte(n>>8), byte(n)), nil
+	}
+	return b, ErrTooLarge
+}
+
+func appendOpenType(b []byte, data []byte) ([]byte, error) {
+	b, err := appendLength(b, len(data))
+	if err != nil {
+		return b, err
+	}
+	return append(b, data...), nil
+}
+
+func readLength(data []byte) (int, []byte, error) {
+	if len(data) < 1 {
+		return 0, nil, ErrShortPacket
+	}
+	switch {
+	case data[0]&0x80 == 0:
+		return int(data[0]), data[1:], nil
+	case data[0]&0x40 == 0:
+		if len(data) < 2 {
+			return 0, nil, ErrShortPacket
+		}
+		return int(data[0]&0x3f)<<8 | int(data[1]), data[2:], nil
+	}
+	return 0, nil, ErrFragmented
+}
+
+func readOpenType(data []byte) ([]byte, []byte, error) {
+	n, data, err := readLength(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(data) < n {
+		return nil, nil, ErrShortPacket
+	}
+	return data[:n:n], data[n:], nil
+}
+
+// Marshal encodes the packet in PER aligned format.
+func (p *Packet) Marshal() ([]byte, error) {
+	return p.AppendTo(nil)
+}
+
+// AppendTo appends the encoded packet to the buffer.
+func (p *Packet) AppendTo(b []byte) ([]byte, error) {
+	b = append(b, byte(p.Seq>>8), byte(p.Seq))
+	b, err := appendOpenType(b, p.Primary)
+	if err != nil {
+		return b, err
+	}
+	if p.FEC == nil {
+		b = append(b, 0)
+		b, err = appendLength(b, len(p.Secondary))
+		if err != nil {
+			return b, err
+		}
+		for _, s := range p.Secondary {
+			if b, err = appendOpenType(b, s); err != nil {
+				return b, err
+			}
+		}
+		return b, nil
+	}
+	if p.FEC.Span < 0 || p.FEC.Span > 0xff {
+		return b, ErrTooLarge
+	}
+	// Span is an unconstrained integer, encoded with its length in bytes.
+	b = append(b, fecFlag, 1, byte(p.FEC.Span))
+	b, err = appendLength(b, len(p.FEC.Entries))
+	if err != nil {
+		return b, err
+	}
+	for _, e := range p.FEC.Entries {
+		if b, err = appendOpenType(b, e); err != nil {
+			return b, err
+		}
+	}
+	return b, nil
+}
+
+// Unmarshal decodes the packet. Decoded fields reference the data slice.
+func (p *Packet) Unmarshal(data []byte) error {
+	*p = Packet{}
+	if len(data) < 2 {
+		return ErrShortPacket
+	}
+	p.Seq = uint16(data[0])<<8 | uint16(data[1])
+	data = data[2:]
+	var err error
+	p.Primary, data, err = readOpenType(data)
+	if err != nil {
+		return err
+	}
+	if len(data) < 1 {
+		return ErrShortPacket
+	}
+	fec := data[0]&fecFlag != 0
+	data = data[1:]
+	if fec {
+		if len(data) < 1 {
+			return ErrShortPacket
+		}
+		n := int(data[0])
+		if n < 1 || n > 4 || len(data) < 1+n {
+			return fmt.Errorf("udptl: invalid FEC span length: %d", n)
+		}
+		span := 0
+		for _, v := range data[1 : 1+n] {
+			span = span<<8 | int(v)
+		}
+		data = data[1+n:]
+		p.FEC = &FEC{Span: span}
+	}
+	cnt, data, err := readLength(data)
+	if err != nil {
+		return err
+	}
+	list := make([][]byte, 0, min(cnt, len(data)))
+	for range cnt {
+		var v []byte
+		v, data, err = readOpenType(data)
+		if err != nil {
+			return err
+		}
+		list = append(list, v)
+	}
+	if fec {
+		p.FEC.Entries = list
+	} else {
+		p.Secondary = list
+	}
+	return nil
+}
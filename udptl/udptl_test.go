@@ -0,0 +1,158 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udptl
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPacket(t *testing.T) {
+	cases := []struct {
+		name string
+		pkt  Packet
+		data []byte
+	}{
+		{
+			name: "no recovery",
+			pkt:  Packet{Seq: 0x0102, Primary: []byte{0x06, 0x01}, Secondary: [][]byte{}},
+			data: []byte{0x01, 0x02, 0x02, 0x06, 0x01, 0x00, 0x00},
+		},
+		{
+			name: "redundancy",
+			pkt:  Packet{Seq: 1, Primary: []byte{0x06, 0x01}, Secondary: [][]byte{{0x02}, {}}},
+			data: []byte{0x00, 0x01, 0x02, 0x06, 0x01, 0x00, 0x02, 0x01, 0x02, 0x00},
+		},
+		{
+			name: "fec",
+			pkt:  Packet{Seq: 7, Primary: []byte{0x01}, FEC: &FEC{Span: 3, Entries: [][]byte{{0xaa, 0xbb}}}},
+			data: []byte{0x00, 0x07, 0x01, 0x01, 0x80, 0x01, 0x03, 0x01, 0x02, 0xaa, 0xbb},
+		},
+		{
+			name: "long",
+			pkt:  Packet{Seq: 2, Primary: bytes.Repeat([]byte{0x55}, 300), Secondary: [][]byte{}},
+			data: append(append([]byte{0x00, 0x02, 0x81, 0x2c}, bytes.Repeat([]byte{0x55}, 300)...), 0x00, 0x00),
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			data, err := c.pkt.Marshal()
+			require.NoError(t, err)
+			require.Equal(t, c.data, data)
+
+			var got Packet
+			require.NoError(t, got.Unmarshal(data))
+			require.Equal(t, c.pkt, got)
+
+			for i := range data {
+				require.Error(t, got.Unmarshal(data[:i]))
+			}
+		})
+	}
+	_, err := (&Packet{Primary: make([]byte, maxLength+1)}).Marshal()
+	require.ErrorIs(t, err, ErrTooLarge)
+
+	var p Packet
+	require.ErrorIs(t, p.Unmarshal([]byte{0x00, 0x01, 0xc1}), ErrFragmented)
+}
+
+func ifpPacket(i int) []byte {
+	return bytes.Repeat([]byte{byte(i + 1)}, 1+i%5)
+}
+
+// transmit encodes n packets, drops lost ones and returns all decoded IFP packets.
+func transmit(t *testing.T, conf Config, n int, lost map[int]bool) ([]IFP, DecoderStats) {
+	enc := NewEncoder(conf)
+	dec := NewDecoder()
+	var out []IFP
+	for i := range n {
+		data, err := enc.Encode(ifpPacket(i))
+		require.NoError(t, err)
+		if conf.MaxDatagram > 0 {
+			require.LessOrEqual(t, len(data), conf.MaxDatagram)
+		}
+		if lost[i] {
+			continue
+		}
+		list, err := dec.Decode(data)
+		require.NoError(t, err)
+		for _, p := range list {
+			p.Data = bytes.Clone(p.Data)
+			out = append(out, p)
+		}
+	}
+	return out, dec.Stats()
+}
+
+func TestRedundancy(t *testing.T) {
+	out, stats := transmit(t, Config{ErrorCorrection: ErrorCorrectionRedundancy, Redundancy: 2}, 10, map[int]bool{
+		3: true, 4: true, // recovered
+		6: true, 7: true, 8: true, // only 7 and 8 are recovered
+	})
+	require.Len(t, out, 9)
+	for _, p := range out {
+		require.Equal(t, ifpPacket(int(p.Seq)), p.Data)
+		require.Equal(t, p.Seq == 3 || p.Seq == 4 || p.Seq == 7 || p.Seq == 8, p.Recovered)
+		require.NotEqual(t, uint16(6), p.Seq)
+	}
+	require.Equal(t, DecoderStats{Packets: 5, Recovered: 4, Lost: 1}, stats)
+}
+
+func TestFEC(t *testing.T) {
+	out, stats := transmit(t, Config{ErrorCorrection: ErrorCorrectionFEC, FECEntries: 2, FECSpan: 2}, 20, map[int]bool{
+		10: true,
+	})
+	require.Len(t, out, 20)
+	for i, p := range out {
+		require.EqualValues(t, i, p.Seq)
+		require.Equal(t, i == 10, p.Recovered)
+		// Recovered packet is padded to the longest covered packet.
+		require.Equal(t, ifpPacket(i), p.Data[:len(ifpPacket(i))])
+	}
+	require.Equal(t, DecoderStats{Packets: 19, Recovered: 1}, stats)
+}
+
+func TestNoErrorCorrection(t *testing.T) {
+	out, stats := transmit(t, Config{}, 5, map[int]bool{2: true})
+	require.Len(t, out, 4)
+	require.Equal(t, DecoderStats{Packets: 4, Lost: 1}, stats)
+}
+
+func TestMaxDatagram(t *testing.T) {
+	out, _ := transmit(t, Config{ErrorCorrection: ErrorCorrectionRedundancy, Redundancy: 5, MaxDatagram: 16}, 20, nil)
+	require.Len(t, out, 20)
+}
+
+func TestDuplicate(t *testing.T) {
+	enc := NewEncoder(Config{ErrorCorrection: ErrorCorrectionRedundancy})
+	dec := NewDecoder()
+	first, err := enc.Encode([]byte{1})
+	require.NoError(t, err)
+	second, err := enc.Encode([]byte{2})
+	require.NoError(t, err)
+
+	out, err := dec.Decode(second)
+	require.NoError(t, err)
+	require.Len(t, out, 1)
+	out, err = dec.Decode(first)
+	require.NoError(t, err)
+	require.Empty(t, out)
+	out, err = dec.Decode(second)
+	require.NoError(t, err)
+	require.Empty(t, out)
+	require.Equal(t, DecoderStats{Packets: 3, Duplicate: 2}, dec.Stats())
+}
@@ -0,0 +1,62 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package vp8 implements VP8 RTP payload format (RFC 7741). SDP negotiation is left to the application.
+package vp8
+
+import (
+	prtp "github.com/pion/rtp"
+	"github.com/pion/rtp/codecs"
+
+	"github.com/livekit/media-sdk"
+	"github.com/livekit/media-sdk/rtp"
+)
+
+const SDPName = "VP8/90000"
+
+// NewCodec creates VP8 video codec.
+func NewCodec() rtp.VideoCodec {
+	return &codec{info: media.CodecInfo{
+		SDPName:      SDPName,
+		SampleRate:   rtp.VideoClockRate,
+		RTPClockRate: rtp.VideoClockRate,
+		RTPIsStatic:  false,
+		FileExt:      "ivf",
+	}}
+}
+
+type codec struct {
+	info media.CodecInfo
+}
+
+func (c *codec) Info() media.CodecInfo {
+	return c.info
+}
+
+func (c *codec) NewDepacketizer() prtp.Depacketizer {
+	return &codecs.VP8Packet{}
+}
+
+func (c *codec) NewPayloader() prtp.Payloader {
+	return &codecs.VP8Payloader{EnablePictureID: true}
+}
+
+func (c *codec) IsKeyFrame(frame []byte) bool {
+	return IsKeyFrame(frame)
+}
+
+// IsKeyFrame checks if the VP8 frame is a keyframe. The inverse key frame flag is stored in the frame tag (RFC 6386).
+func IsKeyFrame(frame []byte) bool {
+	return len(frame) != 0 && frame[0]&0x01 == 0
+}
@@ -0,0 +1,71 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vp8
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/media-sdk/rtp"
+)
+
+type frameWriter struct {
+	frames []rtp.VideoFrame
+}
+
+func (w *frameWriter) String() string { return "frames" }
+
+func (w *frameWriter) WriteVideo(f rtp.VideoFrame) error {
+	f.Data = bytes.Clone(f.Data)
+	w.frames = append(w.frames, f)
+	return nil
+}
+
+func TestRoundTrip(t *testing.T) {
+	key := make([]byte, 2500)
+	key[0] = 0x10 // keyframe, show_frame
+	for i := 1; i < len(key); i++ {
+		key[i] = byte(i)
+	}
+	frames := []rtp.VideoFrame{
+		{Timestamp: 0, KeyFrame: true, Data: key},
+		{Timestamp: 3000, Data: []byte{0x11, 1, 2, 3}},
+	}
+	require.True(t, IsKeyFrame(frames[0].Data))
+	require.False(t, IsKeyFrame(frames[1].Data))
+	codec := NewCodec()
+
+	var buf rtp.Buffer
+	out := rtp.NewVideoStreamOut(rtp.NewSeqWriter(&buf).NewStream(96, rtp.VideoClockRate), codec, 0)
+	for _, f := range frames {
+		require.NoError(t, out.WriteVideo(f))
+	}
+	require.Len(t, buf, 4)
+
+	var w frameWriter
+	r := rtp.NewVideoReceiver(codec, &w)
+	defer r.Close()
+	for _, p := range buf {
+		require.NoError(t, r.HandleRTP(&p.Header, p.Payload))
+	}
+	require.Len(t, w.frames, len(frames))
+	for i, f := range w.frames {
+		require.Equal(t, frames[i].KeyFrame, f.KeyFrame)
+		require.Equal(t, frames[i].Data, f.Data)
+		require.Equal(t, buf[0].Timestamp+frames[i].Timestamp, f.Timestamp)
+	}
+}
@@ -0,0 +1,236 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wav
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/livekit/media-sdk"
+	"github.com/livekit/media-sdk/g711"
+)
+
+// Audio formats supported by the reader.
+const (
+	FormatPCM        = 1
+	FormatFloat      = 3
+	FormatALaw       = 6
+	FormatULaw       = 7
+	formatExtensible = 0xFFFE
+)
+
+var (
+	ErrNotWAV            = errors.New("not a WAV file")
+	ErrUnsupportedFormat = errors.New("unsupported WAV format")
+)
+
+// Format describes the audio stored in WAV file.
+type Format struct {
+	Format        uint16
+	Channels      int
+	SampleRate    int
+	BitsPerSample int
+}
+
+// NewReader parses WAV file header and returns a reader for audio samples.
+// Samples are converted to the requested sample rate and number of channels.
+// PCM (8, 16, 24 and 32 bit), float, A-law and µ-law formats are supported.
+func NewReader(r io.Reader, sampleRate int, channels int) (*Reader, error) {
+	d := &decoder{r: r}
+	if err := d.readHeader(); err != nil {
+		return nil, err
+	}
+	return &Reader{
+		format:     d.format,
+		sampleRate: sampleRate,
+		channels:   channels,
+		r:          media.ConvertReader(d, d.format.SampleRate, d.format.Channels, sampleRate, channels),
+	}, nil
+}
+
+type Reader struct {
+	format     Format
+	sampleRate int
+	channels   int
+	r          media.ReadCloser[media.PCM16Sample]
+}
+
+// Format returns the format of the source file.
+func (r *Reader) Format() Format {
+	return r.format
+}
+
+func (r *Reader) SampleRate() int {
+	return r.sampleRate
+}
+
+func (r *Reader) Channels() int {
+	return r.channels
+}
+
+func (r *Reader) ReadSample(buf media.PCM16Sample) (int, error) {
+	return r.r.ReadSample(buf)
+}
+
+func (r *Reader) Close() error {
+	return r.r.Close()
+}
+
+// decoder reads interleaved samples from the data chunk.
+type decoder struct {
+	r        io.Reader
+	format   Format
+	dataSize uint64 // from ds64 chunk
+	buf      []byte
+}
+
+func (d *decoder) Close() error {
+	if c, ok := d.r.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+func (d *decoder) readHeader() error {
+	var hdr [12]byte
+	if _, err := io.ReadFull(d.r, hdr[:]); err != nil {
+		return fmt.Errorf("%w: %w", ErrNotWAV, err)
+	}
+	switch string(hdr[:4]) {
+	case "RIFF", "RF64":
+	default:
+		return ErrNotWAV
+	}
+	if string(hdr[8:12]) != "WAVE" {
+		return ErrNotWAV
+	}
+	le := binary.LittleEndian
+	hasFormat := false
+	for {
+		var chunk [8]byte
+		if _, err := io.ReadFull(d.r, chunk[:]); err != nil {
+			return fmt.Errorf("cannot read WAV chunk: %w", err)
+		}
+		id, size := string(chunk[:4]), uint64(le.Uint32(chunk[4:]))
+		switch id {
+		case "data":
+			if !hasFormat {
+				return errors.New("no format chunk in WAV file")
+			}
+			if size == math.MaxUint32 && d.dataSize != 0 {
+				size = d.dataSize
+			}
+			if size != 0 && size != math.MaxUint32 {
+				// Size is known, ignore anything after the data chunk.
+				d.r = io.LimitReader(d.r, int64(size))
+			}
+			return nil
+		case "fmt ", "ds64":
+			data := make([]byte, size+size%2)
+			if _, err := io.ReadFull(d.r, data); err != nil {
+				return fmt.Errorf("cannot read WAV chunk: %w", err)
+			}
+			if id == "ds64" {
+				if size < 16 {
+					return errors.New("invalid ds64 chunk")
+				}
+				d.dataSize = le.Uint64(data[8:])
+				continue
+			}
+			if err := d.parseFormat(data[:size]); err != nil {
+				return err
+			}
+			hasFormat = true
+		default:
+			// Skip unknown chunk, including the padding byte.
+			if _, err := io.CopyN(io.Discard, d.r, int64(size+size%2)); err != nil {
+				return fmt.Errorf("cannot read WAV chunk: %w", err)
+			}
+		}
+	}
+}
+
+func (d *decoder) parseFormat(data []byte) error {
+	if len(data) < 16 {
+		return errors.New("invalid WAV format chunk")
+	}
+	le := binary.LittleEndian
+	f := Format{
+		Format:        le.Uint16(data[0:]),
+		Channels:      int(le.Uint16(data[2:])),
+		SampleRate:    int(le.Uint32(data[4:])),
+		BitsPerSample: int(le.Uint16(data[14:])),
+	}
+	if f.Format == formatExtensible {
+		if len(data) < 26 {
+			return errors.New("invalid WAV format chunk")
+		}
+		// First two bytes of the sub-format GUID contain the format code.
+		f.Format = le.Uint16(data[24:])
+	}
+	if f.Channels <= 0 || f.SampleRate <= 0 {
+		return fmt.Errorf("%w: %d channels at %d Hz", ErrUnsupportedFormat, f.Channels, f.SampleRate)
+	}
+	switch {
+	case f.Format == FormatPCM && (f.BitsPerSample == 8 || f.BitsPerSample == 16 || f.BitsPerSample == 24 || f.BitsPerSample == 32):
+	case f.Format == FormatFloat && f.BitsPerSample == 32:
+	case (f.Format == FormatALaw || f.Format == FormatULaw) && f.BitsPerSample == 8:
+	default:
+		return fmt.Errorf("%w: format %d, %d bits", ErrUnsupportedFormat, f.Format, f.BitsPerSample)
+	}
+	d.format = f
+	return nil
+}
+
+func (d *decoder) ReadSample(out media.PCM16Sample) (int, error) {
+	bps := d.format.BitsPerSample / 8
+	sz := len(out) * bps
+	if cap(d.buf) < sz {
+		d.buf = make([]byte, sz)
+	}
+	buf := d.buf[:sz]
+	n, err := io.ReadFull(d.r, buf)
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		err = io.EOF
+	}
+	n /= bps
+	buf = buf[:n*bps]
+	switch d.format.Format {
+	case FormatALaw:
+		g711.DecodeALawTo(out, buf)
+	case FormatULaw:
+		g711.DecodeULawTo(out, buf)
+	case FormatFloat:
+		for i := range n {
+			v := math.Float32frombits(binary.LittleEndian.Uint32(buf[i*4:]))
+			out[i] = int16(max(-1, min(v, 1)) * 0x7fff)
+		}
+	default:
+		for i := range n {
+			b := buf[i*bps : (i+1)*bps]
+			switch bps {
+			case 1:
+				out[i] = (int16(b[0]) - 0x80) << 8
+			default:
+				// Take two most significant bytes.
+				out[i] = int16(binary.LittleEndian.Uint16(b[bps-2:]))
+			}
+		}
+	}
+	return n, err
+}
@@ -0,0 +1,178 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/media-sdk"
+	"github.com/livekit/media-sdk/g711"
+)
+
+func readAll(t testing.TB, r media.Reader[media.PCM16Sample]) media.PCM16Sample {
+	var out media.PCM16Sample
+	buf := make(media.PCM16Sample, 100)
+	for {
+		n, err := r.ReadSample(buf)
+		out = append(out, buf[:n]...)
+		if err == io.EOF {
+			return out
+		}
+		require.NoError(t, err)
+	}
+}
+
+func genSamples(n int) media.PCM16Sample {
+	out := make(media.PCM16Sample, n)
+	for i := range out {
+		out[i] = int16(i * 13)
+	}
+	return out
+}
+
+func TestWriteRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.wav")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+
+	exp := genSamples(8000)
+	w := NewWriter(f, 8000, 1)
+	require.Equal(t, 8000, w.SampleRate())
+	for i := 0; i < len(exp); i += 160 {
+		require.NoError(t, w.WriteSample(exp[i:i+160]))
+	}
+	require.NoError(t, w.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Len(t, data, headerSize+len(exp)*2)
+	require.Equal(t, "RIFF", string(data[:4]))
+	require.EqualValues(t, len(data)-8, binary.LittleEndian.Uint32(data[4:]))
+	require.EqualValues(t, len(exp)*2, binary.LittleEndian.Uint32(data[headerSize-4:]))
+
+	r, err := NewReader(bytes.NewReader(data), 8000, 1)
+	require.NoError(t, err)
+	require.Equal(t, Format{Format: FormatPCM, Channels: 1, SampleRate: 8000, BitsPerSample: 16}, r.Format())
+	require.Equal(t, exp, readAll(t, r))
+	require.NoError(t, r.Close())
+
+	// Upmix
+	r, err = NewReader(bytes.NewReader(data), 8000, 2)
+	require.NoError(t, err)
+	got := readAll(t, r)
+	require.Len(t, got, 2*len(exp))
+	require.Equal(t, exp[10], got[20])
+	require.Equal(t, exp[10], got[21])
+	// Buffer must fit all channels.
+	r, err = NewReader(bytes.NewReader(data), 8000, 2)
+	require.NoError(t, err)
+	_, err = r.ReadSample(make(media.PCM16Sample, 1))
+	require.ErrorIs(t, err, io.ErrShortBuffer)
+
+	// Resample
+	r, err = NewReader(bytes.NewReader(data), 16000, 1)
+	require.NoError(t, err)
+	require.InDelta(t, 2*len(exp), len(readAll(t, r)), 320)
+}
+
+type bufferCloser struct {
+	bytes.Buffer
+}
+
+func (*bufferCloser) Close() error { return nil }
+
+func TestWriteStream(t *testing.T) {
+	var buf bufferCloser
+	exp := genSamples(1600)
+	w := NewWriter(&buf, 16000, 1)
+	require.NoError(t, w.WriteSample(exp))
+	require.NoError(t, w.Close())
+
+	data := buf.Bytes()
+	require.Len(t, data, headerSize+len(exp)*2)
+	require.EqualValues(t, uint32(0xffffffff), binary.LittleEndian.Uint32(data[4:]))
+	require.EqualValues(t, uint32(0xffffffff), binary.LittleEndian.Uint32(data[headerSize-4:]))
+
+	r, err := NewReader(bytes.NewReader(data), 16000, 1)
+	require.NoError(t, err)
+	require.Equal(t, exp, readAll(t, r))
+}
+
+func TestReadG711(t *testing.T) {
+	exp := genSamples(800)
+	for _, format := range []uint16{FormatALaw, FormatULaw} {
+		enc := make([]byte, len(exp))
+		dec := make(media.PCM16Sample, len(exp))
+		if format == FormatALaw {
+			g711.EncodeALawTo(enc, exp)
+			g711.DecodeALawTo(dec, enc)
+		} else {
+			g711.EncodeULawTo(enc, exp)
+			g711.DecodeULawTo(dec, enc)
+		}
+		le := binary.LittleEndian
+		var b []byte
+		b = append(b, "RIFF"...)
+		b = le.AppendUint32(b, uint32(4+8+16+8+8+len(enc)))
+		b = append(b, "WAVE"...)
+		b = append(b, "fmt "...)
+		b = le.AppendUint32(b, 16)
+		b = le.AppendUint16(b, format)
+		b = le.AppendUint16(b, 1)
+		b = le.AppendUint32(b, 8000)
+		b = le.AppendUint32(b, 8000)
+		b = le.AppendUint16(b, 1)
+		b = le.AppendUint16(b, 8)
+		b = append(b, "LIST"...) // unknown chunk with padding
+		b = le.AppendUint32(b, 3)
+		b = append(b, 1, 2, 3, 0)
+		b = append(b, "data"...)
+		b = le.AppendUint32(b, uint32(len(enc)))
+		b = append(b, enc...)
+
+		r, err := NewReader(bytes.NewReader(b), 8000, 1)
+		require.NoError(t, err)
+		require.Equal(t, format, r.Format().Format)
+		require.Equal(t, dec, readAll(t, r))
+	}
+}
+
+func TestReadRF64(t *testing.T) {
+	exp := genSamples(1000)
+	b := appendHeader(nil, 16000, 1, 5<<30)
+	require.Len(t, b, headerSize)
+	require.Equal(t, "RF64", string(b[:4]))
+	for _, v := range exp {
+		b = binary.LittleEndian.AppendUint16(b, uint16(v))
+	}
+	r, err := NewReader(bytes.NewReader(b), 16000, 1)
+	require.NoError(t, err)
+	require.Equal(t, 16000, r.Format().SampleRate)
+	require.Equal(t, exp, readAll(t, r))
+}
+
+func TestReadInvalid(t *testing.T) {
+	_, err := NewReader(bytes.NewReader([]byte("RIFF\x00\x00\x00\x00AVI ")), 8000, 1)
+	require.ErrorIs(t, err, ErrNotWAV)
+	_, err = NewReader(bytes.NewReader(nil), 8000, 1)
+	require.ErrorIs(t, err, ErrNotWAV)
+}
@@ -0,0 +1,168 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wav
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/livekit/media-sdk"
+)
+
+const (
+	// headerSize is the size of the header written by the writer.
+	// It includes a JUNK chunk that is replaced with ds64 for RF64 files.
+	headerSize = 12 + (8 + ds64Size) + (8 + 16) + 8
+	ds64Size   = 28
+)
+
+// NewWriter creates a writer for 16 bit PCM WAV file. It writes the header first and updates sizes in it on Close.
+// Files larger than 4 GB are written in RF64 format.
+//
+// If w doesn't implement io.Seeker, sizes in the header are set to the maximal value, which most readers
+// interpret as "read until the end of the stream".
+func NewWriter(w io.WriteCloser, sampleRate int, channels int) media.PCM16Writer {
+	wr := &writer{
+		w:          w,
+		bw:         bufio.NewWriter(w),
+		sampleRate: sampleRate,
+		channels:   channels,
+	}
+	if s, ok := w.(io.Seeker); ok {
+		if _, err := s.Seek(0, io.SeekCurrent); err == nil {
+			wr.seeker = s
+		}
+	}
+	return wr
+}
+
+type writer struct {
+	w          io.WriteCloser
+	seeker     io.Seeker
+	bw         *bufio.Writer
+	sampleRate int
+	channels   int
+	started    bool
+	size       uint64
+	buf        []byte
+}
+
+func (w *writer) String() string {
+	return fmt.Sprintf("WAV(%d,%d)", w.channels, w.sampleRate)
+}
+
+func (w *writer) SampleRate() int {
+	return w.sampleRate
+}
+
+func (w *writer) WriteSample(sample media.PCM16Sample) error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	if sz := sample.Size(); cap(w.buf) < sz {
+		w.buf = make([]byte, sz)
+	} else {
+		w.buf = w.buf[:sz]
+	}
+	n, err := sample.CopyTo(w.buf)
+	if err != nil {
+		return err
+	}
+	n, err = w.bw.Write(w.buf[:n])
+	w.size += uint64(n)
+	return err
+}
+
+func (w *writer) start() error {
+	if w.started {
+		return nil
+	}
+	w.started = true
+	// Sizes are unknown yet, readers should read until EOF.
+	hdr := appendHeader(nil, w.sampleRate, w.channels, 0)
+	if w.seeker == nil {
+		// Header won't be updated, so use the maximal size, as other streaming encoders do.
+		binary.LittleEndian.PutUint32(hdr[4:], math.MaxUint32)
+		binary.LittleEndian.PutUint32(hdr[len(hdr)-4:], math.MaxUint32)
+	}
+	_, err := w.bw.Write(hdr)
+	return err
+}
+
+func (w *writer) Close() error {
+	err := w.start()
+	if err == nil {
+		err = w.bw.Flush()
+	}
+	if err == nil && w.seeker != nil {
+		err = w.writeHeader()
+	}
+	return errors.Join(err, w.w.Close())
+}
+
+func (w *writer) writeHeader() error {
+	if _, err := w.seeker.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	_, err := w.w.Write(appendHeader(nil, w.sampleRate, w.channels, w.size))
+	return err
+}
+
+// appendHeader appends a WAV header for 16 bit PCM with a given data size.
+// If the size doesn't fit into RIFF, RF64 header is written instead.
+func appendHeader(b []byte, sampleRate, channels int, dataSize uint64) []byte {
+	const bitsPerSample = 16
+	riffSize := uint64(headerSize-8) + dataSize
+	rf64 := riffSize > math.MaxUint32
+	le := binary.LittleEndian
+	if rf64 {
+		b = append(b, "RF64"...)
+		b = le.AppendUint32(b, math.MaxUint32)
+	} else {
+		b = append(b, "RIFF"...)
+		b = le.AppendUint32(b, uint32(riffSize))
+	}
+	b = append(b, "WAVE"...)
+
+	if rf64 {
+		b = append(b, "ds64"...)
+		b = le.AppendUint32(b, ds64Size)
+		b = le.AppendUint64(b, riffSize)
+		b = le.AppendUint64(b, dataSize)
+		b = le.AppendUint64(b, dataSize/uint64(channels*bitsPerSample/8))
+		b = le.AppendUint32(b, 0) // table length
+	} else {
+		b = append(b, "JUNK"...)
+		b = le.AppendUint32(b, ds64Size)
+		b = append(b, make([]byte, ds64Size)...)
+	}
+
+	b = append(b, "fmt "...)
+	b = le.AppendUint32(b, 16)
+	b = le.AppendUint16(b, FormatPCM)
+	b = le.AppendUint16(b, uint16(channels))
+	b = le.AppendUint32(b, uint32(sampleRate))
+	b = le.AppendUint32(b, uint32(sampleRate*channels*bitsPerSample/8))
+	b = le.AppendUint16(b, uint16(channels*bitsPerSample/8))
+	b = le.AppendUint16(b, bitsPerSample)
+
+	b = append(b, "data"...)
+	b = le.AppendUint32(b, uint32(min(dataSize, math.MaxUint32)))
+	return b
+}
@@ -0,0 +1,509 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webm
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// EBML element IDs used by the reader.
+const (
+	idEBML              = 0x1A45DFA3
+	idSegment           = 0x18538067
+	idInfo              = 0x1549A966
+	idTimecodeScale     = 0x2AD7B1
+	idDuration          = 0x4489
+	idTracks            = 0x1654AE6B
+	idTrackEntry        = 0xAE
+	idTrackNumber       = 0xD7
+	idTrackType         = 0x83
+	idName              = 0x536E
+	idCodecID           = 0x86
+	idCodecPrivate      = 0x63A2
+	idDefaultDuration   = 0x23E383
+	idAudio             = 0xE1
+	idSamplingFrequency = 0xB5
+	idChannels          = 0x9F
+	idBitDepth          = 0x6264
+	idCluster           = 0x1F43B675
+	idTimecode          = 0xE7
+	idSimpleBlock       = 0xA3
+	idBlockGroup        = 0xA0
+	idBlock             = 0xA1
+)
+
+const (
+	unknownSize    = math.MaxUint64
+	maxElementSize = 16 << 20
+	defTimecode    = time.Millisecond
+)
+
+var (
+	ErrNotWebM     = errors.New("not a WebM/Matroska file")
+	ErrInvalidData = errors.New("invalid WebM data")
+)
+
+// Track describes a track of WebM/Matroska file.
+type Track struct {
+	Number          uint64
+	Type            int
+	Name            string
+	Codec           string
+	CodecPrivate    []byte
+	DefaultDuration time.Duration
+	SampleRate      int
+	Channels        int
+	BitDepth        int
+}
+
+// Block is a single frame read from the file.
+type Block struct {
+	Track     uint64
+	Timestamp time.Duration
+	// Keyframe is only known for SimpleBlock. Frames from BlockGroup are always reported as keyframes.
+	Keyframe bool
+	Data     []byte
+}
+
+// NewReader parses WebM/Matroska headers and returns a demuxer for blocks of all tracks.
+// The reader does not seek, thus it works with streams written by NewWriter, which have no size for Segment and Cluster.
+func NewReader(r io.Reader) (*Reader, error) {
+	dr := &Reader{
+		src:       r,
+		r:         bufio.NewReader(r),
+		timescale: defTimecode,
+	}
+	if err := dr.readHeader(); err != nil {
+		return nil, err
+	}
+	return dr, nil
+}
+
+type Reader struct {
+	src       io.Reader
+	r         *bufio.Reader
+	timescale time.Duration
+	duration  time.Duration
+	tracks    []Track
+	cluster   int64 // cluster timecode
+	pending   []Block
+}
+
+// Tracks returns all tracks defined in the file.
+func (r *Reader) Tracks() []Track {
+	return r.tracks
+}
+
+// Track finds a track by number.
+func (r *Reader) Track(num uint64) (Track, bool) {
+	for _, t := range r.tracks {
+		if t.Number == num {
+			return t, true
+		}
+	}
+	return Track{}, false
+}
+
+// Duration returns the duration of the segment, if it's set in the header.
+func (r *Reader) Duration() time.Duration {
+	return r.duration
+}
+
+// Close closes the source reader, if it implements io.Closer.
+func (r *Reader) Close() error {
+	if c, ok := r.src.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+func (r *Reader) readHeader() error {
+	id, size, err := r.readElement()
+	if err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, ErrInvalidData) {
+			return fmt.Errorf("%w: %w", ErrNotWebM, err)
+		}
+		return err
+	}
+	if id != idEBML || size == unknownSize {
+		return ErrNotWebM
+	}
+	if err = r.skip(size); err != nil {
+		return err
+	}
+	for {
+		id, size, err = r.readElement()
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("%w: no tracks", ErrInvalidData)
+		} else if err != nil {
+			return err
+		}
+		switch id {
+		case idSegment:
+			// Enter the segment.
+		case idInfo:
+			data, err := r.readData(size)
+			if err != nil {
+				return err
+			}
+			if err = r.parseInfo(data); err != nil {
+				return err
+			}
+		case idTracks:
+			data, err := r.readData(size)
+			if err != nil {
+				return err
+			}
+			return r.parseTracks(data)
+		case idCluster:
+			return fmt.Errorf("%w: no tracks", ErrInvalidData)
+		default:
+			if err = r.skip(size); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// ReadBlock returns the next block of any track. It returns io.EOF at the end of the file.
+// Laced frames are returned as separate blocks with the same timestamp.
+func (r *Reader) ReadBlock() (Block, error) {
+	for len(r.pending) == 0 {
+		id, size, err := r.readElement()
+		if err != nil {
+			return Block{}, err
+		}
+		switch id {
+		case idSegment, idCluster, idBlockGroup:
+			// Enter the element.
+		case idTimecode:
+			data, err := r.readData(size)
+			if err != nil {
+				return Block{}, err
+			}
+			r.cluster = int64(readUint(data))
+		case idSimpleBlock, idBlock:
+			data, err := r.readData(size)
+			if err != nil {
+				return Block{}, err
+			}
+			if r.pending, err = r.parseBlock(r.pending[:0], data, id == idSimpleBlock); err != nil {
+				return Block{}, err
+			}
+		default:
+			if err = r.skip(size); err != nil {
+				return Block{}, err
+			}
+		}
+	}
+	b := r.pending[0]
+	r.pending = r.pending[1:]
+	return b, nil
+}
+
+func (r *Reader) parseBlock(dst []Block, data []byte, simple bool) ([]Block, error) {
+	track, n := readVint(data)
+	if n <= 0 || len(data) < n+3 {
+		return dst, fmt.Errorf("%w: short block", ErrInvalidData)
+	}
+	ts := r.cluster + int64(int16(binary.BigEndian.Uint16(data[n:])))
+	flags := data[n+2]
+	data = data[n+3:]
+	frames, err := unlace(data, (flags>>1)&0x3)
+	if err != nil {
+		return dst, err
+	}
+	for _, f := range frames {
+		dst = append(dst, Block{
+			Track:     track,
+			Timestamp: time.Duration(ts) * r.timescale,
+			Keyframe:  !simple || flags&0x80 != 0,
+			Data:      f,
+		})
+	}
+	return dst, nil
+}
+
+// unlace splits block data into frames.
+func unlace(data []byte, lacing byte) ([][]byte, error) {
+	if lacing == 0 {
+		return [][]byte{data}, nil
+	}
+	if len(data) < 1 {
+		return nil, fmt.Errorf("%w: short laced block", ErrInvalidData)
+	}
+	count := int(data[0]) + 1
+	data = data[1:]
+	sizes := make([]int, count)
+	switch lacing {
+	case 1: // Xiph
+		for i := range count - 1 {
+			for {
+				if len(data) == 0 {
+					return nil, fmt.Errorf("%w: short laced block", ErrInvalidData)
+				}
+				b := data[0]
+				data = data[1:]
+				sizes[i] += int(b)
+				if b != 255 {
+					break
+				}
+			}
+		}
+	case 2: // fixed size
+		if len(data)%count != 0 {
+			return nil, fmt.Errorf("%w: invalid fixed lacing", ErrInvalidData)
+		}
+		for i := range count - 1 {
+			sizes[i] = len(data) / count
+		}
+	case 3: // EBML
+		for i := range count - 1 {
+			v, n := readVint(data)
+			if n <= 0 {
+				return nil, fmt.Errorf("%w: invalid EBML lacing", ErrInvalidData)
+			}
+			data = data[n:]
+			if i == 0 {
+				sizes[i] = int(v)
+			} else {
+				// Signed difference from the previous size.
+				sizes[i] = sizes[i-1] + int(int64(v)-(1<<(7*n-1)-1))
+			}
+		}
+	}
+	total := 0
+	for _, sz := range sizes[:count-1] {
+		if sz < 0 {
+			return nil, fmt.Errorf("%w: invalid lacing", ErrInvalidData)
+		}
+		total += sz
+	}
+	if total > len(data) {
+		return nil, fmt.Errorf("%w: short laced block", ErrInvalidData)
+	}
+	sizes[count-1] = len(data) - total
+	frames := make([][]byte, count)
+	for i, sz := range sizes {
+		frames[i] = data[:sz]
+		data = data[sz:]
+	}
+	return frames, nil
+}
+
+func (r *Reader) parseInfo(data []byte) error {
+	return forEachElement(data, func(id uint64, data []byte) error {
+		switch id {
+		case idTimecodeScale:
+			if v := readUint(data); v != 0 {
+				r.timescale = time.Duration(v)
+			}
+		case idDuration:
+			r.duration = time.Duration(readFloat(data) * float64(r.timescale))
+		}
+		return nil
+	})
+}
+
+func (r *Reader) parseTracks(data []byte) error {
+	err := forEachElement(data, func(id uint64, data []byte) error {
+		if id != idTrackEntry {
+			return nil
+		}
+		t := Track{Channels: 1}
+		err := forEachElement(data, func(id uint64, data []byte) error {
+			switch id {
+			case idTrackNumber:
+				t.Number = readUint(data)
+			case idTrackType:
+				t.Type = int(readUint(data))
+			case idName:
+				t.Name = string(data)
+			case idCodecID:
+				t.Codec = string(data)
+			case idCodecPrivate:
+				t.CodecPrivate = data
+			case idDefaultDuration:
+				t.DefaultDuration = time.Duration(readUint(data))
+			case idAudio:
+				return forEachElement(data, func(id uint64, data []byte) error {
+					switch id {
+					case idSamplingFrequency:
+						t.SampleRate = int(readFloat(data))
+					case idChannels:
+						t.Channels = int(readUint(data))
+					case idBitDepth:
+						t.BitDepth = int(readUint(data))
+					}
+					return nil
+				})
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		r.tracks = append(r.tracks, t)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(r.tracks) == 0 {
+		return fmt.Errorf("%w: no tracks", ErrInvalidData)
+	}
+	return nil
+}
+
+// readElement reads element ID and size.
+func (r *Reader) readElement() (uint64, uint64, error) {
+	id, err := r.readVint(false)
+	if err != nil {
+		return 0, 0, err
+	}
+	size, err := r.readVint(true)
+	if errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	return id, size, err
+}
+
+func (r *Reader) readVint(isSize bool) (uint64, error) {
+	b, err := r.r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	n := vintLen(b)
+	if n == 0 || (!isSize && n > 4) {
+		return 0, fmt.Errorf("%w: invalid element header", ErrInvalidData)
+	}
+	buf := [8]byte{b}
+	if _, err = io.ReadFull(r.r, buf[1:n]); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return 0, err
+	}
+	if !isSize {
+		// Element IDs keep the length marker.
+		var v uint64
+		for _, b := range buf[:n] {
+			v = v<<8 | uint64(b)
+		}
+		return v, nil
+	}
+	v, _ := readVint(buf[:n])
+	if v == 1<<(7*n)-1 {
+		return unknownSize, nil
+	}
+	return v, nil
+}
+
+func (r *Reader) readData(size uint64) ([]byte, error) {
+	if size == unknownSize || size > maxElementSize {
+		return nil, fmt.Errorf("%w: element is too large", ErrInvalidData)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r.r, data); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return data, nil
+}
+
+func (r *Reader) skip(size uint64) error {
+	if size == unknownSize {
+		return fmt.Errorf("%w: unexpected element of unknown size", ErrInvalidData)
+	}
+	_, err := io.CopyN(io.Discard, r.r, int64(size))
+	if errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+func vintLen(b byte) int {
+	for i := range 8 {
+		if b&(0x80>>i) != 0 {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// readVint reads a variable size integer with the length marker removed.
+// It returns the number of bytes read, or 0, if the data is invalid.
+func readVint(data []byte) (uint64, int) {
+	if len(data) == 0 {
+		return 0, 0
+	}
+	n := vintLen(data[0])
+	if n == 0 || len(data) < n {
+		return 0, 0
+	}
+	v := uint64(data[0] & (0xff >> n))
+	for _, b := range data[1:n] {
+		v = v<<8 | uint64(b)
+	}
+	return v, n
+}
+
+func readUint(data []byte) uint64 {
+	var v uint64
+	for _, b := range data {
+		v = v<<8 | uint64(b)
+	}
+	return v
+}
+
+func readFloat(data []byte) float64 {
+	switch len(data) {
+	case 4:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(data)))
+	case 8:
+		return math.Float64frombits(binary.BigEndian.Uint64(data))
+	}
+	return 0
+}
+
+// forEachElement calls fn for each child element of a master element.
+func forEachElement(data []byte, fn func(id uint64, data []byte) error) error {
+	for len(data) > 0 {
+		n := vintLen(data[0])
+		if n == 0 || n > 4 || len(data) < n {
+			return fmt.Errorf("%w: invalid element header", ErrInvalidData)
+		}
+		id := readUint(data[:n])
+		data = data[n:]
+		size, n := readVint(data)
+		if n <= 0 || uint64(len(data)-n) < size {
+			return fmt.Errorf("%w: invalid element size", ErrInvalidData)
+		}
+		data = data[n:]
+		if err := fn(id, data[:size]); err != nil {
+			return err
+		}
+		data = data[size:]
+	}
+	return nil
+}
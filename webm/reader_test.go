@@ -0,0 +1,110 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webm
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/media-sdk"
+)
+
+type bufferCloser struct {
+	bytes.Buffer
+}
+
+func (*bufferCloser) Close() error { return nil }
+
+func TestPCM16Reader(t *testing.T) {
+	const (
+		rate = 16000
+		dur  = 20 * time.Millisecond
+	)
+	var buf bufferCloser
+	w := NewPCM16Writer(&buf, rate, 1, dur)
+	var exp []media.PCM16Sample
+	for i := range 100 {
+		s := make(media.PCM16Sample, rate/50)
+		for j := range s {
+			s[j] = int16(i*1000 - j)
+		}
+		exp = append(exp, s)
+		require.NoError(t, w.WriteSample(s))
+	}
+	require.NoError(t, w.Close())
+
+	r, err := NewPCM16Reader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, rate, r.SampleRate())
+	require.Equal(t, 1, r.Channels())
+	require.Equal(t, CodecPCM16, r.Track().Codec)
+	require.Equal(t, dur, r.Track().DefaultDuration)
+
+	for i, s := range exp {
+		got, ts, err := r.ReadTimedSample()
+		require.NoError(t, err, "sample %d", i)
+		require.Equal(t, s, got, "sample %d", i)
+		require.Equal(t, time.Duration(i)*dur, ts, "sample %d", i)
+	}
+	_, _, err = r.ReadTimedSample()
+	require.Equal(t, io.EOF, err)
+
+	// Samples are not lost if the buffer is too small.
+	r, err = NewPCM16Reader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	_, err = r.ReadSample(make(media.PCM16Sample, 10))
+	require.ErrorIs(t, err, io.ErrShortBuffer)
+	sample := make(media.PCM16Sample, rate/50)
+	for i, s := range exp {
+		n, err := r.ReadSample(sample)
+		require.NoError(t, err, "sample %d", i)
+		require.Equal(t, s, sample[:n], "sample %d", i)
+	}
+
+	_, err = NewPCM16Reader(bytes.NewReader([]byte("RIFF....WAVEfmt ")))
+	require.ErrorIs(t, err, ErrNotWebM)
+
+	_, err = NewSampleReader(r.r, CodecOpus, func(data []byte) ([]byte, error) { return data, nil })
+	require.Error(t, err)
+}
+
+func TestUnlace(t *testing.T) {
+	cases := []struct {
+		name   string
+		lacing byte
+		data   []byte
+		exp    [][]byte
+	}{
+		{"none", 0, []byte{1, 2, 3}, [][]byte{{1, 2, 3}}},
+		{"xiph", 1, []byte{2, 1, 2, 9, 8, 8, 7}, [][]byte{{9}, {8, 8}, {7}}},
+		{"fixed", 2, []byte{2, 1, 2, 3, 4, 5, 6}, [][]byte{{1, 2}, {3, 4}, {5, 6}}},
+		{"ebml", 3, []byte{2, 0x81, 0xc0, 9, 8, 8, 7, 7}, [][]byte{{9}, {8, 8}, {7, 7}}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			frames, err := unlace(c.data, c.lacing)
+			require.NoError(t, err)
+			require.Equal(t, c.exp, frames)
+		})
+	}
+	_, err := unlace([]byte{2, 1, 2, 3, 4, 5}, 2)
+	require.ErrorIs(t, err, ErrInvalidData)
+	_, err = unlace([]byte{1, 200, 1}, 1)
+	require.ErrorIs(t, err, ErrInvalidData)
+}
@@ -0,0 +1,531 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webm
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/livekit/media-sdk"
+)
+
+// EBML element IDs used only by the recorder.
+const (
+	idEBMLVersion        = 0x4286
+	idEBMLReadVersion    = 0x42F7
+	idEBMLMaxIDLength    = 0x42F2
+	idEBMLMaxSizeLength  = 0x42F3
+	idDocType            = 0x4282
+	idDocTypeVersion     = 0x4287
+	idDocTypeReadVersion = 0x4285
+	idSeekHead           = 0x114D9B74
+	idSeek               = 0x4DBB
+	idSeekID             = 0x53AB
+	idSeekPosition       = 0x53AC
+	idMuxingApp          = 0x4D80
+	idWritingApp         = 0x5741
+	idDateUTC            = 0x4461
+	idTrackUID           = 0x73C5
+	idFlagLacing         = 0x9C
+	idCues               = 0x1C53BB6B
+	idCuePoint           = 0xBB
+	idCueTime            = 0xB3
+	idCueTrackPositions  = 0xB7
+	idCueTrack           = 0xF7
+	idCueClusterPosition = 0xF1
+)
+
+const (
+	trackTypeAudio = 2
+	appName        = "livekit/media-sdk"
+	// DefTimescale is the default timestamp resolution of the recorder.
+	DefTimescale    = 100 * time.Microsecond
+	clusterDuration = time.Second
+)
+
+var (
+	ErrRecorderStarted = errors.New("tracks cannot be added after recording started")
+	ErrSampleTooOld    = errors.New("sample timestamp is too old")
+)
+
+// matroskaEpoch is the origin of DateUTC element.
+var matroskaEpoch = time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)
+
+type RecorderOption func(*Recorder)
+
+// WithTimescale sets the resolution of timestamps in the file. Clusters cannot be longer than 32767 units.
+func WithTimescale(scale time.Duration) RecorderOption {
+	return func(r *Recorder) {
+		if scale > 0 {
+			r.timescale = scale
+		}
+	}
+}
+
+// WithStartTime sets the wall-clock time of the beginning of the recording.
+// It's used as a reference for WriteSample. Defaults to the time when the recorder was created.
+func WithStartTime(t time.Time) RecorderOption {
+	return func(r *Recorder) {
+		r.start = t
+	}
+}
+
+// NewRecorder creates a WebM recorder with multiple tracks. Tracks are added with NewTrackWriter before writing any samples.
+//
+// If w implements io.Seeker, duration, segment size and SeekHead are updated on Close, making the file seekable.
+// Cues are always written at the end of the file.
+func NewRecorder(w io.WriteCloser, opts ...RecorderOption) *Recorder {
+	r := &Recorder{
+		w:         w,
+		timescale: DefTimescale,
+		start:     time.Now(),
+	}
+	for _, o := range opts {
+		o(r)
+	}
+	if s, ok := w.(io.Seeker); ok {
+		if _, err := s.Seek(0, io.SeekCurrent); err == nil {
+			r.seeker = s
+		}
+	}
+	return r
+}
+
+// Recorder writes samples of multiple tracks to a single WebM file.
+type Recorder struct {
+	mu        sync.Mutex
+	w         io.WriteCloser
+	seeker    io.Seeker
+	timescale time.Duration
+	start     time.Time
+	tracks    []*trackState
+	started   bool
+	closed    bool
+	err       error
+
+	pos         int64 // bytes written
+	segStart    int64 // start of the segment data
+	segSizePos  int64
+	durationPos int64
+	cuesSeekPos int64
+
+	cluster     []byte
+	clusterTS   int64 // in timescale units
+	clusterUsed bool
+	cues        []byte
+}
+
+type trackState struct {
+	Track
+	lastTS  int64
+	lastDur int64
+}
+
+// NewTrackWriter adds a track to the recorder and returns a writer for it.
+// Track number is assigned automatically, if not set. Track type defaults to audio.
+func NewTrackWriter[T media.Frame](r *Recorder, t Track) (*TrackWriter[T], error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.started || r.closed {
+		return nil, ErrRecorderStarted
+	}
+	if t.Codec == "" {
+		return nil, errors.New("track codec must be set")
+	}
+	if t.Number == 0 {
+		t.Number = uint64(len(r.tracks) + 1)
+	}
+	for _, t2 := range r.tracks {
+		if t2.Number == t.Number {
+			return nil, fmt.Errorf("duplicate track number: %d", t.Number)
+		}
+	}
+	if t.Type == 0 {
+		t.Type = trackTypeAudio
+	}
+	if t.Channels == 0 {
+		t.Channels = 1
+	}
+	st := &trackState{Track: t, lastTS: -1}
+	r.tracks = append(r.tracks, st)
+	return &TrackWriter[T]{r: r, t: st}, nil
+}
+
+// TrackWriter writes samples to a single track of the recorder.
+type TrackWriter[T media.Frame] struct {
+	r   *Recorder
+	t   *trackState
+	buf []byte
+}
+
+func (w *TrackWriter[T]) String() string {
+	return fmt.Sprintf("WEBM(%s,%d,%d)#%d", w.t.Codec, w.t.Channels, w.t.SampleRate, w.t.Number)
+}
+
+func (w *TrackWriter[T]) SampleRate() int {
+	return w.t.SampleRate
+}
+
+// Track returns track parameters.
+func (w *TrackWriter[T]) Track() Track {
+	return w.t.Track
+}
+
+// WriteSample writes a sample with a timestamp based on the wall-clock time elapsed since the start of the recording.
+func (w *TrackWriter[T]) WriteSample(sample T) error {
+	return w.WriteSampleAt(sample, time.Since(w.r.start))
+}
+
+// WriteSampleAt writes a sample with a given timestamp relative to the start of the recording.
+// Timestamps in a single track must not decrease.
+func (w *TrackWriter[T]) WriteSampleAt(sample T, ts time.Duration) error {
+	if sz := sample.Size(); cap(w.buf) < sz {
+		w.buf = make([]byte, sz)
+	} else {
+		w.buf = w.buf[:sz]
+	}
+	n, err := sample.CopyTo(w.buf)
+	if err != nil {
+		return err
+	}
+	return w.r.writeBlock(w.t, ts, w.buf[:n])
+}
+
+// Close the track. The file is finalized when the Recorder is closed.
+func (w *TrackWriter[T]) Close() error {
+	return nil
+}
+
+func (r *Recorder) write(b []byte) error {
+	if r.err != nil {
+		return r.err
+	}
+	n, err := r.w.Write(b)
+	r.pos += int64(n)
+	r.err = err
+	return err
+}
+
+func (r *Recorder) writeHeader() error {
+	r.started = true
+	var b []byte
+	b = appendMaster(b, idEBML, func(b []byte) []byte {
+		b = appendUint(b, idEBMLVersion, 1)
+		b = appendUint(b, idEBMLReadVersion, 1)
+		b = appendUint(b, idEBMLMaxIDLength, 4)
+		b = appendUint(b, idEBMLMaxSizeLength, 8)
+		b = appendString(b, idDocType, "webm")
+		b = appendUint(b, idDocTypeVersion, 4)
+		b = appendUint(b, idDocTypeReadVersion, 2)
+		return b
+	})
+	b = appendID(b, idSegment)
+	r.segSizePos = int64(len(b))
+	b = append(b, 0x01, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff) // unknown size
+	r.segStart = int64(len(b))
+
+	var durOff int
+	infoData := appendUint(nil, idTimecodeScale, uint64(r.timescale))
+	if r.seeker != nil {
+		// Updated on Close.
+		infoData = appendFloat(infoData, idDuration, 0)
+		durOff = len(infoData) - 8
+	}
+	infoData = appendID(infoData, idDateUTC)
+	infoData = appendSize(infoData, 8)
+	infoData = binary.BigEndian.AppendUint64(infoData, uint64(r.start.Sub(matroskaEpoch).Nanoseconds()))
+	infoData = appendString(infoData, idMuxingApp, appName)
+	infoData = appendString(infoData, idWritingApp, appName)
+	info := appendBytes(nil, idInfo, infoData)
+	durOff += len(info) - len(infoData)
+
+	tracks := appendMaster(nil, idTracks, func(b []byte) []byte {
+		for _, t := range r.tracks {
+			b = appendTrack(b, t.Track)
+		}
+		return b
+	})
+	if r.seeker != nil {
+		// SeekHead uses fixed size positions, thus its size is known before the positions are.
+		sh, _ := appendSeekHead(nil, 0, 0)
+		sz := int64(len(sh))
+		sh, cuesOff := appendSeekHead(nil, sz, sz+int64(len(info)))
+		r.cuesSeekPos = int64(len(b) + cuesOff)
+		b = append(b, sh...)
+		r.durationPos = int64(len(b) + durOff)
+	}
+	b = append(b, info...)
+	b = append(b, tracks...)
+	return r.write(b)
+}
+
+// appendSeekHead appends SeekHead with positions of Info, Tracks and Cues. Cues position is zero and must be updated later.
+// It returns the offset of Cues position in the resulting slice.
+func appendSeekHead(b []byte, infoPos, tracksPos int64) ([]byte, int) {
+	var body []byte
+	body, _ = appendSeek(body, idInfo, infoPos)
+	body, _ = appendSeek(body, idTracks, tracksPos)
+	body, cuesOff := appendSeek(body, idCues, 0)
+	b = appendID(b, idSeekHead)
+	b = appendSize(b, uint64(len(body)))
+	cuesOff += len(b)
+	return append(b, body...), cuesOff
+}
+
+// appendSeek appends Seek element with 8 byte position. It returns the offset of the position in the resulting slice.
+func appendSeek(b []byte, id uint64, pos int64) ([]byte, int) {
+	data := appendBytes(nil, idSeekID, appendID(nil, id))
+	data = appendID(data, idSeekPosition)
+	data = appendSize(data, 8)
+	data = binary.BigEndian.AppendUint64(data, uint64(pos))
+	b = appendBytes(b, idSeek, data)
+	return b, len(b) - 8
+}
+
+func appendTrack(b []byte, t Track) []byte {
+	return appendMaster(b, idTrackEntry, func(b []byte) []byte {
+		b = appendUint(b, idTrackNumber, t.Number)
+		b = appendUint(b, idTrackUID, rand.Uint64())
+		b = appendUint(b, idTrackType, uint64(t.Type))
+		b = appendUint(b, idFlagLacing, 0)
+		if t.Name != "" {
+			b = appendString(b, idName, t.Name)
+		}
+		b = appendString(b, idCodecID, t.Codec)
+		if len(t.CodecPrivate) != 0 {
+			b = appendBytes(b, idCodecPrivate, t.CodecPrivate)
+		}
+		if t.DefaultDuration > 0 {
+			b = appendUint(b, idDefaultDuration, uint64(t.DefaultDuration))
+		}
+		if t.Type == trackTypeAudio {
+			b = appendMaster(b, idAudio, func(b []byte) []byte {
+				b = appendFloat(b, idSamplingFrequency, float64(t.SampleRate))
+				b = appendUint(b, idChannels, uint64(t.Channels))
+				if t.BitDepth != 0 {
+					b = appendUint(b, idBitDepth, uint64(t.BitDepth))
+				}
+				return b
+			})
+		}
+		return b
+	})
+}
+
+func (r *Recorder) writeBlock(t *trackState, ts time.Duration, data []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return io.ErrClosedPipe
+	}
+	if ts < 0 {
+		return fmt.Errorf("%w: %v", ErrSampleTooOld, ts)
+	}
+	if !r.started {
+		if err := r.writeHeader(); err != nil {
+			return err
+		}
+	}
+	tc := int64(ts / r.timescale)
+	if tc < t.lastTS {
+		return fmt.Errorf("%w: %v", ErrSampleTooOld, ts)
+	}
+	if !r.clusterUsed || tc-r.clusterTS > math.MaxInt16 || time.Duration(tc-r.clusterTS)*r.timescale >= clusterDuration {
+		if err := r.flushCluster(); err != nil {
+			return err
+		}
+		r.clusterUsed = true
+		r.clusterTS = tc
+		r.cluster = appendUint(r.cluster[:0], idTimecode, uint64(tc))
+		r.cues = appendMaster(r.cues, idCuePoint, func(b []byte) []byte {
+			b = appendUint(b, idCueTime, uint64(tc))
+			return appendMaster(b, idCueTrackPositions, func(b []byte) []byte {
+				b = appendUint(b, idCueTrack, t.Number)
+				return appendUint(b, idCueClusterPosition, uint64(r.pos-r.segStart))
+			})
+		})
+	}
+	rel := tc - r.clusterTS
+	if rel < math.MinInt16 {
+		return fmt.Errorf("%w: %v", ErrSampleTooOld, ts)
+	}
+	if t.lastTS >= 0 {
+		t.lastDur = tc - t.lastTS
+	}
+	t.lastTS = tc
+
+	var hdr [12]byte
+	h := appendSize(hdr[:0], t.Number)
+	h = binary.BigEndian.AppendUint16(h, uint16(int16(rel)))
+	h = append(h, 0x80) // keyframe, no lacing
+	r.cluster = appendID(r.cluster, idSimpleBlock)
+	r.cluster = appendSize(r.cluster, uint64(len(h)+len(data)))
+	r.cluster = append(r.cluster, h...)
+	r.cluster = append(r.cluster, data...)
+	return nil
+}
+
+func (r *Recorder) flushCluster() error {
+	if !r.clusterUsed {
+		return nil
+	}
+	r.clusterUsed = false
+	return r.write(appendBytes(nil, idCluster, r.cluster))
+}
+
+// Close writes remaining samples, Cues and updates the headers, if the writer is seekable. It closes the underlying writer.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	err := r.finalize()
+	return errors.Join(err, r.w.Close())
+}
+
+func (r *Recorder) finalize() error {
+	if !r.started {
+		if err := r.writeHeader(); err != nil {
+			return err
+		}
+	}
+	if err := r.flushCluster(); err != nil {
+		return err
+	}
+	cuesPos := r.pos - r.segStart
+	var end int64 // in timescale units
+	for _, t := range r.tracks {
+		if t.lastTS < 0 {
+			continue
+		}
+		// Assume the last sample has the default duration, or the same duration as the previous one.
+		dur := t.lastDur
+		if t.DefaultDuration > 0 {
+			dur = int64(t.DefaultDuration / r.timescale)
+		}
+		end = max(end, t.lastTS+dur)
+	}
+	if len(r.cues) != 0 {
+		if err := r.write(appendBytes(nil, idCues, r.cues)); err != nil {
+			return err
+		}
+	}
+	if r.seeker == nil {
+		return nil
+	}
+	fileEnd := r.pos
+	patch := func(pos int64, v []byte) error {
+		if _, err := r.seeker.Seek(pos-r.pos, io.SeekCurrent); err != nil {
+			return err
+		}
+		r.pos = pos
+		return r.write(v)
+	}
+	if err := patch(r.segSizePos, appendSize8(nil, uint64(fileEnd-r.segStart))); err != nil {
+		return err
+	}
+	if err := patch(r.durationPos, binary.BigEndian.AppendUint64(nil, math.Float64bits(float64(end)))); err != nil {
+		return err
+	}
+	if len(r.cues) != 0 {
+		if err := patch(r.cuesSeekPos, binary.BigEndian.AppendUint64(nil, uint64(cuesPos))); err != nil {
+			return err
+		}
+	}
+	_, err := r.seeker.Seek(fileEnd-r.pos, io.SeekCurrent)
+	return err
+}
+
+func appendID(b []byte, id uint64) []byte {
+	switch {
+	case id <= 0xff:
+		return append(b, byte(id))
+	case id <= 0xffff:
+		return binary.BigEndian.AppendUint16(b, uint16(id))
+	case id <= 0xffffff:
+		return append(b, byte(id>>16), byte(id>>8), byte(id))
+	default:
+		return binary.BigEndian.AppendUint32(b, uint32(id))
+	}
+}
+
+// appendSize appends element size as a variable size integer of minimal length.
+func appendSize(b []byte, size uint64) []byte {
+	n := 1
+	for n < 8 && size >= 1<<(7*n)-1 {
+		n++
+	}
+	if n == 8 {
+		return appendSize8(b, size)
+	}
+	for i := n - 1; i >= 0; i-- {
+		v := byte(size >> (8 * i))
+		if i == n-1 {
+			v |= 0x80 >> (n - 1)
+		}
+		b = append(b, v)
+	}
+	return b
+}
+
+// appendSize8 appends element size as a variable size integer of 8 bytes.
+func appendSize8(b []byte, size uint64) []byte {
+	b = append(b, 0x01)
+	for i := 6; i >= 0; i-- {
+		b = append(b, byte(size>>(8*i)))
+	}
+	return b
+}
+
+func appendBytes(b []byte, id uint64, data []byte) []byte {
+	b = appendID(b, id)
+	b = appendSize(b, uint64(len(data)))
+	return append(b, data...)
+}
+
+func appendString(b []byte, id uint64, s string) []byte {
+	return appendBytes(b, id, []byte(s))
+}
+
+func appendUint(b []byte, id uint64, v uint64) []byte {
+	n := 1
+	for n < 8 && v>>(8*n) != 0 {
+		n++
+	}
+	b = appendID(b, id)
+	b = appendSize(b, uint64(n))
+	for i := n - 1; i >= 0; i-- {
+		b = append(b, byte(v>>(8*i)))
+	}
+	return b
+}
+
+func appendFloat(b []byte, id uint64, v float64) []byte {
+	b = appendID(b, id)
+	b = appendSize(b, 8)
+	return binary.BigEndian.AppendUint64(b, math.Float64bits(v))
+}
+
+func appendMaster(b []byte, id uint64, fn func(b []byte) []byte) []byte {
+	return appendBytes(b, id, fn(nil))
+}
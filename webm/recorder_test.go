@@ -0,0 +1,106 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webm
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/media-sdk"
+)
+
+func TestRecorder(t *testing.T) {
+	const rate = 8000
+	record := func(t *testing.T, w io.WriteCloser) map[uint64][]Block {
+		r := NewRecorder(w)
+		a, err := NewTrackWriter[media.PCM16Sample](r, Track{Name: "a", Codec: CodecPCM16, SampleRate: rate})
+		require.NoError(t, err)
+		b, err := NewTrackWriter[media.PCM16Sample](r, Track{Name: "b", Codec: CodecPCM16, SampleRate: rate, Channels: 2})
+		require.NoError(t, err)
+		require.EqualValues(t, 1, a.Track().Number)
+		require.EqualValues(t, 2, b.Track().Number)
+
+		exp := make(map[uint64][]Block)
+		write := func(w *TrackWriter[media.PCM16Sample], i int, ts time.Duration) {
+			s := media.PCM16Sample{int16(i), int16(-i)}
+			require.NoError(t, w.WriteSampleAt(s, ts))
+			data := make([]byte, s.Size())
+			_, _ = s.CopyTo(data)
+			exp[w.Track().Number] = append(exp[w.Track().Number], Block{
+				Track: w.Track().Number, Timestamp: ts, Keyframe: true, Data: data,
+			})
+		}
+		for i := range 200 {
+			ts := time.Duration(i) * 20 * time.Millisecond
+			if i >= 100 {
+				// Gap in the input.
+				ts += 3 * time.Second
+			}
+			write(a, i, ts)
+			// Second track is slightly shifted.
+			write(b, i, ts+2500*time.Microsecond)
+		}
+
+		_, err = NewTrackWriter[media.PCM16Sample](r, Track{Codec: CodecPCM16})
+		require.ErrorIs(t, err, ErrRecorderStarted)
+		err = a.WriteSampleAt(media.PCM16Sample{1}, time.Second)
+		require.ErrorIs(t, err, ErrSampleTooOld)
+		require.NoError(t, r.Close())
+		return exp
+	}
+	check := func(t *testing.T, data []byte, exp map[uint64][]Block, dur time.Duration) {
+		r, err := NewReader(bytes.NewReader(data))
+		require.NoError(t, err)
+		require.Equal(t, dur, r.Duration())
+		require.Len(t, r.Tracks(), 2)
+		require.Equal(t, "b", r.Tracks()[1].Name)
+		require.Equal(t, 2, r.Tracks()[1].Channels)
+		require.Equal(t, rate, r.Tracks()[1].SampleRate)
+
+		got := make(map[uint64][]Block)
+		for {
+			b, err := r.ReadBlock()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			got[b.Track] = append(got[b.Track], b)
+		}
+		require.Equal(t, exp, got)
+	}
+
+	t.Run("file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "rec.webm")
+		f, err := os.Create(path)
+		require.NoError(t, err)
+		exp := record(t, f)
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.True(t, bytes.Contains(data, []byte{0x1C, 0x53, 0xBB, 0x6B}), "no cues")
+		// Last sample of the second track, plus its duration.
+		check(t, data, exp, 3*time.Second+200*20*time.Millisecond+2500*time.Microsecond)
+	})
+	t.Run("stream", func(t *testing.T) {
+		var buf bufferCloser
+		exp := record(t, &buf)
+		check(t, buf.Bytes(), exp, 0)
+	})
+}
@@ -0,0 +1,135 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webm
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/livekit/media-sdk"
+)
+
+const (
+	CodecPCM16 = "A_PCM/INT/LIT"
+	CodecOpus  = "A_OPUS"
+)
+
+// DecodeFunc converts block data to a typed sample.
+type DecodeFunc[T any] func(data []byte) (T, error)
+
+// NewSampleReader creates a reader for samples of the first track with a given codec.
+func NewSampleReader[T ~[]E, E any](r *Reader, codec string, decode DecodeFunc[T]) (*SampleReader[T, E], error) {
+	for _, t := range r.Tracks() {
+		if t.Codec == codec {
+			return &SampleReader[T, E]{r: r, track: t, decode: decode}, nil
+		}
+	}
+	return nil, fmt.Errorf("no %s track found", codec)
+}
+
+// NewPCM16Reader creates a reader for the first A_PCM/INT/LIT track in WebM file, for example written by NewPCM16Writer.
+func NewPCM16Reader(r io.Reader) (*SampleReader[media.PCM16Sample, int16], error) {
+	dr, err := NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	sr, err := NewSampleReader(dr, CodecPCM16, DecodePCM16)
+	if err != nil {
+		return nil, err
+	}
+	if sr.track.BitDepth != 0 && sr.track.BitDepth != 16 {
+		return nil, fmt.Errorf("unsupported PCM bit depth: %d", sr.track.BitDepth)
+	}
+	return sr, nil
+}
+
+// DecodePCM16 decodes 16 bit little-endian PCM samples.
+func DecodePCM16(data []byte) (media.PCM16Sample, error) {
+	if len(data)%2 != 0 {
+		return nil, fmt.Errorf("%w: odd PCM16 sample size", ErrInvalidData)
+	}
+	out := make(media.PCM16Sample, len(data)/2)
+	for i := range out {
+		out[i] = int16(binary.LittleEndian.Uint16(data[i*2:]))
+	}
+	return out, nil
+}
+
+// SampleReader reads typed samples of a single track. Blocks of other tracks are skipped.
+type SampleReader[T ~[]E, E any] struct {
+	r      *Reader
+	track  Track
+	decode DecodeFunc[T]
+
+	// pending sample that didn't fit into the ReadSample buffer
+	pending   T
+	pendingTS time.Duration
+}
+
+// Track returns the track being read.
+func (r *SampleReader[T, E]) Track() Track {
+	return r.track
+}
+
+func (r *SampleReader[T, E]) SampleRate() int {
+	return r.track.SampleRate
+}
+
+func (r *SampleReader[T, E]) Channels() int {
+	return r.track.Channels
+}
+
+// ReadTimedSample returns the next sample of the track and its timestamp relative to the start of the file.
+// It returns io.EOF at the end of the file.
+func (r *SampleReader[T, E]) ReadTimedSample() (T, time.Duration, error) {
+	if s := r.pending; s != nil {
+		r.pending = nil
+		return s, r.pendingTS, nil
+	}
+	for {
+		b, err := r.r.ReadBlock()
+		if err != nil {
+			return nil, 0, err
+		}
+		if b.Track != r.track.Number {
+			continue
+		}
+		s, err := r.decode(b.Data)
+		if err != nil {
+			return nil, 0, err
+		}
+		return s, b.Timestamp, nil
+	}
+}
+
+// ReadSample implements media.Reader. The buffer must be large enough to fit the whole sample.
+// If it's not, io.ErrShortBuffer is returned and the sample is kept for the next call.
+func (r *SampleReader[T, E]) ReadSample(buf T) (int, error) {
+	s, ts, err := r.ReadTimedSample()
+	if err != nil {
+		return 0, err
+	}
+	if len(buf) < len(s) {
+		r.pending, r.pendingTS = s, ts
+		return 0, io.ErrShortBuffer
+	}
+	return copy(buf, s), nil
+}
+
+func (r *SampleReader[T, E]) Close() error {
+	return r.r.Close()
+}
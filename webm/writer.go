@@ -27,7 +27,7 @@ import (
 )
 
 func NewPCM16Writer(w io.WriteCloser, sampleRate int, channels int, sampleDur time.Duration) media.PCM16Writer {
-	return NewWriter[media.PCM16Sample](w, "A_PCM/INT/LIT", channels, sampleRate, sampleDur)
+	return NewWriter[media.PCM16Sample](w, CodecPCM16, channels, sampleRate, sampleDur)
 }
 
 func NewWriter[T media.Frame](w io.WriteCloser, codec string, channels, sampleRate int, sampleDur time.Duration) media.WriteCloser[T] {
@@ -57,7 +57,7 @@ type writer[T media.Frame] struct {
 	channels   int
 	sampleRate int
 	dur        time.Duration
-	ts         int64
+	ts         time.Duration
 	buf        []byte
 }
 
@@ -79,8 +79,9 @@ func (w *writer[T]) WriteSample(sample T) error {
 	if err != nil {
 		return err
 	}
-	_, err = w.ws.Write(true, w.ts, slices.Clone(w.buf[:n]))
-	w.ts += w.dur.Milliseconds()
+	// Keep the timestamp as a duration, to avoid accumulating rounding errors.
+	_, err = w.ws.Write(true, w.ts.Milliseconds(), slices.Clone(w.buf[:n]))
+	w.ts += w.dur
 	return err
 }
 